@@ -3,13 +3,16 @@ package boot
 import (
 	"context"
 	"fmt"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
-	"log"
+	"log/slog"
+	"os"
 	"transaction-server/app"
 	"transaction-server/internal/common/db"
+	"transaction-server/internal/common/logger"
 	config2 "transaction-server/internal/config"
+	"transaction-server/internal/metrics"
+	"transaction-server/internal/tracing"
 )
 
 var (
@@ -17,17 +20,32 @@ var (
 	Db *db.DB
 	// Config holds info about growth config
 	Config config2.AppConfig
+	// Logger is the structured logger of the application, also the default of slog and log.
+	Logger *slog.Logger
+	// LogLevel is the level of Logger, changed by the runtime configuration.
+	LogLevel = new(slog.LevelVar)
+	// Runtime holds the settings reloaded while the application runs.
+	Runtime *config2.Runtime
 )
 
 func init() {
-	fmt.Println("Initializing App ...", "default")
-	Config = initConfig()
+	env := config2.Env()
+	Config = initConfig(env)
+	LogLevel.Set(logger.ParseLevel(Config.Log.Level))
+	Logger = logger.NewWithLevel(Config.Log, LogLevel, os.Stdout)
+	slog.SetDefault(Logger)
+	Logger.Info("initializing app", "env", env)
 }
 
-func initConfig() config2.AppConfig {
-	err := config2.NewDefaultConfig().Load("default", &Config)
+// initConfig loads the config of the environment and exits when it is invalid.
+func initConfig(env string) config2.AppConfig {
+	err := config2.NewDefaultConfig().Load(env, &Config)
+	if err == nil {
+		err = Config.Validate()
+	}
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("failed to load the config", "env", env, "error", err)
+		os.Exit(1)
 	}
 	return Config
 }
@@ -42,16 +60,58 @@ func Initialize(ctx context.Context) error {
 		return err
 	}
 
+	Runtime, err = InitRuntime()
+	if err != nil {
+		return err
+	}
+
 	// Create an Application appContext which will be used across the application.
 	appContext := app.NewAppContext(ctx, Config)
 	appContext.SetDB(Db)
+	appContext.SetLogger(Logger)
+	appContext.SetRuntime(Runtime)
 	return nil
 }
 
-// InitDb initializes db
+// InitRuntime reads the runtime configuration, which then sets the level of the logger.
+func InitRuntime() (*config2.Runtime, error) {
+	var file *config2.Config
+	if Config.App.RuntimeConfig != "" {
+		file = config2.NewDefaultRuntimeConfig(Config.App.RuntimeConfig)
+	}
+	runtime, err := config2.NewRuntime(file, Config.Runtime())
+	if err != nil {
+		return nil, err
+	}
+	runtime.OnChange(func(runtimeConfig config2.RuntimeConfig) {
+		LogLevel.Set(logger.ParseLevel(runtimeConfig.Log.Level))
+	})
+	return runtime, nil
+}
+
+// InitDb initializes db, with its read replicas
 func InitDb() (*db.DB, error) {
-	gDb, err := db.NewDb(&Config.Db, db.GormConfig(getGormConfig(&Config.Db)), db.Dialector(getGormDialector()))
-	return gDb, err
+	gDb, err := db.NewDb(&Config.Db, db.GormConfig(getGormConfig(&Config.Db)),
+		db.Plugins(metrics.GormPlugin{}, tracing.GormPlugin{}), db.OnTransactionRetry(metrics.ObserveDBTransactionRetry))
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := gDb.GetInstance(context.Background()).DB()
+	if err != nil {
+		return nil, err
+	}
+	if err = metrics.RegisterDBStats("primary", sqlDB); err != nil {
+		return nil, err
+	}
+	for i, replica := range gDb.Replicas() {
+		if sqlDB, err = replica.DB(); err != nil {
+			return nil, err
+		}
+		if err = metrics.RegisterDBStats(fmt.Sprintf("replica-%d", i), sqlDB); err != nil {
+			return nil, err
+		}
+	}
+	return gDb, nil
 }
 
 func getGormConfig(cr db.IConfigReader) *gorm.Config {
@@ -60,18 +120,14 @@ func getGormConfig(cr db.IConfigReader) *gorm.Config {
 		SkipDefaultTransaction:   true,
 		PrepareStmt:              true,
 		DisableNestedTransaction: true,
-		Logger:                   gormLogger.Default.LogMode(getDbLogLevelByDebugMode(cr.IsDebugMode())),
+		Logger:                   logger.NewGormLogger(Logger, Config.Log.SlowQuery).LogMode(getDbLogLevelByDebugMode(cr.IsDebugMode())),
 	}
 }
 
+// getDbLogLevelByDebugMode logs every query in debug mode, and failed and slow ones otherwise.
 func getDbLogLevelByDebugMode(debug bool) gormLogger.LogLevel {
 	if debug == false {
-		return gormLogger.Silent
+		return gormLogger.Warn
 	}
 	return gormLogger.Info
 }
-
-func getGormDialector() gorm.Dialector {
-	dsnFormat := "%s:%s@%s(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local"
-	return mysql.Open(fmt.Sprintf(dsnFormat, Config.Db.Username, Config.Db.Password, Config.Db.Protocol, Config.Db.URL, Config.Db.Port, Config.Db.Name))
-}
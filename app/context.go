@@ -2,13 +2,14 @@ package app
 
 import (
 	"context"
+	"log/slog"
 	"sync"
 	"transaction-server/internal/common/db"
 	"transaction-server/internal/config"
 )
 
 // this file consists of the functionalities required throughout the app:
-// db, logger, producer, tracer
+// db, logger, producer. The tracer is the global OpenTelemetry one, see internal/tracing.
 var (
 	appContext *applicationContext
 	once       sync.Once
@@ -21,6 +22,12 @@ type applicationContext struct {
 
 	// DB holds the db connection.
 	dB *db.DB
+
+	// logger is the structured logger, also set as the default of slog.
+	logger *slog.Logger
+
+	// runtime holds the settings reloaded while the application runs.
+	runtime *config.Runtime
 }
 
 // NewAppContext creates New Application Context(singleton function)
@@ -56,3 +63,23 @@ func (appContext *applicationContext) SetDB(dB *db.DB) {
 		appContext.dB = dB
 	}
 }
+
+func (appContext *applicationContext) Logger() *slog.Logger {
+	return appContext.logger
+}
+
+func (appContext *applicationContext) SetLogger(logger *slog.Logger) {
+	if appContext.logger == nil {
+		appContext.logger = logger
+	}
+}
+
+func (appContext *applicationContext) Runtime() *config.Runtime {
+	return appContext.runtime
+}
+
+func (appContext *applicationContext) SetRuntime(runtime *config.Runtime) {
+	if appContext.runtime == nil {
+		appContext.runtime = runtime
+	}
+}
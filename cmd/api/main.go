@@ -2,10 +2,25 @@ package main
 
 import (
 	"context"
-	"log"
+	"google.golang.org/grpc"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 	"transaction-server/app"
 	"transaction-server/app/boot"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/registry"
 	"transaction-server/internal/routes"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/tracing"
+	"transaction-server/internal/webhook"
 )
 
 func main() {
@@ -15,11 +30,180 @@ func main() {
 	// It should also initialize the application context
 	ctx := context.Background()
 	if err := boot.Initialize(ctx); err != nil {
-		log.Fatalf("failed to initialize the application: %v", err)
+		fatal("failed to initialize the application", err)
 	}
-	router := routes.RegisterRoutes(ctx)
-	err := router.Run(app.Context().Config().App.Port)
+	shutdownTracing, err := tracing.Init(ctx, app.Context().Config().Tracing, app.Context().Config().App.ServiceName)
 	if err != nil {
-		log.Fatal("Unable to start server. Error: ", err)
+		fatal("failed to initialize tracing", err)
 	}
+	apiRegistry := registry.NewRegistry(ctx)
+	router := routes.RegisterRoutes(ctx, apiRegistry)
+	app.Context().Runtime().Watch()
+
+	// Relay the domain events recorded in the outbox to the configured publisher and the
+	// live transaction streams and, when webhooks are enabled, queue their deliveries to
+	// the subscriptions. The streams come first as they never fail, and ignore a message
+	// published again after a later publisher failed.
+	commonRepo := db.NewRepo(app.Context().DB())
+	outboxConfig := app.Context().Config().Outbox
+	publisher, closer, err := outbox.NewPublisher(outboxConfig)
+	if err != nil {
+		fatal("failed to create the outbox publisher", err)
+	}
+	// The workers act on their own, as the system.
+	workersCtx, stopWorkers := context.WithCancel(auth.WithSystemIdentity(ctx))
+	var workers sync.WaitGroup
+	publisher = outbox.NewMultiPublisher(apiRegistry.GetTransactionStreamBroker(), publisher)
+	webhookConfig := app.Context().Config().Webhook
+	if webhookConfig.Enabled {
+		publisher = outbox.NewMultiPublisher(publisher, webhook.NewCore(commonRepo))
+		dispatcher := webhook.NewDispatcher(commonRepo, nil, webhookConfig)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			dispatcher.Run(workersCtx)
+		}()
+	}
+	relay := outbox.NewRelay(commonRepo, publisher, outboxConfig)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		relay.Run(workersCtx)
+	}()
+
+	// Serve the gRPC API alongside the HTTP routes, over the same servers.
+	appConfig := app.Context().Config().App
+	var grpcServer *grpc.Server
+	if grpcPort := appConfig.GrpcPort; grpcPort != "" {
+		listener, err := net.Listen("tcp", grpcPort)
+		if err != nil {
+			fatal("failed to listen for gRPC", err)
+		}
+		unary := []grpc.UnaryServerInterceptor{rpc.UnaryRequestIDInterceptor(app.Context().Logger()),
+			rpc.UnaryTimeoutInterceptor(app.Context().Config().Timeout)}
+		streams := []grpc.StreamServerInterceptor{rpc.StreamRequestIDInterceptor(app.Context().Logger())}
+		if authenticator := apiRegistry.GetAuthenticator(); authenticator != nil {
+			unary = append(unary, rpc.UnaryAuthInterceptor(authenticator))
+			streams = append(streams, rpc.StreamAuthInterceptor(authenticator))
+		}
+		if rateLimiter := apiRegistry.GetRateLimiter(); rateLimiter != nil {
+			unary = append(unary, rpc.UnaryRateLimitInterceptor(rateLimiter))
+			streams = append(streams, rpc.StreamRateLimitInterceptor(rateLimiter))
+		}
+		unary = append(unary, rpc.UnaryTenantInterceptor(apiRegistry.GetTenantResolver()))
+		streams = append(streams, rpc.StreamTenantInterceptor(apiRegistry.GetTenantResolver()))
+		if apiRegistry.GetAuthenticator() == nil {
+			unary = append(unary, rpc.UnarySystemInterceptor())
+			streams = append(streams, rpc.StreamSystemInterceptor())
+		}
+		grpcServer = rpc.NewServer(apiRegistry.GetAccountsServer(), apiRegistry.GetTransactionsServer(),
+			grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(streams...))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				fatal("unable to start the gRPC server", err)
+			}
+		}()
+	}
+
+	server := &http.Server{
+		Addr:              appConfig.Port,
+		Handler:           router,
+		ReadTimeout:       appConfig.ReadTimeout,
+		ReadHeaderTimeout: appConfig.ReadHeaderTimeout,
+		WriteTimeout:      appConfig.WriteTimeout,
+		IdleTimeout:       appConfig.IdleTimeout,
+	}
+	// Transaction streams never finish on their own: end them on shutdown rather than
+	// holding the drain until its deadline. Their clients resume on another instance.
+	server.RegisterOnShutdown(apiRegistry.GetTransactionStreamBroker().Close)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+	slog.Info("serving", "port", appConfig.Port, "grpcPort", appConfig.GrpcPort)
+
+	signalled, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err = <-served:
+		fatal("unable to start the server", err)
+	case <-signalled.Done():
+	}
+	// A second signal kills the process without waiting for the drain.
+	stop()
+
+	// Stop accepting requests and drain the in-flight ones, then stop the background
+	// workers, and finally flush the spans and close the connections they all used.
+	timeout := appConfig.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	slog.Info("shutting down", "timeout", timeout)
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer != nil {
+			stopGRPC(shutdownCtx, grpcServer)
+		}
+	}()
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to drain the HTTP requests", "error", err)
+	}
+	<-grpcStopped
+
+	stopWorkers()
+	if !wait(shutdownCtx, &workers) {
+		slog.Error("failed to stop the background workers", "error", shutdownCtx.Err())
+	}
+	if err = closer.Close(); err != nil {
+		slog.Error("failed to close the outbox publisher", "error", err)
+	}
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush the traces", "error", err)
+	}
+	if err = app.Context().DB().Close(); err != nil {
+		slog.Error("failed to close the database", "error", err)
+	}
+	slog.Info("shut down")
+}
+
+// defaultShutdownTimeout bounds the shutdown when app.shutdownTimeout is not set.
+const defaultShutdownTimeout = 30 * time.Second
+
+// stopGRPC drains the gRPC calls, cancelling those still running when ctx is done.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+		<-stopped
+	}
+}
+
+// wait waits for the group until ctx is done, reporting whether the group finished.
+func wait(ctx context.Context, group *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		group.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// fatal logs the error and exits.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+	"transaction-server/app"
+	"transaction-server/app/boot"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/tenant"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	if err := boot.Initialize(ctx); err != nil {
+		log.Fatalf("failed to initialize the application: %v", err)
+	}
+	authCore := auth.NewCore(db.NewRepo(app.Context().DB()), app.Context().Config().Auth)
+
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "issue":
+		flags := flag.NewFlagSet("issue", flag.ExitOnError)
+		client := flags.String("client", "", "Name of the client the key is issued to")
+		role := flags.String("role", "", "Access role of the client (keeps the current role when empty)")
+		tenantID := flags.String("tenant", "", "Tenant a new client is bound to (defaults to the default tenant)")
+		_ = flags.Parse(args)
+		if *client == "" {
+			usage()
+			os.Exit(2)
+		}
+		clientTenant, err := tenant.NewResolver(app.Context().Config().Tenant).Resolve(nil, *tenantID)
+		if err != nil {
+			log.Fatalf("invalid tenant: %v", err)
+		}
+		ctx = db.WithTenant(ctx, clientTenant)
+		key, apiKey, err := authCore.IssueKey(ctx, *client)
+		if err != nil {
+			log.Fatalf("failed to issue the key: %v", err)
+		}
+		if *role != "" {
+			setRole(ctx, authCore, *client, *role)
+		}
+		log.Printf("issued key %s to %s; the API key below is not shown again", key.ID, *client)
+		fmt.Println(apiKey)
+	case "revoke":
+		if len(args) != 1 {
+			usage()
+			os.Exit(2)
+		}
+		if _, err := authCore.RevokeKey(ctx, args[0]); err != nil {
+			log.Fatalf("failed to revoke the key: %v", err)
+		}
+		log.Printf("revoked key %s", args[0])
+	case "role":
+		if len(args) != 2 {
+			usage()
+			os.Exit(2)
+		}
+		setRole(ctx, authCore, args[0], args[1])
+	case "list":
+		flags := flag.NewFlagSet("list", flag.ExitOnError)
+		client := flags.String("client", "", "Name of the client to list the keys of (defaults to all)")
+		_ = flags.Parse(args)
+		keys, err := authCore.ListKeys(ctx, *client)
+		if err != nil {
+			log.Fatalf("failed to list the keys: %v", err)
+		}
+		for _, key := range *keys {
+			state := "active"
+			if key.IsRevoked() {
+				state = "revoked " + time.Unix(key.RevokedAt, 0).UTC().Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", key.ID, key.ClientID, time.Unix(key.CreatedAt, 0).UTC().Format(time.RFC3339), state)
+		}
+	case "encrypt":
+		encrypted, err := authCore.EncryptKeys(ctx)
+		if err != nil {
+			log.Fatalf("failed to encrypt the keys: %v", err)
+		}
+		log.Printf("encrypted %d keys", encrypted)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// setRole sets the role of the client, which must be one of the configured roles.
+func setRole(ctx context.Context, authCore auth.ICore, client string, role string) {
+	roles := app.Context().Config().Policy.Roles
+	if len(roles) == 0 {
+		roles = policy.DefaultRoles()
+	}
+	if _, ok := roles[role]; !ok {
+		log.Fatalf("unknown role %s", role)
+	}
+	if _, err := authCore.SetRole(ctx, client, role); err != nil {
+		log.Fatalf("failed to set the role: %v", err)
+	}
+	log.Printf("set the role of %s to %s", client, role)
+}
+
+func usage() {
+	fmt.Println("Usage: apikey issue -client NAME [-role ROLE] [-tenant TENANT]")
+	fmt.Println("       apikey role CLIENT ROLE")
+	fmt.Println("       apikey revoke KEY_ID")
+	fmt.Println("       apikey list [-client NAME]")
+	fmt.Println("       apikey encrypt")
+}
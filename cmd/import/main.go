@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"transaction-server/app"
+	"transaction-server/app/boot"
+	"transaction-server/internal/account"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/importer"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/tenant"
+	"transaction-server/internal/transaction"
+)
+
+var (
+	flags      = flag.NewFlagSet("import", flag.ExitOnError)
+	kind       = flags.String("kind", importer.KindTransactions, "Kind of records in the file: accounts or transactions")
+	format     = flags.String("format", "", "Format of the file: csv or ndjson (defaults to the file extension)")
+	batchSize  = flags.Int("batch-size", 100, "Number of records created and checkpointed together")
+	rejectPath = flags.String("rejects", "", "File rejected records are written to (defaults to FILE.rejects.FORMAT)")
+	checkpoint = flags.String("checkpoint", "", "Checkpoint file used to resume the import (defaults to FILE.checkpoint)")
+	restart    = flags.Bool("restart", false, "Ignore an existing checkpoint and import the file from the start")
+	tenantID   = flags.String("tenant", "", "Tenant the records are imported into (defaults to the default tenant)")
+)
+
+func main() {
+	flags.Usage = usage
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		log.Fatalf("error parsing the flags: %v", err)
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	input := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(input), ".")
+		if *format == "jsonl" {
+			*format = importer.FormatNDJSON
+		}
+	}
+	if *rejectPath == "" {
+		*rejectPath = fmt.Sprintf("%s.rejects.%s", input, *format)
+	}
+	if *checkpoint == "" {
+		*checkpoint = input + ".checkpoint"
+	}
+	if *restart {
+		if err := os.Remove(*checkpoint); err != nil && !os.IsNotExist(err) {
+			log.Fatalf("failed to remove the checkpoint: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	if err := boot.Initialize(ctx); err != nil {
+		log.Fatalf("failed to initialize the application: %v", err)
+	}
+	importTenant, err := tenant.NewResolver(app.Context().Config().Tenant).Resolve(nil, *tenantID)
+	if err != nil {
+		log.Fatalf("invalid tenant: %v", err)
+	}
+	// The records are imported by the operator running the import, as the system.
+	ctx = auth.WithSystemIdentity(db.WithTenant(ctx, importTenant))
+
+	commonRepo := db.NewRepo(app.Context().DB())
+	outboxCore := outbox.NewCore(commonRepo)
+	accountCore := account.NewCore(commonRepo, outboxCore)
+	transactionCore := transaction.NewCore(commonRepo, fxrate.NewCore(commonRepo), outboxCore)
+	imp, err := importer.New(accountCore, transactionCore, importer.Config{Kind: *kind, BatchSize: *batchSize})
+	if err != nil {
+		log.Fatalf("invalid import: %v", err)
+	}
+
+	cp, err := importer.LoadCheckpoint(*checkpoint, input)
+	if err != nil {
+		log.Fatalf("failed to load the checkpoint: %v", err)
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		log.Fatalf("failed to open the file: %v", err)
+	}
+	defer file.Close()
+	reader, err := importer.NewReader(file, *format)
+	if err != nil {
+		log.Fatalf("failed to read the file: %v", err)
+	}
+
+	// A resumed import appends to the rejects of the previous run.
+	rejectFlags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if cp.IsResumed() {
+		rejectFlags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	rejectFile, err := os.OpenFile(*rejectPath, rejectFlags, 0o644)
+	if err != nil {
+		log.Fatalf("failed to open the reject file: %v", err)
+	}
+	defer rejectFile.Close()
+	stat, err := rejectFile.Stat()
+	if err != nil {
+		log.Fatalf("failed to open the reject file: %v", err)
+	}
+	var header []string
+	if h, ok := reader.(interface{ Header() []string }); ok {
+		header = h.Header()
+	}
+	rejects, err := importer.NewRejectWriter(rejectFile, *format, header, stat.Size() == 0)
+	if err != nil {
+		log.Fatalf("failed to write the reject file: %v", err)
+	}
+
+	if cp.IsResumed() {
+		log.Printf("resuming %s after record %d", input, cp.Records)
+	}
+	if err := imp.Run(ctx, reader, rejects, cp); err != nil {
+		log.Fatalf("import stopped after record %d: %v", cp.Records, err)
+	}
+	log.Printf("imported %d %s, rejected %d (see %s)", cp.Imported, *kind, cp.Rejected, *rejectPath)
+}
+
+func usage() {
+	fmt.Println("Usage: import [flags] FILE")
+	flags.PrintDefaults()
+}
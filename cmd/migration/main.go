@@ -59,6 +59,8 @@ func main() {
 
 	// For other commands boot application (hence getting db and config ready).
 	// Read application's dialect and get sqldb instance.
+	// Migrations may run longer than the statements of the API are allowed to.
+	boot.Config.Db.StatementTimeout = 0
 	if err := boot.Initialize(context.Background()); err != nil {
 		log.Fatalf("failed to run command: %v", err)
 	}
@@ -2,25 +2,87 @@ package account
 
 import (
 	"context"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ICore interface {
 	Create(ctx context.Context, account *Account) error
 	Get(ctx context.Context, account *Account, id string) error
+	List(ctx context.Context, request IListRequest) (*[]Account, error)
 }
 
 type Core struct {
-	repo IRepo
+	repo   IRepo
+	outbox outbox.ICore
 }
 
-func NewCore(repo IRepo) *Core {
-	return &Core{repo: repo}
+func NewCore(repo IRepo, outbox outbox.ICore) *Core {
+	return &Core{repo: repo, outbox: outbox}
 }
 
+// Create creates the account, owned by the authenticated client of the context if any.
 func (c *Core) Create(ctx context.Context, account *Account) error {
-	return c.repo.Create(ctx, account)
+	ctx, span := tracing.Start(ctx, "account.Core.Create")
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		account.OwnerID = identity.ClientID
+	}
+	err := c.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := c.repo.Create(ctx, account); err != nil {
+			return err
+		}
+		event, err := outbox.NewEvent(outbox.TypeAccountCreated, outbox.AggregateAccount, account.ID, account.ToDto())
+		if err != nil {
+			return err
+		}
+		return c.outbox.Record(ctx, event)
+	})
+	if err == nil {
+		slog.InfoContext(ctx, "account created", "account_id", account.ID, "owner_id", account.OwnerID)
+		span.SetAttributes(attribute.String("account.id", account.ID))
+	}
+	tracing.End(span, err)
+	return err
 }
 
 func (c *Core) Get(ctx context.Context, account *Account, id string) error {
 	return c.repo.FindByID(ctx, account, id)
 }
+
+func (c *Core) List(ctx context.Context, request IListRequest) (*[]Account, error) {
+	ctx, span := tracing.Start(ctx, "account.Core.List")
+	accounts, err := c.list(ctx, request)
+	if err == nil {
+		span.SetAttributes(attribute.Int("account.count", len(*accounts)))
+	}
+	tracing.End(span, err)
+	return accounts, err
+}
+
+func (c *Core) list(ctx context.Context, request IListRequest) (*[]Account, error) {
+	conditions := make([]clause.Expression, 0)
+	if request.GetOwnerID() != "" {
+		conditions = append(conditions, clause.Eq{Column: "owner_id", Value: request.GetOwnerID()})
+	}
+	if request.GetDocumentNumber() != "" {
+		conditions = append(conditions, clause.Eq{Column: "document_number", Value: request.GetDocumentNumber()})
+	}
+	repoRequest := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{
+			Limit:  request.GetLimit(),
+			Offset: request.GetOffset(),
+		},
+		Conditions: conditions,
+	}
+	listResponse := make([]Account, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
@@ -7,21 +7,34 @@ import (
 	"testing"
 	"transaction-server/internal/account"
 	"transaction-server/internal/account/mock"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/outbox"
+	outboxmock "transaction-server/internal/outbox/mock"
 )
 
 type testDependencies struct {
 	mockRepoCtrl *gomock.Controller
 	mockRepo     *mock.MockIRepo
+	mockOutbox   *outboxmock.MockICore
 	core         account.ICore
 }
 
 func setupTest(t *testing.T) *testDependencies {
 	mockRepoCtrl := gomock.NewController(t)
 	mockRepo := mock.NewMockIRepo(mockRepoCtrl)
-	core := account.NewCore(mockRepo)
+	mockOutbox := outboxmock.NewMockICore(mockRepoCtrl)
+	core := account.NewCore(mockRepo, mockOutbox)
+	mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	).AnyTimes()
 	return &testDependencies{
 		mockRepoCtrl: mockRepoCtrl,
 		mockRepo:     mockRepo,
+		mockOutbox:   mockOutbox,
 		core:         core,
 	}
 }
@@ -35,6 +48,13 @@ func TestCore_Create(t *testing.T) {
 	defer teardownTest(td)
 
 	td.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	td.mockOutbox.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, events ...*outbox.Event) error {
+			if len(events) != 1 || events[0].Type != outbox.TypeAccountCreated {
+				t.Errorf("unexpected events: %v", events)
+			}
+			return nil
+		})
 
 	err := td.core.Create(context.Background(), &account.Account{})
 	if err != nil {
@@ -77,3 +97,42 @@ func TestCore_Get_Error(t *testing.T) {
 		t.Error("expected error but got nil")
 	}
 }
+
+func TestCore_List(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db.FindManyWithFiltersRequester) error {
+			if len(req.GetConditions()) != 1 {
+				t.Errorf("expected the document number condition, got %v", req.GetConditions())
+			}
+			*models.(*[]account.Account) = []account.Account{{Name: "John Doe"}}
+			return nil
+		})
+
+	accounts, err := td.core.List(context.Background(), &dto.ListAccountRequest{DocumentNumber: "123456789"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(*accounts) != 1 {
+		t.Errorf("expected 1 account, got %d", len(*accounts))
+	}
+}
+
+func TestCore_Create_Owned_By_Client(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	td.mockOutbox.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{ClientID: "0c000000000000"})
+	acc := &account.Account{}
+	if err := td.core.Create(ctx, acc); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if acc.OwnerID != "0c000000000000" {
+		t.Errorf("expected the account owned by the client, got %q", acc.OwnerID)
+	}
+}
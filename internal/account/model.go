@@ -1,6 +1,7 @@
 package account
 
 import (
+	"transaction-server/internal/common/currency"
 	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
 )
@@ -10,6 +11,8 @@ type Account struct {
 	db.Model              // Embedding the common database model
 	Name           string `json:"name,omitempty" audit:"name"`              // Name of the account
 	DocumentNumber string `json:"document_number,omitempty" audit:"doc_no"` // Document number associated with the account
+	Currency       string `json:"currency,omitempty" audit:"currency"`      // ISO 4217 currency the account settles in
+	OwnerID        string `json:"owner_id,omitempty" audit:"owner_id"`      // ID of the API client that created the account
 }
 
 // TableName returns the name of the database table for the Account entity.
@@ -24,6 +27,9 @@ func (e *Account) EntityName() string {
 
 // SetDefaults sets default values for the Account entity.
 func (e *Account) SetDefaults() error {
+	if e.Currency == "" {
+		e.Currency = currency.Default
+	}
 	return nil
 }
 
@@ -33,6 +39,7 @@ func (e *Account) ToDto() *dto.Account {
 		ID:             e.ID,
 		Name:           e.Name,
 		DocumentNumber: e.DocumentNumber,
+		Currency:       e.Currency,
 		CreatedAt:      e.CreatedAt,
 		UpdatedAt:      e.UpdatedAt,
 	}
@@ -42,4 +49,13 @@ func (e *Account) ToDto() *dto.Account {
 func (e *Account) ApplyDto(val *dto.Account) {
 	e.Name = val.Name
 	e.DocumentNumber = val.DocumentNumber
+	e.Currency = val.Currency
+}
+
+// IListRequest is the interface that wraps basic request attribute getters for list requests.
+type IListRequest interface {
+	GetLimit() uint32
+	GetOffset() uint32
+	GetDocumentNumber() string
+	GetOwnerID() string
 }
@@ -7,5 +7,7 @@ import (
 
 type IRepo interface {
 	FindByID(ctx context.Context, receiver db.IModel, id string) error
+	FindManyWithFilters(ctx context.Context, models interface{}, req db.FindManyWithFiltersRequester) error
 	Create(ctx context.Context, receiver db.IModel) error
+	Transaction(ctx context.Context, fc func(ctx context.Context) error) error
 }
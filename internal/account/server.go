@@ -4,26 +4,32 @@ import (
 	"github.com/gin-gonic/gin"
 	"transaction-server/internal/common"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
 	"transaction-server/internal/validator"
 )
 
 type IServer interface {
 	Create(ctx *gin.Context, req *dto.CreateAccountRequest) *dto.CreateAccountResponse
 	Get(ctx *gin.Context, id string) *dto.GetAccountResponse
+	List(ctx *gin.Context, req *dto.ListAccountRequest) *dto.ListAccountResponse
 }
 
 type Server struct {
-	core ICore
+	core   ICore
+	policy policy.IPolicy
 }
 
-func NewServer(core ICore) IServer {
-	return &Server{core: core}
+func NewServer(core ICore, policy policy.IPolicy) IServer {
+	return &Server{core: core, policy: policy}
 }
 
 func (s *Server) Create(ctx *gin.Context, req *dto.CreateAccountRequest) *dto.CreateAccountResponse {
 	if err := validator.NewValidAccount(req, validator.CreateAccountValidator); err != nil {
 		return &dto.CreateAccountResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
 	}
+	if err := s.policy.Authorize(ctx, policy.PermissionAccountsWrite); err != nil {
+		return &dto.CreateAccountResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	account := new(Account)
 	account.ApplyDto(req.Account)
 	if err := s.core.Create(ctx, account); err != nil {
@@ -36,9 +42,34 @@ func (s *Server) Get(ctx *gin.Context, id string) *dto.GetAccountResponse {
 	if err := validator.NewValidAccount(id, validator.GetAccountValidator); err != nil {
 		return &dto.GetAccountResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
 	}
+	if err := s.policy.Authorize(ctx, policy.PermissionAccountsRead); err != nil {
+		return &dto.GetAccountResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	account := new(Account)
 	if err := s.core.Get(ctx, account, id); err != nil {
 		return &dto.GetAccountResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
 	}
+	if err := s.policy.AuthorizeOwner(ctx, policy.PermissionAccountsRead, account.OwnerID); err != nil {
+		return &dto.GetAccountResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	return &dto.GetAccountResponse{Account: account.ToDto(), Base: &dto.Base{Success: true}}
 }
+
+func (s *Server) List(ctx *gin.Context, req *dto.ListAccountRequest) *dto.ListAccountResponse {
+	if err := validator.NewValidAccount(req, validator.ListAccountValidator); err != nil {
+		return &dto.ListAccountResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if err := s.policy.Authorize(ctx, policy.PermissionAccountsRead); err != nil {
+		return &dto.ListAccountResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	req.OwnerID = s.policy.OwnerScope(ctx)
+	accounts, err := s.core.List(ctx, req)
+	if err != nil {
+		return &dto.ListAccountResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	accountsDto := make([]*dto.Account, 0)
+	for _, account := range *accounts {
+		accountsDto = append(accountsDto, account.ToDto())
+	}
+	return &dto.ListAccountResponse{Accounts: accountsDto, Base: &dto.Base{Success: true}}
+}
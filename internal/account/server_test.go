@@ -1,6 +1,7 @@
 package account_test
 
 import (
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"testing"
@@ -9,8 +10,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"transaction-server/internal/account"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/common"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
 )
 
 type ServerTest struct {
@@ -22,7 +25,7 @@ type ServerTest struct {
 func setupServerTest(t *testing.T) *ServerTest {
 	mockCoreCtrl := gomock.NewController(t)
 	mockCore := mock.NewMockICore(mockCoreCtrl)
-	server := account.NewServer(mockCore)
+	server := account.NewServer(mockCore, policy.NewPolicy(nil, policy.Config{}))
 	return &ServerTest{
 		mockCoreCtrl: mockCoreCtrl,
 		mockCore:     mockCore,
@@ -38,7 +41,7 @@ func TestServer_Create_Success(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateAccountRequest{
 		Account: &dto.Account{
 			Name:           "John Doe",
@@ -56,7 +59,7 @@ func TestServer_Create_ValidationFailed(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateAccountRequest{
 		Account: &dto.Account{},
 	}
@@ -70,7 +73,7 @@ func TestServer_Create_DBPersistError(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateAccountRequest{
 		Account: &dto.Account{
 			Name:           "John Doe",
@@ -89,7 +92,7 @@ func TestServer_Get_Success(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "0b0e0000000000"
 
 	td.mockCore.EXPECT().Get(gomock.Any(), gomock.Any(), id).Return(nil)
@@ -102,7 +105,7 @@ func TestServer_Get_ValidationFailed(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "invalid-id"
 
 	resp := td.server.Get(ctx, id)
@@ -114,7 +117,7 @@ func TestServer_Get_DBQueryError(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "0b0e0000000000"
 
 	td.mockCore.EXPECT().Get(gomock.Any(), gomock.Any(), id).Return(errors.New("DB error"))
@@ -123,3 +126,73 @@ func TestServer_Get_DBQueryError(t *testing.T) {
 	assert.False(t, resp.Success)
 	assert.Equal(t, resp.Error.Code, common.ErrDBQueryError)
 }
+
+func TestServer_List_Success(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.ListAccountRequest{Limit: 2, DocumentNumber: "123456789"}
+
+	td.mockCore.EXPECT().List(gomock.Any(), req).Return(&[]account.Account{{Name: "John Doe"}}, nil)
+
+	resp := td.server.List(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Accounts, 1)
+}
+
+func TestServer_List_ValidationFailed(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	resp := td.server.List(ctx, &dto.ListAccountRequest{Limit: 21})
+	assert.False(t, resp.Success)
+	assert.Equal(t, resp.Error.Code, common.ErrValidationFailed)
+}
+
+// asClient returns a context authenticated as a client with the role.
+func asClient(role string) *gin.Context {
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: role})
+	return ctx
+}
+
+func TestServer_Create_Forbidden(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	req := &dto.CreateAccountRequest{Account: &dto.Account{Name: "John Doe", DocumentNumber: "123456789"}}
+	resp := td.server.Create(asClient(policy.RoleAgent), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+}
+
+func TestServer_Get_Forbidden_For_Other_Owner(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	td.mockCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, a *account.Account, id string) error {
+			a.OwnerID = "0c000000000002"
+			return nil
+		})
+
+	resp := td.server.Get(asClient(policy.RolePartner), "0b0e0000000000")
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+}
+
+func TestServer_List_Scoped_To_Owner(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	td.mockCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request account.IListRequest) (*[]account.Account, error) {
+			assert.Equal(t, "0c000000000001", request.GetOwnerID())
+			return &[]account.Account{}, nil
+		})
+
+	resp := td.server.List(asClient(policy.RolePartner), &dto.ListAccountRequest{Limit: 2})
+	assert.True(t, resp.Success)
+}
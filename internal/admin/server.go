@@ -0,0 +1,40 @@
+// Package admin serves the operational endpoints of the API.
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/config"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
+)
+
+type IServer interface {
+	GetConfig(ctx *gin.Context) *dto.GetConfigResponse
+}
+
+type Server struct {
+	policy  policy.IPolicy
+	static  config.AppConfig
+	runtime *config.Runtime
+}
+
+func NewServer(policy policy.IPolicy, static config.AppConfig, runtime *config.Runtime) IServer {
+	return &Server{policy: policy, static: static, runtime: runtime}
+}
+
+// GetConfig returns the static configuration with the current version of the runtime
+// configuration applied, without its secrets.
+func (s *Server) GetConfig(ctx *gin.Context) *dto.GetConfigResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionConfigRead); err != nil {
+		return &dto.GetConfigResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	snapshot := s.runtime.Current()
+	return &dto.GetConfigResponse{
+		Base:        &dto.Base{Success: true},
+		Version:     snapshot.Version,
+		LoadedAt:    snapshot.LoadedAt.Unix(),
+		ReloadError: s.runtime.LastError(),
+		Config:      snapshot.Config.Apply(s.static).Redacted(),
+		Features:    snapshot.Config.Features,
+	}
+}
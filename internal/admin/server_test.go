@@ -0,0 +1,62 @@
+package admin_test
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/admin"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/config"
+	"transaction-server/internal/policy"
+	policymock "transaction-server/internal/policy/mock"
+)
+
+func setupServer(t *testing.T) admin.IServer {
+	var static config.AppConfig
+	static.App.ServiceName = "api"
+	static.Db.Password = "secret"
+	static.Transaction.BatchMaxSize = 100
+	runtime, err := config.NewRuntime(nil, static.Runtime())
+	require.NoError(t, err)
+	accessPolicy := policy.NewPolicy(policymock.NewMockIRepo(gomock.NewController(t)), policy.Config{})
+	return admin.NewServer(accessPolicy, static, runtime)
+}
+
+func TestServer_GetConfig(t *testing.T) {
+	server := setupServer(t)
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: policy.RoleAdmin})
+
+	response := server.GetConfig(ctx)
+
+	assert.True(t, response.Success)
+	assert.Equal(t, uint64(1), response.Version)
+	effective := response.Config.(config.AppConfig)
+	assert.Equal(t, "api", effective.App.ServiceName)
+	assert.Equal(t, 100, effective.Transaction.BatchMaxSize)
+	assert.Equal(t, "REDACTED", effective.Db.Password)
+}
+
+func TestServer_GetConfig_Forbidden(t *testing.T) {
+	server := setupServer(t)
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: policy.RoleAgent})
+
+	response := server.GetConfig(ctx)
+
+	assert.False(t, response.Success)
+	assert.Equal(t, common.ErrForbidden, response.Error.Code)
+}
+
+func TestServer_GetConfig_Unauthenticated(t *testing.T) {
+	server := setupServer(t)
+
+	response := server.GetConfig(&gin.Context{})
+
+	assert.False(t, response.Success)
+	assert.Equal(t, common.ErrUnauthorized, response.Error.Code)
+}
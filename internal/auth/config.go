@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Enabled requires every API request to be signed with an API key.
+	Enabled bool
+	// ReplayWindow is how far the timestamp of a request may be from the server clock.
+	// Signatures are remembered for the window in the database, so a captured request
+	// cannot be replayed, against this instance or another.
+	ReplayWindow time.Duration
+	// MaxBodySize bounds the body of a request, in bytes, as it is read whole to check its
+	// digest; larger requests are rejected with 413. It defaults to 1 MiB.
+	MaxBodySize int64
+	// EncryptionKey is the base64 encoding of the 32 bytes AES-256 key the signing keys of
+	// the API keys are encrypted with in the database.
+	EncryptionKey string
+}
+
+// Validate validates the replay window and the encryption key are set when authentication
+// is enabled and the body size is not negative.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.ReplayWindow, validation.Required.When(c.Enabled), validation.Min(time.Duration(0))),
+		validation.Field(&c.EncryptionKey, validation.Required.When(c.Enabled), validation.By(isEncryptionKey)),
+		validation.Field(&c.MaxBodySize, validation.Min(int64(0))),
+	)
+}
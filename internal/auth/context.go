@@ -0,0 +1,51 @@
+package auth
+
+import "context"
+
+// ContextKeyIdentity is the gin context key the identity of the client is set under.
+const ContextKeyIdentity = "auth.identity"
+
+// Identity is the authenticated client of a request.
+type Identity struct {
+	ClientID   string
+	ClientName string
+	KeyID      string
+	Role       string
+	// TenantID is the tenant the client belongs to.
+	TenantID string
+	// System is set on the identity of the server itself, which every action is allowed to.
+	System bool
+}
+
+// SystemClientName is the client name of the system identity.
+const SystemClientName = "system"
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// WithSystemIdentity returns a copy of ctx carrying the identity of the server itself, for
+// the trusted callers acting on their own rather than for a client: the importer, the
+// background workers and, as the operator trusts them so, the requests served while
+// authentication is disabled.
+func WithSystemIdentity(ctx context.Context) context.Context {
+	return WithIdentity(ctx, systemIdentity())
+}
+
+func systemIdentity() *Identity {
+	return &Identity{ClientName: SystemClientName, System: true}
+}
+
+// IdentityFromContext returns the identity of the authenticated client, if any. A gin
+// context carries it under ContextKeyIdentity, whether or not it falls back to the
+// request context.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	if identity, ok := ctx.Value(identityKey{}).(*Identity); ok {
+		return identity, true
+	}
+	identity, ok := ctx.Value(ContextKeyIdentity).(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,255 @@
+// Package auth authenticates API requests signed with the keys issued to clients.
+//
+// Requests are signed with HMAC-SHA256, keyed by the signing key of the API key: the hex
+// SHA-256 digest of its secret. The server needs the signing key to verify the signatures,
+// and whoever holds it can sign requests as the client, so it is kept encrypted with
+// AES-256-GCM under the encryption key of the server (Config.EncryptionKey), in the
+// encrypted_signing_key column of api_keys. A copy of the table is of no use without the
+// encryption key, which must be kept out of the database and its backups.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
+	"transaction-server/internal/common/db"
+)
+
+var (
+	ErrUnknownKey       = errors.New("unknown API key")
+	ErrRevokedKey       = errors.New("API key is revoked")
+	ErrExpiredTimestamp = errors.New("request timestamp is outside the replay window")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrReplayedRequest  = errors.New("request has already been received")
+)
+
+// defaultReplayWindow is used when Config.ReplayWindow is not set.
+const defaultReplayWindow = 5 * time.Minute
+
+// sweepInterval is how often the signatures outside the replay window are deleted.
+const sweepInterval = time.Minute
+
+type ICore interface {
+	// IssueKey issues a new key to the named client, creating the client if needed. The
+	// returned API key holds the secret and cannot be recovered later.
+	IssueKey(ctx context.Context, clientName string) (*Key, string, error)
+	RevokeKey(ctx context.Context, keyID string) (*Key, error)
+	// SetRole sets the access role of the named client.
+	SetRole(ctx context.Context, clientName string, role string) (*Client, error)
+	ListKeys(ctx context.Context, clientName string) (*[]Key, error)
+	// EncryptKeys encrypts the signing keys stored in clear and returns how many it encrypted.
+	EncryptKeys(ctx context.Context) (int, error)
+	// Authenticate verifies the signature of a request and returns its client.
+	Authenticate(ctx context.Context, credentials *Credentials) (*Identity, error)
+}
+
+type Core struct {
+	repo   IRepo
+	config Config
+	now    func() time.Time
+	// sealer encrypts the signing keys, nil when the encryption key is missing or invalid,
+	// as sealerErr then reports.
+	sealer    *sealer
+	sealerErr error
+
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func NewCore(repo IRepo, config Config) *Core {
+	if config.ReplayWindow <= 0 {
+		config.ReplayWindow = defaultReplayWindow
+	}
+	sealer, err := newSealer(config.EncryptionKey)
+	return &Core{repo: repo, config: config, now: time.Now, sealer: sealer, sealerErr: err}
+}
+
+func (c *Core) IssueKey(ctx context.Context, clientName string) (*Key, string, error) {
+	if c.sealer == nil {
+		return nil, "", c.sealerErr
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	encryptedSigningKey, err := c.sealer.seal(SigningKey(secret))
+	if err != nil {
+		return nil, "", err
+	}
+	key := &Key{EncryptedSigningKey: encryptedSigningKey}
+	err = c.repo.Transaction(ctx, func(ctx context.Context) error {
+		client := &Client{}
+		err := c.repo.FindByConditions(ctx, client, []clause.Expression{clause.Eq{Column: "name", Value: clientName}})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			client.Name = clientName
+			err = c.repo.Create(ctx, client)
+		}
+		if err != nil {
+			return err
+		}
+		key.ClientID = client.ID
+		return c.repo.Create(ctx, key)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, key.ID + "." + secret, nil
+}
+
+func (c *Core) RevokeKey(ctx context.Context, keyID string) (*Key, error) {
+	// A key just issued may not have reached the replicas yet.
+	ctx = db.WithPrimary(ctx)
+	key := &Key{}
+	if err := c.repo.FindByID(ctx, key, keyID); err != nil {
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return key, nil
+	}
+	key.RevokedAt = c.now().Unix()
+	if err := c.repo.Update(ctx, key, "revoked_at"); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (c *Core) SetRole(ctx context.Context, clientName string, role string) (*Client, error) {
+	client := &Client{}
+	if err := c.repo.FindByConditions(ctx, client, []clause.Expression{clause.Eq{Column: "name", Value: clientName}}); err != nil {
+		return nil, err
+	}
+	client.Role = role
+	if err := c.repo.Update(ctx, client, "role"); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ListKeys lists the keys of the named client, or of every client when the name is empty.
+func (c *Core) ListKeys(ctx context.Context, clientName string) (*[]Key, error) {
+	var conditions []clause.Expression
+	if clientName != "" {
+		client := &Client{}
+		if err := c.repo.FindByConditions(ctx, client, []clause.Expression{clause.Eq{Column: "name", Value: clientName}}); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, clause.Eq{Column: "client_id", Value: client.ID})
+	}
+	listResponse := make([]Key, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{Limit: 1000},
+		Conditions:      conditions,
+	}); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
+
+// EncryptKeys encrypts the signing keys stored in clear, by the versions before they were
+// encrypted, and returns how many it encrypted.
+func (c *Core) EncryptKeys(ctx context.Context) (int, error) {
+	if c.sealer == nil {
+		return 0, c.sealerErr
+	}
+	encrypted := 0
+	for {
+		// The keys encrypted leave the condition, so each page is the first.
+		keys := make([]Key, 0)
+		if err := c.repo.FindManyWithFilters(db.WithPrimary(ctx), &keys, &db.FindManyWithConditionsRequest{
+			FindManyRequest: db.FindManyRequest{Limit: 1000},
+			Conditions: []clause.Expression{clause.Expr{
+				SQL: "encrypted_signing_key NOT LIKE ?", Vars: []interface{}{sealedPrefix + "%"},
+			}},
+		}); err != nil {
+			return encrypted, err
+		}
+		if len(keys) == 0 {
+			return encrypted, nil
+		}
+		for i := range keys {
+			sealed, err := c.sealer.seal(keys[i].EncryptedSigningKey)
+			if err != nil {
+				return encrypted, err
+			}
+			keys[i].EncryptedSigningKey = sealed
+			if err := c.repo.Update(ctx, &keys[i], "encrypted_signing_key"); err != nil {
+				return encrypted, err
+			}
+			encrypted++
+		}
+	}
+}
+
+func (c *Core) Authenticate(ctx context.Context, credentials *Credentials) (*Identity, error) {
+	now := c.now()
+	if skew := now.Sub(time.Unix(credentials.Timestamp, 0)); skew > c.config.ReplayWindow || skew < -c.config.ReplayWindow {
+		return nil, ErrExpiredTimestamp
+	}
+	// Keys and clients are read from the primary, for a key revoked, or a role changed, to
+	// take effect at once rather than once the replicas catch up.
+	ctx = db.WithPrimary(ctx)
+	key := &Key{}
+	if err := c.repo.FindByID(ctx, key, credentials.KeyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownKey
+		}
+		return nil, err
+	}
+	if key.IsRevoked() {
+		return nil, ErrRevokedKey
+	}
+	if c.sealer == nil {
+		return nil, c.sealerErr
+	}
+	signingKey, err := c.sealer.open(key.EncryptedSigningKey)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(credentials.Signature), []byte(credentials.Sign(signingKey))) {
+		return nil, ErrInvalidSignature
+	}
+	fresh, err := c.remember(ctx, credentials, now)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrReplayedRequest
+	}
+	client := &Client{}
+	if err := c.repo.FindByID(ctx, client, key.ClientID); err != nil {
+		return nil, err
+	}
+	return &Identity{ClientID: client.ID, ClientName: client.Name, KeyID: key.ID, Role: client.Role, TenantID: client.TenantID}, nil
+}
+
+// remember records a verified signature in the database, for every instance to know of it,
+// reporting false if it was already received. Signatures are forgotten once their timestamp
+// is outside the replay window, as it is then rejected.
+func (c *Core) remember(ctx context.Context, credentials *Credentials, now time.Time) (bool, error) {
+	if err := c.sweep(ctx, now); err != nil {
+		return false, err
+	}
+	q := c.repo.DBInstance(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&Nonce{
+		KeyID:     credentials.KeyID,
+		Signature: credentials.Signature,
+		ExpiresAt: time.Unix(credentials.Timestamp, 0).Add(c.config.ReplayWindow).Unix(),
+	})
+	return q.RowsAffected == 1, q.Error
+}
+
+// sweep deletes the signatures whose timestamp is outside the replay window, at most once
+// per sweep interval.
+func (c *Core) sweep(ctx context.Context, now time.Time) error {
+	c.mu.Lock()
+	if now.Sub(c.sweptAt) < sweepInterval {
+		c.mu.Unlock()
+		return nil
+	}
+	c.sweptAt = now
+	c.mu.Unlock()
+	return c.repo.DBInstance(ctx).Where("expires_at < ?", now.Unix()).Delete(&Nonce{}).Error
+}
@@ -0,0 +1,232 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+)
+
+// testConfig is the configuration of the cores under test.
+var testConfig = auth.Config{ReplayWindow: time.Minute, EncryptionKey: base64.StdEncoding.EncodeToString(make([]byte, 32))}
+
+// setupDB returns a fresh in-memory database holding the client and key tables.
+func setupDB(t *testing.T) *db.DB {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&auth.Client{}, &auth.Key{}, &auth.Nonce{}))
+	return gDb
+}
+
+// setupCore returns a core over a fresh in-memory database holding the client and key tables.
+func setupCore(t *testing.T) *auth.Core {
+	return auth.NewCore(&db.Repo{Db: setupDB(t)}, testConfig)
+}
+
+// sign returns the credentials of a request signed with the API key at the time.
+func sign(t *testing.T, apiKey string, at time.Time, body string) *auth.Credentials {
+	keyID, secret, err := auth.ParseAPIKey(apiKey)
+	require.NoError(t, err)
+	credentials := &auth.Credentials{
+		KeyID:      keyID,
+		Timestamp:  at.Unix(),
+		Method:     "POST",
+		Path:       "/accounts",
+		BodyDigest: auth.Digest([]byte(body)),
+	}
+	credentials.Signature = credentials.Sign(auth.SigningKey(secret))
+	return credentials
+}
+
+func TestCore_IssueKey_And_Authenticate(t *testing.T) {
+	core := setupCore(t)
+	ctx := context.Background()
+	key, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(apiKey, key.ID+"."))
+	secret := strings.TrimPrefix(apiKey, key.ID+".")
+	assert.NotContains(t, key.EncryptedSigningKey, secret)
+	assert.NotContains(t, key.EncryptedSigningKey, auth.SigningKey(secret))
+
+	identity, err := core.Authenticate(ctx, sign(t, apiKey, time.Now(), `{"name":"x"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Identity{ClientID: key.ClientID, ClientName: "partner", KeyID: key.ID, TenantID: db.DefaultTenant}, identity)
+
+	// A second key goes to the same client.
+	other, _, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+	assert.Equal(t, key.ClientID, other.ClientID)
+	keys, err := core.ListKeys(ctx, "partner")
+	require.NoError(t, err)
+	assert.Len(t, *keys, 2)
+
+	// The role of the client is part of its identity.
+	_, err = core.SetRole(ctx, "partner", "agent")
+	require.NoError(t, err)
+	identity, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), `{"name":"y"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "agent", identity.Role)
+}
+
+func TestCore_Authenticate_Rejects(t *testing.T) {
+	core := setupCore(t)
+	ctx := context.Background()
+	_, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	tampered := sign(t, apiKey, time.Now(), `{"amount":1}`)
+	tampered.BodyDigest = auth.Digest([]byte(`{"amount":1000}`))
+	unknown := sign(t, apiKey, time.Now(), "")
+	unknown.KeyID = "00000000000000"
+	wrongSecret := sign(t, unknown.KeyID+".wrong", time.Now(), "")
+	wrongSecret.KeyID = strings.Split(apiKey, ".")[0]
+
+	tests := []struct {
+		name        string
+		credentials *auth.Credentials
+		err         error
+	}{
+		{"tampered body", tampered, auth.ErrInvalidSignature},
+		{"wrong secret", wrongSecret, auth.ErrInvalidSignature},
+		{"unknown key", unknown, auth.ErrUnknownKey},
+		{"expired", sign(t, apiKey, time.Now().Add(-2*time.Minute), ""), auth.ErrExpiredTimestamp},
+		{"future", sign(t, apiKey, time.Now().Add(2*time.Minute), ""), auth.ErrExpiredTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := core.Authenticate(ctx, tt.credentials)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCore_Authenticate_Rejects_Replay(t *testing.T) {
+	core := setupCore(t)
+	ctx := context.Background()
+	_, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	credentials := sign(t, apiKey, time.Now(), "{}")
+	_, err = core.Authenticate(ctx, credentials)
+	require.NoError(t, err)
+	_, err = core.Authenticate(ctx, credentials)
+	assert.ErrorIs(t, err, auth.ErrReplayedRequest)
+}
+
+func TestCore_Authenticate_Rejects_Replay_To_Another_Instance(t *testing.T) {
+	repo := &db.Repo{Db: setupDB(t)}
+	core, other := auth.NewCore(repo, testConfig), auth.NewCore(repo, testConfig)
+	ctx := context.Background()
+	_, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	credentials := sign(t, apiKey, time.Now(), "{}")
+	_, err = core.Authenticate(ctx, credentials)
+	require.NoError(t, err)
+	_, err = other.Authenticate(ctx, credentials)
+	assert.ErrorIs(t, err, auth.ErrReplayedRequest)
+
+	// Another request of the key is let through by either.
+	_, err = other.Authenticate(ctx, sign(t, apiKey, time.Now(), "[]"))
+	assert.NoError(t, err)
+}
+
+func TestCore_Authenticate_Reads_The_Primary(t *testing.T) {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")), db.ReplicaDialectors(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = gDb.Close() })
+	replica := gDb.Replicas()[0]
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&auth.Client{}, &auth.Key{}, &auth.Nonce{}))
+	require.NoError(t, replica.AutoMigrate(&auth.Client{}, &auth.Key{}, &auth.Nonce{}))
+	core := auth.NewCore(&db.Repo{Db: gDb}, testConfig)
+	ctx := context.Background()
+
+	// The replica has not caught up with the key just issued.
+	key, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+	_, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), "issued"))
+	require.NoError(t, err)
+
+	// Nor with its revocation.
+	client := &auth.Client{}
+	require.NoError(t, gDb.Instance(ctx).Take(client, "id = ?", key.ClientID).Error)
+	require.NoError(t, replica.Create(client).Error)
+	require.NoError(t, replica.Create(key).Error)
+	_, err = core.RevokeKey(ctx, key.ID)
+	require.NoError(t, err)
+	_, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), "revoked"))
+	assert.ErrorIs(t, err, auth.ErrRevokedKey)
+}
+
+func TestCore_RevokeKey(t *testing.T) {
+	core := setupCore(t)
+	ctx := context.Background()
+	key, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	revoked, err := core.RevokeKey(ctx, key.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+
+	_, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), ""))
+	assert.ErrorIs(t, err, auth.ErrRevokedKey)
+}
+
+func TestCore_Authenticate_Needs_The_Encryption_Key(t *testing.T) {
+	gDb := setupDB(t)
+	ctx := context.Background()
+	_, apiKey, err := auth.NewCore(&db.Repo{Db: gDb}, testConfig).IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	// The signing key stored cannot be read without the encryption key it was encrypted with.
+	other := testConfig
+	other.EncryptionKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	_, err = auth.NewCore(&db.Repo{Db: gDb}, other).Authenticate(ctx, sign(t, apiKey, time.Now(), ""))
+	assert.ErrorIs(t, err, auth.ErrUndecryptableKey)
+
+	missing := testConfig
+	missing.EncryptionKey = ""
+	_, err = auth.NewCore(&db.Repo{Db: gDb}, missing).Authenticate(ctx, sign(t, apiKey, time.Now(), ""))
+	assert.ErrorIs(t, err, auth.ErrNoEncryptionKey)
+	_, _, err = auth.NewCore(&db.Repo{Db: gDb}, missing).IssueKey(ctx, "partner")
+	assert.ErrorIs(t, err, auth.ErrNoEncryptionKey)
+}
+
+func TestCore_EncryptKeys(t *testing.T) {
+	gDb := setupDB(t)
+	core := auth.NewCore(&db.Repo{Db: gDb}, testConfig)
+	ctx := context.Background()
+	key, apiKey, err := core.IssueKey(ctx, "partner")
+	require.NoError(t, err)
+
+	// A key issued before the signing keys were encrypted holds its signing key in clear.
+	secret := strings.TrimPrefix(apiKey, key.ID+".")
+	require.NoError(t, gDb.Instance(ctx).Model(key).Update("encrypted_signing_key", auth.SigningKey(secret)).Error)
+	_, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), "clear"))
+	assert.ErrorIs(t, err, auth.ErrUnencryptedKey)
+
+	encrypted, err := core.EncryptKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, encrypted)
+	stored := &auth.Key{}
+	require.NoError(t, gDb.Instance(ctx).Take(stored, "id = ?", key.ID).Error)
+	assert.NotContains(t, stored.EncryptedSigningKey, auth.SigningKey(secret))
+	_, err = core.Authenticate(ctx, sign(t, apiKey, time.Now(), "encrypted"))
+	assert.NoError(t, err)
+
+	// The keys encrypted already are left alone.
+	encrypted, err = core.EncryptKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, encrypted)
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrBodyDigestMismatch = errors.New("body does not match " + HeaderContentDigest)
+
+// defaultMaxBodySize is used when Config.MaxBodySize is not set.
+const defaultMaxBodySize = 1 << 20
+
+// Middleware authenticates each request by its signature, aborting it with 401 when the
+// signature is missing or invalid, or with 413 when the body, read whole to check its
+// digest, is larger than Config.MaxBodySize. The identity of the client is put on the
+// request context, where core code reads it with IdentityFromContext.
+func Middleware(core ICore, config Config) gin.HandlerFunc {
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+	return func(ctx *gin.Context) {
+		identity, err := authenticate(ctx, core, maxBodySize)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, dto.GetErrorResponse(common.ErrPayloadTooLarge,
+				fmt.Sprintf("request body is larger than %d bytes", maxBytesErr.Limit)))
+			return
+		}
+		if err != nil {
+			ctx.Header("WWW-Authenticate", Scheme)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.GetErrorResponse(common.ErrUnauthorized, err.Error()))
+			return
+		}
+		ctx.Set(ContextKeyIdentity, identity)
+		ctx.Request = ctx.Request.WithContext(WithIdentity(ctx.Request.Context(), identity))
+		ctx.Next()
+	}
+}
+
+// SystemMiddleware serves each request as the system, in place of Middleware when
+// authentication is disabled. It comes after the middlewares telling the clients apart,
+// such as rate limiting, for them to key the requests by client IP.
+func SystemMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity := systemIdentity()
+		ctx.Set(ContextKeyIdentity, identity)
+		ctx.Request = ctx.Request.WithContext(WithIdentity(ctx.Request.Context(), identity))
+		ctx.Next()
+	}
+}
+
+func authenticate(ctx *gin.Context, core ICore, maxBodySize int64) (*Identity, error) {
+	credentials, err := ParseAuthorization(ctx.GetHeader("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	// The body is read to check its digest and put back for the handler to bind.
+	var body []byte
+	if ctx.Request.Body != nil {
+		if body, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize)); err != nil {
+			return nil, err
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	credentials.Method = ctx.Request.Method
+	credentials.Path = ctx.Request.URL.RequestURI()
+	credentials.BodyDigest = Digest(body)
+	if !hmac.Equal([]byte(ctx.GetHeader(HeaderContentDigest)), []byte(credentials.BodyDigest)) {
+		return nil, ErrBodyDigestMismatch
+	}
+	return core.Authenticate(ctx.Request.Context(), credentials)
+}
@@ -0,0 +1,120 @@
+package auth_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/auth/mock"
+)
+
+func setupRouter(core auth.ICore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(auth.Middleware(core, auth.Config{MaxBodySize: 64}))
+	router.POST("/accounts", func(ctx *gin.Context) {
+		identity, _ := auth.IdentityFromContext(ctx.Request.Context())
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.JSON(http.StatusOK, gin.H{"client": identity.ClientName, "body": string(body)})
+	})
+	return router
+}
+
+func TestMiddleware_Authenticates_Signed_Request(t *testing.T) {
+	core := mock.NewMockICore(gomock.NewController(t))
+	body := `{"name":"John Doe"}`
+	core.EXPECT().Authenticate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, credentials *auth.Credentials) (*auth.Identity, error) {
+			assert.Equal(t, "0a000000000000", credentials.KeyID)
+			assert.Equal(t, "POST", credentials.Method)
+			assert.Equal(t, "/accounts?dry=1", credentials.Path)
+			assert.Equal(t, auth.Digest([]byte(body)), credentials.BodyDigest)
+			assert.Equal(t, credentials.Sign(auth.SigningKey("secret")), credentials.Signature)
+			return &auth.Identity{ClientID: "0c000000000000", ClientName: "partner", KeyID: credentials.KeyID}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts?dry=1", bytes.NewBufferString(body))
+	require.NoError(t, auth.SignRequest(req, "0a000000000000.secret", time.Now()))
+	recorder := httptest.NewRecorder()
+	setupRouter(core).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"client":"partner","body":"{\"name\":\"John Doe\"}"}`, recorder.Body.String())
+}
+
+func TestMiddleware_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(req *http.Request)
+		message string
+	}{
+		{"missing credentials", func(req *http.Request) {}, auth.ErrMissingCredentials.Error()},
+		{"other scheme", func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") }, auth.ErrMalformedHeader.Error()},
+		{"body digest mismatch", func(req *http.Request) {
+			require.NoError(t, auth.SignRequest(req, "0a000000000000.secret", time.Now()))
+			req.Header.Set(auth.HeaderContentDigest, auth.Digest([]byte("other")))
+		}, auth.ErrBodyDigestMismatch.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := mock.NewMockICore(gomock.NewController(t))
+			req := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewBufferString("{}"))
+			tt.prepare(req)
+			recorder := httptest.NewRecorder()
+			setupRouter(core).ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Equal(t, auth.Scheme, recorder.Header().Get("WWW-Authenticate"))
+			assert.JSONEq(t, `{"success":false,"error":{"code":"ERR_UNAUTHORIZED_ERROR","message":"`+tt.message+`"}}`, recorder.Body.String())
+		})
+	}
+}
+
+func TestMiddleware_Rejects_Failed_Authentication(t *testing.T) {
+	core := mock.NewMockICore(gomock.NewController(t))
+	core.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil, auth.ErrRevokedKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewBufferString("{}"))
+	require.NoError(t, auth.SignRequest(req, "0a000000000000.secret", time.Now()))
+	recorder := httptest.NewRecorder()
+	setupRouter(core).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestMiddleware_Rejects_Body_Too_Large(t *testing.T) {
+	core := mock.NewMockICore(gomock.NewController(t))
+	req := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewBufferString(`{"name":"`+strings.Repeat("x", 64)+`"}`))
+	require.NoError(t, auth.SignRequest(req, "0a000000000000.secret", time.Now()))
+	recorder := httptest.NewRecorder()
+	setupRouter(core).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	assert.JSONEq(t, `{"success":false,"error":{"code":"ERR_PAYLOAD_TOO_LARGE_ERROR","message":"request body is larger than 64 bytes"}}`, recorder.Body.String())
+}
+
+func TestSystemMiddleware_Serves_Requests_As_The_System(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(auth.SystemMiddleware())
+	router.GET("/accounts", func(ctx *gin.Context) {
+		fromRequest, _ := auth.IdentityFromContext(ctx.Request.Context())
+		fromGin, _ := auth.IdentityFromContext(ctx)
+		ctx.JSON(http.StatusOK, gin.H{"system": fromRequest.System && fromGin.System, "client": fromRequest.ClientName})
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"system":true,"client":"system"}`, recorder.Body.String())
+}
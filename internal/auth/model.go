@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"transaction-server/internal/common/db"
+)
+
+// Client is a caller of the API. A client holds any number of keys, so keys can be
+// rotated without downtime.
+type Client struct {
+	db.Model        // Embedding the common database model
+	Name     string `json:"name"` // Unique name of the client
+	Role     string `json:"role"` // Access role of the client, the configured default when empty
+}
+
+// TableName returns the name of the database table for the Client entity.
+func (e *Client) TableName() string {
+	return "api_clients"
+}
+
+// EntityName returns the name of the entity.
+func (e *Client) EntityName() string {
+	return "api_client"
+}
+
+// SetDefaults sets default values for the Client entity.
+func (e *Client) SetDefaults() error {
+	return nil
+}
+
+// Key is an API key of a client. The ID of the key is public; its secret is not stored,
+// and its signing key is stored encrypted, see the package documentation.
+type Key struct {
+	db.Model                   // Embedding the common database model
+	ClientID            string `json:"client_id"`             // ID of the client the key belongs to
+	EncryptedSigningKey string `json:"encrypted_signing_key"` // Signing key of the key, see SigningKey, encrypted by the server
+	RevokedAt           int64  `json:"revoked_at"`            // Time the key was revoked (Unix timestamp), 0 while active
+}
+
+// TableName returns the name of the database table for the Key entity.
+func (e *Key) TableName() string {
+	return "api_keys"
+}
+
+// EntityName returns the name of the entity.
+func (e *Key) EntityName() string {
+	return "api_key"
+}
+
+// SetDefaults sets default values for the Key entity.
+func (e *Key) SetDefaults() error {
+	return nil
+}
+
+// IsRevoked reports whether the key has been revoked.
+func (e *Key) IsRevoked() bool {
+	return e.RevokedAt != 0
+}
+
+// newSecret returns a random key secret.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SigningKey returns the HMAC key of requests signed with the secret: its hex SHA-256
+// digest. The server stores it encrypted rather than the secret.
+func SigningKey(secret string) string {
+	digest := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(digest[:])
+}
+
+// Nonce is a signature received within the replay window, kept for the request it signs
+// not to be accepted again by any instance.
+type Nonce struct {
+	KeyID     string `gorm:"primaryKey"`
+	Signature string `gorm:"primaryKey"`
+	// ExpiresAt is when the timestamp of the request leaves the replay window, in Unix seconds.
+	ExpiresAt int64
+}
+
+func (Nonce) TableName() string {
+	return "api_request_nonces"
+}
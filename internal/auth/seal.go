@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// sealedPrefix marks the signing keys encrypted by a sealer, from the ones stored in clear
+// before the signing keys were encrypted.
+const sealedPrefix = "v1:"
+
+// encryptionKeySize is the size of the AES-256 key the signing keys are encrypted with.
+const encryptionKeySize = 32
+
+var (
+	ErrNoEncryptionKey      = errors.New("no encryption key is configured for the signing keys")
+	ErrInvalidEncryptionKey = errors.New("encryption key must be the base64 encoding of 32 bytes")
+	ErrUnencryptedKey       = errors.New("signing key of the API key is not encrypted")
+	ErrUndecryptableKey     = errors.New("signing key of the API key cannot be decrypted")
+)
+
+// sealer encrypts the signing keys at rest with AES-256-GCM under the encryption key of
+// the server.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newSealer returns the sealer of the base64 encoded encryption key.
+func newSealer(encryptionKey string) (*sealer, error) {
+	if encryptionKey == "" {
+		return nil, ErrNoEncryptionKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil || len(key) != encryptionKeySize {
+		return nil, ErrInvalidEncryptionKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts the signing key.
+func (s *sealer) seal(signingKey string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(signingKey), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a signing key encrypted by seal.
+func (s *sealer) open(sealed string) (string, error) {
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", ErrUnencryptedKey
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return "", ErrUndecryptableKey
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	signingKey, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrUndecryptableKey
+	}
+	return string(signingKey), nil
+}
+
+// isEncryptionKey validates the value is a base64 encoded encryption key, if set.
+func isEncryptionKey(value interface{}) error {
+	encryptionKey, _ := value.(string)
+	if encryptionKey == "" {
+		return nil
+	}
+	_, err := newSealer(encryptionKey)
+	return err
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Scheme is the scheme of the Authorization header of signed requests.
+	Scheme = "HMAC-SHA256"
+	// HeaderContentDigest holds the hex SHA-256 digest of the request body.
+	HeaderContentDigest = "X-Content-SHA256"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrMalformedHeader    = errors.New("malformed Authorization header")
+	ErrInvalidAPIKey      = errors.New("invalid API key")
+)
+
+// Credentials are the signature of a request and what it signs.
+type Credentials struct {
+	KeyID     string
+	Timestamp int64
+	Signature string
+	// Method, Path and BodyDigest are the signed parts of the request. Path includes
+	// the query string.
+	Method     string
+	Path       string
+	BodyDigest string
+}
+
+// StringToSign returns the canonical string signed by the credentials.
+func (c *Credentials) StringToSign() string {
+	return strings.Join([]string{strconv.FormatInt(c.Timestamp, 10), c.Method, c.Path, c.BodyDigest}, "\n")
+}
+
+// Sign returns the hex HMAC-SHA256 of the string to sign keyed with the signing key.
+func (c *Credentials) Sign(signingKey string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(c.StringToSign()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authorization returns the value of the Authorization header carrying the credentials.
+func (c *Credentials) Authorization() string {
+	return fmt.Sprintf("%s KeyId=%s, Timestamp=%d, Signature=%s", Scheme, c.KeyID, c.Timestamp, c.Signature)
+}
+
+// Digest returns the hex SHA-256 digest of a body.
+func Digest(body []byte) string {
+	digest := sha256.Sum256(body)
+	return hex.EncodeToString(digest[:])
+}
+
+// ParseAuthorization reads the key ID, timestamp and signature of an Authorization header.
+func ParseAuthorization(header string) (*Credentials, error) {
+	if header == "" {
+		return nil, ErrMissingCredentials
+	}
+	scheme, params, ok := strings.Cut(header, " ")
+	if !ok || scheme != Scheme {
+		return nil, ErrMalformedHeader
+	}
+	credentials := new(Credentials)
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "KeyId":
+			credentials.KeyID = value
+		case "Timestamp":
+			timestamp, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, ErrMalformedHeader
+			}
+			credentials.Timestamp = timestamp
+		case "Signature":
+			credentials.Signature = value
+		}
+	}
+	if credentials.KeyID == "" || credentials.Timestamp == 0 || credentials.Signature == "" {
+		return nil, ErrMalformedHeader
+	}
+	return credentials, nil
+}
+
+// ParseAPIKey splits an API key into the ID of the key and its secret.
+func ParseAPIKey(apiKey string) (string, string, error) {
+	keyID, secret, ok := strings.Cut(apiKey, ".")
+	if !ok || keyID == "" || secret == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+	return keyID, secret, nil
+}
+
+// SignRequest signs an outgoing request with the API key, setting the Authorization and
+// HeaderContentDigest headers. The body is read and replaced so the request can still be sent.
+func SignRequest(req *http.Request, apiKey string, now time.Time) error {
+	keyID, secret, err := ParseAPIKey(apiKey)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if req.Body != nil {
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	credentials := &Credentials{
+		KeyID:      keyID,
+		Timestamp:  now.Unix(),
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		BodyDigest: Digest(body),
+	}
+	credentials.Signature = credentials.Sign(SigningKey(secret))
+	req.Header.Set("Authorization", credentials.Authorization())
+	req.Header.Set(HeaderContentDigest, credentials.BodyDigest)
+	return nil
+}
@@ -5,4 +5,9 @@ const (
 	ErrDBQueryError     string = "ERR_DB_QUERY_ERROR"
 	ErrValidationFailed string = "ERR_VALIDATION_FAILED_ERROR"
 	ErrNotFoundFailed   string = "ERR_NOT_FOUND_ERROR"
+	ErrUnauthorized     string = "ERR_UNAUTHORIZED_ERROR"
+	ErrForbidden        string = "ERR_FORBIDDEN_ERROR"
+	ErrRateLimited      string = "ERR_RATE_LIMITED_ERROR"
+	ErrTimeout          string = "ERR_TIMEOUT_ERROR"
+	ErrPayloadTooLarge  string = "ERR_PAYLOAD_TOO_LARGE_ERROR"
 )
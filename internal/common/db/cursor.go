@@ -0,0 +1,122 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// CursorModel is implemented by models which can be paginated by cursor.
+// The embedded Model satisfies it.
+type CursorModel interface {
+	GetID() string
+	GetCreatedAt() int64
+}
+
+// Cursor is the position of a row in a list ordered by (created_at, id).
+// It is handed to clients as an opaque token.
+type Cursor struct {
+	CreatedAt int64  `json:"c"`
+	ID        string `json:"i"`
+	// Backward marks cursors which point at the page before the row.
+	Backward bool `json:"b,omitempty"`
+}
+
+// Encode returns the opaque token of the cursor.
+func (c Cursor) Encode() string {
+	value, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode.
+// An empty token decodes to a nil cursor, i.e. the first page.
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	value, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := new(Cursor)
+	if err = json.Unmarshal(value, cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Page holds the cursors of the pages around a result set.
+// A cursor is empty when there is no page in that direction.
+type Page struct {
+	NextCursor string
+	PrevCursor string
+}
+
+// FindManyWithCursorRequester is the interface that wraps attribute getters of keyset paginated requests.
+type FindManyWithCursorRequester interface {
+	GetLimit() uint32
+	GetConditions() []clause.Expression
+	GetCursor() string
+	IsAscending() bool
+}
+
+// FindManyWithCursorRequest is a request to find a page of a model type ordered by (created_at, id).
+type FindManyWithCursorRequest struct {
+	Limit      uint32
+	Conditions []clause.Expression
+	Cursor     string
+	Ascending  bool
+}
+
+// GetLimit returns limit.
+func (r FindManyWithCursorRequest) GetLimit() uint32 {
+	return r.Limit
+}
+
+// GetConditions returns list of additional filters on entity.
+func (r FindManyWithCursorRequest) GetConditions() []clause.Expression {
+	return r.Conditions
+}
+
+// GetCursor returns the token of the page to fetch.
+func (r FindManyWithCursorRequest) GetCursor() string {
+	return r.Cursor
+}
+
+// IsAscending returns true if the rows are ordered oldest first.
+func (r FindManyWithCursorRequest) IsAscending() bool {
+	return r.Ascending
+}
+
+// keysetCondition selects the rows strictly after the cursor in the scan direction.
+func keysetCondition(cursor *Cursor, desc bool) clause.Expression {
+	if desc {
+		return clause.Or(
+			clause.Lt{Column: "created_at", Value: cursor.CreatedAt},
+			clause.And(
+				clause.Eq{Column: "created_at", Value: cursor.CreatedAt},
+				clause.Lt{Column: "id", Value: cursor.ID},
+			),
+		)
+	}
+	return clause.Or(
+		clause.Gt{Column: "created_at", Value: cursor.CreatedAt},
+		clause.And(
+			clause.Eq{Column: "created_at", Value: cursor.CreatedAt},
+			clause.Gt{Column: "id", Value: cursor.ID},
+		),
+	)
+}
+
+// cursorAt returns the cursor of the element at index i of the slice.
+func cursorAt(slice reflect.Value, i int, backward bool) string {
+	model := slice.Index(i).Addr().Interface().(CursorModel)
+	return Cursor{CreatedAt: model.GetCreatedAt(), ID: model.GetID(), Backward: backward}.Encode()
+}
@@ -9,6 +9,7 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/gobuffalo/nulls"
+	"transaction-server/internal/common/currency"
 )
 
 const (
@@ -107,6 +108,21 @@ func IsBasicString(value interface{}) error {
 	return isValidString(value, RegexBasicString)
 }
 
+// IsCurrencyCode validates if the given value is an active ISO 4217 currency code
+func IsCurrencyCode(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if str, err := isString(value); err != nil {
+		return err
+	} else if str != "" && !currency.IsValid(str) {
+		return errors.New("must be a valid ISO 4217 currency code")
+	}
+
+	return nil
+}
+
 // IsInt64 checks if the given data is valid int64 or not
 func IsInt64(value interface{}) error {
 	if _, ok := value.(int64); !ok {
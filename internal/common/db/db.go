@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -20,8 +22,12 @@ const (
 	PostgresConnectionDSNFormatWithSchema = "host=%s port=%d dbname=%s sslmode=%s user=%s password=%s search_path=%s"
 
 	// MysqlConnectionDSNFormat is mysql connection path format for gorm.
-	// E.g. app:password@tcp(localhost:3306)/app?charset=utf8&parseTime=True&loc=Local
-	MysqlConnectionDSNFormat = "%s:%s@%s(%s:%d)/%s?charset=utf8&parseTime=True&loc=Local"
+	// E.g. app:password@tcp(localhost:3306)/app?charset=utf8mb4&parseTime=True&loc=Local
+	MysqlConnectionDSNFormat = "%s:%s@%s(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local"
+
+	// The statement timeouts are set on the session of each connection, in milliseconds.
+	mysqlStatementTimeoutFormat    = "&max_execution_time=%d"
+	postgresStatementTimeoutFormat = " statement_timeout=%d"
 )
 
 const (
@@ -73,6 +79,9 @@ type ConnectionConfig struct {
 	SslMode  string
 	Name     string
 	Schema   string //can be used in Postgres (optional)
+	// StatementTimeout aborts the statements running longer, 0 sets none. MySQL only
+	// applies it to SELECT statements.
+	StatementTimeout time.Duration
 }
 
 // GetDialect returns a dialect identifier
@@ -84,12 +93,20 @@ func (c *ConnectionConfig) GetDialect() string {
 func (c *ConnectionConfig) GetConnectionPath() string {
 	switch c.Dialect {
 	case DialectPostgres:
-		if c.Schema == "" {
-			return fmt.Sprintf(PostgresConnectionDSNFormat, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password)
+		path := fmt.Sprintf(PostgresConnectionDSNFormat, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password)
+		if c.Schema != "" {
+			path = fmt.Sprintf(PostgresConnectionDSNFormatWithSchema, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password, c.Schema)
+		}
+		if c.StatementTimeout > 0 {
+			path += fmt.Sprintf(postgresStatementTimeoutFormat, c.StatementTimeout.Milliseconds())
 		}
-		return fmt.Sprintf(PostgresConnectionDSNFormatWithSchema, c.URL, c.Port, c.Name, c.SslMode, c.Username, c.Password, c.Schema)
+		return path
 	case DialectMySQL:
-		return fmt.Sprintf(MysqlConnectionDSNFormat, c.Username, c.Password, c.Protocol, c.URL, c.Port, c.Name)
+		path := fmt.Sprintf(MysqlConnectionDSNFormat, c.Username, c.Password, c.Protocol, c.URL, c.Port, c.Name)
+		if c.StatementTimeout > 0 {
+			path += fmt.Sprintf(mysqlStatementTimeoutFormat, c.StatementTimeout.Milliseconds())
+		}
+		return path
 	default:
 		return ""
 	}
@@ -117,11 +134,17 @@ func (c *ConnectionPoolConfig) GetConnMaxLifetime() time.Duration {
 	return c.ConnectionMaxLifetime
 }
 
-// Config implements IConfigReader and holds configuration for the DB.
+// Config implements IConfigReader, IReplicaConfigReader and ITransactionRetryConfigReader and
+// holds configuration for the DB.
 type Config struct {
 	ConnectionConfig
 	ConnectionPoolConfig
-	Debug bool
+	// Replicas are the read replicas of the database, sharing the pool configuration.
+	Replicas []ConnectionConfig
+	// ReplicaCheckInterval is how often the replicas are pinged, see DefaultReplicaCheckInterval.
+	ReplicaCheckInterval time.Duration
+	TransactionRetry     TransactionRetryConfig
+	Debug                bool
 }
 
 // IsDebugMode returns true if the debug logs for the DB are to be enabled
@@ -129,12 +152,78 @@ func (c *Config) IsDebugMode() bool {
 	return c.Debug
 }
 
-// DB is the specific wrapper holding gorm db instance.
+// GetReplicas returns the connections of the read replicas.
+func (c *Config) GetReplicas() []IConnectionReader {
+	replicas := make([]IConnectionReader, 0, len(c.Replicas))
+	for i := range c.Replicas {
+		replicas = append(replicas, &c.Replicas[i])
+	}
+	return replicas
+}
+
+// GetReplicaCheckInterval returns how often the replicas are pinged.
+func (c *Config) GetReplicaCheckInterval() time.Duration {
+	return c.ReplicaCheckInterval
+}
+
+// GetTransactionRetryConfig returns how transactions are retried.
+func (c *Config) GetTransactionRetryConfig() TransactionRetryConfig {
+	return c.TransactionRetry
+}
+
+// Validate validates the connection and pool configuration.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.ConnectionConfig),
+		validation.Field(&c.ConnectionPoolConfig),
+		validation.Field(&c.Replicas),
+		validation.Field(&c.ReplicaCheckInterval, validation.Min(time.Duration(0))),
+		validation.Field(&c.TransactionRetry),
+	)
+}
+
+// Validate validates the retries configuration.
+func (c TransactionRetryConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxRetries, validation.Min(0)),
+		validation.Field(&c.MinBackoff, validation.Min(time.Duration(0))),
+		validation.Field(&c.MaxBackoff, validation.Min(c.MinBackoff)),
+	)
+}
+
+// Validate validates the connection configuration.
+func (c ConnectionConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Dialect, validation.Required, validation.In(DialectMySQL, DialectPostgres)),
+		validation.Field(&c.URL, validation.Required),
+		validation.Field(&c.Port, validation.Min(0), validation.Max(65535)),
+		validation.Field(&c.Name, validation.Required),
+		validation.Field(&c.StatementTimeout, validation.Min(time.Duration(0))),
+	)
+}
+
+// Validate validates the pool configuration.
+func (c ConnectionPoolConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxOpenConnections, validation.Min(0)),
+		validation.Field(&c.MaxIdleConnections, validation.Min(0)),
+		validation.Field(&c.ConnectionMaxLifetime, validation.Min(time.Duration(0))),
+	)
+}
+
+// DB is the specific wrapper holding gorm db instance, of the primary, and the instances
+// of the read replicas, if any.
 type DB struct {
-	configReader IConfigReader
-	dialector    gorm.Dialector
-	gormConfig   *gorm.Config
-	instance     *gorm.DB
+	configReader      IConfigReader
+	dialector         gorm.Dialector
+	replicaDialectors []gorm.Dialector
+	gormConfig        *gorm.Config
+	plugins           []gorm.Plugin
+	instance          *gorm.DB
+	replicas          []*replica
+	nextReplica       atomic.Uint32
+	onRetry           func(reason string)
+	closed            chan struct{}
 }
 
 // GormConfig if set, will override the default DB.gormConfig used
@@ -154,6 +243,14 @@ func Dialector(gd gorm.Dialector) func(*DB) error {
 	}
 }
 
+// Plugins if set, are used by the gorm instance, after the tenant scope.
+func Plugins(plugins ...gorm.Plugin) func(*DB) error {
+	return func(db *DB) error {
+		db.plugins = append(db.plugins, plugins...)
+		return nil
+	}
+}
+
 // NewDb instantiates Db and connects to database.
 //
 // Use options to set gorm.Config and gorm.Dialector.
@@ -165,12 +262,16 @@ func Dialector(gd gorm.Dialector) func(*DB) error {
 // If the gorm.Dialector is not set in the options, a default dialector
 // will be created based on dialect & connection from IConfigReader.GetDialect() &
 // IConfigReader.GetConnectionPath()
+//
+// The replicas are opened similarly, from db.ReplicaDialectors() or IReplicaConfigReader.
+// A replica which cannot be reached does not fail NewDb, it only takes no reads until it
+// answers a ping, made every IReplicaConfigReader.GetReplicaCheckInterval().
 func NewDb(cr IConfigReader, options ...func(*DB) error) (*DB, error) {
 	if cr == nil {
 		cr = &Config{}
 	}
 
-	db := &DB{configReader: cr}
+	db := &DB{configReader: cr, closed: make(chan struct{})}
 
 	for _, option := range options {
 		if err := option(db); err != nil {
@@ -184,6 +285,12 @@ func NewDb(cr IConfigReader, options ...func(*DB) error) (*DB, error) {
 		}
 	}
 
+	if len(db.replicaDialectors) == 0 {
+		if err := db.initReplicaDialectors(); err != nil {
+			return nil, err
+		}
+	}
+
 	if db.gormConfig == nil {
 		db.gormConfig = &gorm.Config{
 			AllowGlobalUpdate:      false,
@@ -198,6 +305,10 @@ func NewDb(cr IConfigReader, options ...func(*DB) error) (*DB, error) {
 		return nil, err
 	}
 
+	if len(db.replicas) > 0 {
+		go db.monitorReplicas(db.replicaCheckInterval())
+	}
+
 	return db, nil
 }
 
@@ -219,14 +330,15 @@ func (db *DB) Preload(ctx context.Context, query string, args ...interface{}) *D
 	return tx
 }
 
-// Instance returns underlying instance of gorm db.
+// Instance returns underlying instance of gorm db, of the primary.
 // If the transaction/session in progress then it'll return
 // the *gorm.DB from the context.
+// Statements run with ctx: they are cancelled when it is done.
 func (db *DB) Instance(ctx context.Context) *gorm.DB {
 	if instance, ok := ctx.Value(ContextKeyDatabase).(*gorm.DB); ok {
-		return instance
+		return instance.WithContext(ctx)
 	}
-	return db.instance
+	return db.instance.WithContext(ctx)
 }
 
 // GetInstance returns the instance from the gorm db
@@ -247,13 +359,39 @@ func (db *DB) Session(session *gorm.Session) *DB {
 
 // Alive executes a select query and checks if connection exists and is alive.
 func (db *DB) Alive() error {
+	return db.AliveContext(context.Background())
+}
+
+// AliveContext is Alive giving up when ctx is done.
+func (db *DB) AliveContext(ctx context.Context) error {
 	if dbi, err := db.instance.DB(); err != nil {
 		return err
 	} else {
-		return dbi.Ping()
+		return dbi.PingContext(ctx)
 	}
 }
 
+// Close closes the connection pools, waiting for the queries in flight to finish.
+func (db *DB) Close() error {
+	if db.closed != nil {
+		close(db.closed)
+	}
+	var errs []error
+	for _, r := range db.replicas {
+		if dbi, err := r.instance.DB(); err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, dbi.Close())
+		}
+	}
+	if dbi, err := db.instance.DB(); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, dbi.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func (db *DB) Dialector(ctx context.Context) gorm.Dialector {
 	return db.dialector
 }
@@ -267,23 +405,56 @@ func (db *DB) initDialector() (err error) {
 	return
 }
 
-// connect opens a gorm connection and configures other connection details.
+// connect opens the gorm connections and configures other connection details.
 func (db *DB) connect() error {
 	var err error
 
-	if db.instance, err = gorm.Open(db.dialector, db.gormConfig); err != nil {
+	// gorm.Open keeps and fills the config it is given, so each connection gets its copy.
+	gormConfig := *db.gormConfig
+	if db.instance, err = db.open(db.dialector, db.gormConfig); err != nil {
 		return err
 	}
 
+	for i, dialector := range db.replicaDialectors {
+		replicaConfig := gormConfig
+		replicaConfig.DisableAutomaticPing = true
+		r := &replica{name: fmt.Sprintf("replica-%d", i)}
+		if r.instance, err = db.open(dialector, &replicaConfig); err != nil {
+			return err
+		}
+		db.replicas = append(db.replicas, r)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), db.replicaCheckInterval())
+	defer cancel()
+	db.CheckReplicas(ctx)
+
+	return nil
+}
+
+// open opens a gorm connection with the plugins and the pool configuration.
+func (db *DB) open(dialector gorm.Dialector, gormConfig *gorm.Config) (*gorm.DB, error) {
+	instance, err := gorm.Open(dialector, gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.Use(tenantScope{}); err != nil {
+		return nil, err
+	}
+	for _, plugin := range db.plugins {
+		if err = instance.Use(plugin); err != nil {
+			return nil, err
+		}
+	}
+
 	var dbConn *sql.DB
-	if dbConn, err = db.instance.DB(); err != nil {
-		return err
+	if dbConn, err = instance.DB(); err != nil {
+		return nil, err
 	}
 	dbConn.SetMaxIdleConns(db.configReader.GetMaxIdleConnections())
 	dbConn.SetMaxOpenConns(db.configReader.GetMaxOpenConnections())
 	dbConn.SetConnMaxLifetime(db.configReader.GetConnMaxLifetime() * time.Second)
 
-	return nil
+	return instance, nil
 }
 
 func getDialector(connReader IConnectionReader) (gorm.Dialector, error) {
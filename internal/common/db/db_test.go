@@ -0,0 +1,34 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/common/db"
+)
+
+func TestConnectionConfig_GetConnectionPath_StatementTimeout(t *testing.T) {
+	mysql := db.ConnectionConfig{Dialect: db.DialectMySQL, Protocol: "tcp", URL: "localhost", Port: 3306,
+		Username: "app", Password: "password", Name: "app", StatementTimeout: 30 * time.Second}
+	postgres := db.ConnectionConfig{Dialect: db.DialectPostgres, URL: "localhost", Port: 5432, SslMode: "disable",
+		Username: "app", Password: "password", Name: "app", StatementTimeout: 1500 * time.Millisecond}
+
+	assert.Equal(t, "app:password@tcp(localhost:3306)/app?charset=utf8mb4&parseTime=True&loc=Local&max_execution_time=30000",
+		mysql.GetConnectionPath())
+	assert.Equal(t, "host=localhost port=5432 dbname=app sslmode=disable user=app password=password statement_timeout=1500",
+		postgres.GetConnectionPath())
+
+	mysql.StatementTimeout = 0
+	assert.Equal(t, "app:password@tcp(localhost:3306)/app?charset=utf8mb4&parseTime=True&loc=Local", mysql.GetConnectionPath())
+}
+
+func TestDB_Instance_RunsWithTheContext(t *testing.T) {
+	repo := setupRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, repo.FindByID(ctx, &item{}, "item0000000000"), context.Canceled)
+	assert.ErrorIs(t, repo.Db.Instance(ctx).Exec("SELECT 1").Error, context.Canceled)
+}
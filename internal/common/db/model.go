@@ -11,6 +11,7 @@ import (
 
 type Model struct {
 	ID        string `json:"id"`
+	TenantID  string `json:"tenant_id"` // Set by the repo from the tenant of the context
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
@@ -39,6 +40,11 @@ func (m *Model) GetID() string {
 	return m.ID
 }
 
+// GetTenantID gets the tenant of entity.
+func (m *Model) GetTenantID() string {
+	return m.TenantID
+}
+
 // GetCreatedAt gets created time of entity.
 func (m *Model) GetCreatedAt() int64 {
 	return m.CreatedAt
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultReplicaCheckInterval is how often the replicas are pinged when the configuration
+// does not say.
+const DefaultReplicaCheckInterval = 5 * time.Second
+
+// IReplicaConfigReader has methods to read the read replicas of a database.
+// It is optionally implemented by an IConfigReader.
+type IReplicaConfigReader interface {
+	GetReplicas() []IConnectionReader
+	GetReplicaCheckInterval() time.Duration
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a copy of ctx whose repo reads go to the primary, to read the writes
+// just made, which the replicas may not have applied yet.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// usesPrimary reports whether the reads run with ctx must go to the primary: when asked to
+// by WithPrimary, and within a transaction, which always runs on the primary.
+func usesPrimary(ctx context.Context) bool {
+	if _, ok := ctx.Value(ContextKeyDatabase).(*gorm.DB); ok {
+		return true
+	}
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// replica is a read replica of the database, and whether it answered when last pinged.
+type replica struct {
+	name     string
+	instance *gorm.DB
+	down     atomic.Bool
+}
+
+// ReplicaDialectors if set, will skip the default initialisation of the dialectors of the
+// replicas from IReplicaConfigReader.GetReplicas().
+func ReplicaDialectors(dialectors ...gorm.Dialector) func(*DB) error {
+	return func(db *DB) error {
+		db.replicaDialectors = append(db.replicaDialectors, dialectors...)
+		return nil
+	}
+}
+
+// Replicas returns the gorm instances of the read replicas, in the configured order.
+func (db *DB) Replicas() []*gorm.DB {
+	instances := make([]*gorm.DB, 0, len(db.replicas))
+	for _, r := range db.replicas {
+		instances = append(instances, r.instance)
+	}
+	return instances
+}
+
+// CheckReplicas pings the replicas, taking those not answering out of the reads until they
+// answer again.
+func (db *DB) CheckReplicas(ctx context.Context) {
+	for _, r := range db.replicas {
+		db.checkReplica(ctx, r)
+	}
+}
+
+// checkReplica pings the replica, records whether it is up and reports it.
+func (db *DB) checkReplica(ctx context.Context, r *replica) bool {
+	err := errors.New("no connection pool")
+	if sqlDB, dbErr := r.instance.DB(); dbErr == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	wasDown := r.down.Swap(err != nil)
+	switch {
+	case err != nil && !wasDown:
+		slog.WarnContext(ctx, "read replica is down, reading from the others", "replica", r.name, "error", err)
+	case err == nil && wasDown:
+		slog.InfoContext(ctx, "read replica is up again", "replica", r.name)
+	}
+	return err == nil
+}
+
+// replicaFor returns the replica the reads run with ctx go to, taking turns between the
+// replicas that are up, or nil when they must go to the primary.
+func (db *DB) replicaFor(ctx context.Context) *replica {
+	if len(db.replicas) == 0 || usesPrimary(ctx) {
+		return nil
+	}
+	start := db.nextReplica.Add(1)
+	for i := range db.replicas {
+		r := db.replicas[(int(start)+i)%len(db.replicas)]
+		if !r.down.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// monitorReplicas checks the replicas every interval until the DB is closed.
+func (db *DB) monitorReplicas(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-db.closed:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			db.CheckReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+// initReplicaDialectors initializes the dialectors of the replicas from the configuration.
+func (db *DB) initReplicaDialectors() error {
+	replicaReader, ok := db.configReader.(IReplicaConfigReader)
+	if !ok {
+		return nil
+	}
+	for _, connReader := range replicaReader.GetReplicas() {
+		d, err := getDialector(connReader)
+		if err != nil {
+			return err
+		}
+		db.replicaDialectors = append(db.replicaDialectors, d)
+	}
+	return nil
+}
+
+// replicaCheckInterval returns how often the replicas are pinged.
+func (db *DB) replicaCheckInterval() time.Duration {
+	if replicaReader, ok := db.configReader.(IReplicaConfigReader); ok && replicaReader.GetReplicaCheckInterval() > 0 {
+		return replicaReader.GetReplicaCheckInterval()
+	}
+	return DefaultReplicaCheckInterval
+}
@@ -0,0 +1,101 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"transaction-server/internal/common/db"
+)
+
+const (
+	primaryID = "primary0000000"
+	replicaID = "replica0000000"
+)
+
+// setupReplicatedRepo returns a repo over fresh in-memory primary and replica databases,
+// which are not replicated: an item is created on the primary and another on the replica,
+// to tell where a read went.
+func setupReplicatedRepo(t *testing.T) *db.Repo {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")), db.ReplicaDialectors(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = gDb.Close() })
+	require.Len(t, gDb.Replicas(), 1)
+	replica := gDb.Replicas()[0]
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&item{}))
+	require.NoError(t, replica.AutoMigrate(&item{}))
+
+	repo := &db.Repo{Db: gDb}
+	require.NoError(t, repo.Create(context.Background(), &item{Model: db.Model{ID: primaryID}, Name: "primary"}))
+	require.NoError(t, replica.Create(&item{Model: db.Model{ID: replicaID}, Name: "replica"}).Error)
+	return repo
+}
+
+func TestRepo_ReadsGoToTheReplica(t *testing.T) {
+	repo := setupReplicatedRepo(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.FindByID(ctx, &item{}, replicaID))
+	assert.ErrorIs(t, repo.FindByID(ctx, &item{}, primaryID), gorm.ErrRecordNotFound)
+
+	var items []item
+	require.NoError(t, repo.FindManyWithFilters(ctx, &items, &db.FindManyWithConditionsRequest{}))
+	require.Len(t, items, 1)
+	assert.Equal(t, replicaID, items[0].ID)
+}
+
+func TestRepo_ReadsGoToThePrimaryWhenAsked(t *testing.T) {
+	repo := setupReplicatedRepo(t)
+	ctx := db.WithPrimary(context.Background())
+
+	assert.NoError(t, repo.FindByID(ctx, &item{}, primaryID))
+	assert.ErrorIs(t, repo.FindByID(ctx, &item{}, replicaID), gorm.ErrRecordNotFound)
+}
+
+func TestRepo_ReadsWithinATransactionGoToThePrimary(t *testing.T) {
+	repo := setupReplicatedRepo(t)
+
+	err := repo.Transaction(context.Background(), func(ctx context.Context) error {
+		var items []item
+		if err := repo.FindManyWithFilters(ctx, &items, &db.FindManyWithConditionsRequest{}); err != nil {
+			return err
+		}
+		assert.Len(t, items, 1)
+		assert.Equal(t, primaryID, items[0].ID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestRepo_ReadsFailOverToThePrimary(t *testing.T) {
+	repo := setupReplicatedRepo(t)
+	ctx := context.Background()
+	sqlDB, err := repo.Db.Replicas()[0].DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	// The failing read is run again on the primary, then the replica is left out.
+	assert.NoError(t, repo.FindByID(ctx, &item{}, primaryID))
+	assert.NoError(t, repo.FindByID(ctx, &item{}, primaryID))
+
+	repo.Db.CheckReplicas(ctx)
+	assert.NoError(t, repo.FindByID(ctx, &item{}, primaryID))
+}
+
+func TestConfig_Validate_Replicas(t *testing.T) {
+	config := db.Config{
+		ConnectionConfig: db.ConnectionConfig{Dialect: db.DialectMySQL, URL: "primary", Name: "app"},
+		Replicas:         []db.ConnectionConfig{{Dialect: db.DialectMySQL, Name: "app"}},
+	}
+
+	assert.ErrorContains(t, config.Validate(), "Replicas: (0: (URL: cannot be blank.).)")
+
+	config.Replicas[0].URL = "replica"
+	assert.NoError(t, config.Validate())
+}
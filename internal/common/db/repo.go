@@ -4,20 +4,29 @@ import (
 	"context"
 	"errors"
 	"gorm.io/gorm/clause"
+	"log/slog"
+	"reflect"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 const updatedAtField = "updated_at"
 
+// DefaultLimit is the number of records listed by a request without a limit.
+const DefaultLimit = 10
+
 // Repoer represents Repo family.
 type Repoer interface {
 	FindByID(ctx context.Context, receiver IModel, id string) error
 	FindMany(ctx context.Context, models interface{}, req FindManyRequester) error
 	FindManyWithFilters(ctx context.Context, models interface{}, req FindManyWithFiltersRequester) error
+	FindManyWithCursor(ctx context.Context, models interface{}, req FindManyWithCursorRequester) (*Page, error)
 	FindByKey(ctx context.Context, model interface{}, key string, value string) error
 	FindByConditions(ctx context.Context, model interface{}, conditions []clause.Expression) error
 	Create(ctx context.Context, receiver IModel) error
+	CreateIfAbsent(ctx context.Context, receiver IModel) error
+	CreateInBatches(ctx context.Context, receivers interface{}, batchSize int) error
 	CreateWithAssociations(ctx context.Context, receiver IModel) error
 	SaveWithAssociations(ctx context.Context, receiver IModel) error
 	Update(ctx context.Context, receiver IModel, selectiveList ...string) error
@@ -35,19 +44,37 @@ func NewRepo(db *DB) Repoer {
 	return &Repo{Db: db}
 }
 
-// DBInstance returns gorm instance.
-// If replicas are specified, for Query, Row callback, will use replicas, unless Write mode specified.
-// For Raw callback, statements are considered read-only and will use replicas if the SQL starts with SELECT.
+// DBInstance returns gorm instance, of the primary or of the transaction of the context.
+// Statements run with the context, for its logger, trace and deadline, and are scoped to
+// the tenant of the context, if any.
 func (r *Repo) DBInstance(ctx context.Context) *gorm.DB {
-	return r.Db.Instance(ctx)
+	return withTenant(ctx, r.Db.Instance(ctx))
+}
+
+// read runs query on a read replica, or on the primary when there is none up, when ctx
+// asks for it with WithPrimary or when ctx is within a transaction. A query failing on a
+// replica which then does not answer a ping is run again on the primary.
+func (r *Repo) read(ctx context.Context, query func(*gorm.DB) error) error {
+	replica := r.Db.replicaFor(ctx)
+	if replica == nil {
+		return query(r.DBInstance(ctx))
+	}
+	err := query(withTenant(ctx, replica.instance.WithContext(ctx)))
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+	if r.Db.checkReplica(ctx, replica) {
+		return err
+	}
+	return query(r.DBInstance(ctx))
 }
 
 // FindByID fetches the record which matches the ID provided from the entity defined by receiver
-// and the result will be loaded into receiver
+// and the result will be loaded into receiver. It reads from a replica, see read.
 func (r *Repo) FindByID(ctx context.Context, receiver IModel, id string) error {
-	q := r.DBInstance(ctx).Where("id = ?", id).First(receiver)
-
-	return q.Error
+	return r.read(ctx, func(q *gorm.DB) error {
+		return q.Where("id = ?", id).First(receiver).Error
+	})
 }
 
 // Create inserts a new record in the entity defined by the receiver
@@ -70,6 +97,26 @@ func (r *Repo) Create(ctx context.Context, receiver IModel) error {
 	return q.Error
 }
 
+// CreateIfAbsent inserts the receiver like Create, unless a record with the same primary
+// or unique key exists, in which case nothing is inserted and no error returned.
+func (r *Repo) CreateIfAbsent(ctx context.Context, receiver IModel) error {
+	if err := receiver.BeforeCreate(r.DBInstance(ctx)); err != nil {
+		return err
+	}
+
+	if err := receiver.SetDefaults(); err != nil {
+		return err
+	}
+
+	if err := receiver.Validate(); err != nil {
+		return err
+	}
+
+	q := r.DBInstance(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(receiver)
+
+	return q.Error
+}
+
 // CreateInBatches insert the value in batches into database
 func (r *Repo) CreateInBatches(ctx context.Context, receivers interface{}, batchSize int) error {
 	q := r.DBInstance(ctx).CreateInBatches(receivers, batchSize)
@@ -123,21 +170,86 @@ func (r *Repo) SaveWithAssociations(ctx context.Context, receiver IModel) error
 	return q.Error
 }
 
-// FindMany builds query per request and loads multiple into models.
+// FindMany builds query per request and loads multiple into models. It reads from a replica.
 func (r *Repo) FindMany(ctx context.Context, models interface{}, req FindManyRequester) error {
-	q := r.ApplyListRequest(req, r.DBInstance(ctx))
+	return r.read(ctx, func(q *gorm.DB) error {
+		q = r.ApplyListRequest(req, q)
 
-	q = q.Order("created_at DESC").Order("name desc")
+		q = q.Order("created_at DESC").Order("name desc")
 
-	return q.Find(models).Error
+		return q.Find(models).Error
+	})
 }
 
 // FindManyWithFilters builds query per request with filters and loads multiple into models.
+// It reads from a replica.
 func (r *Repo) FindManyWithFilters(ctx context.Context, models interface{}, req FindManyWithFiltersRequester) error {
-	q := r.ApplyListRequest(req, r.DBInstance(ctx))
-	q = q.Clauses(req.GetConditions()...).Order("created_at DESC")
+	return r.read(ctx, func(q *gorm.DB) error {
+		q = r.ApplyListRequest(req, q)
+		q = q.Clauses(req.GetConditions()...)
+		if orderBy := req.GetOrderBy(); len(orderBy.Columns) > 0 {
+			q = q.Clauses(orderBy)
+		} else {
+			q = q.Order("created_at DESC")
+		}
+
+		return q.Find(models).Error
+	})
+}
+
+// FindManyWithCursor loads the page after (or, for backward cursors, before) the request cursor
+// into models, ordered by (created_at, id), and returns the cursors of the adjacent pages.
+// models must be a pointer to a slice of structs implementing CursorModel. It reads from a replica.
+func (r *Repo) FindManyWithCursor(ctx context.Context, models interface{}, req FindManyWithCursorRequester) (*Page, error) {
+	cursor, err := DecodeCursor(req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	backward := cursor != nil && cursor.Backward
+	// A backward page is scanned in the reverse order and flipped once loaded.
+	desc := !req.IsAscending() != backward
+
+	err = r.read(ctx, func(q *gorm.DB) error {
+		q = q.Clauses(req.GetConditions()...)
+		if cursor != nil {
+			q = q.Where(keysetCondition(cursor, desc))
+		}
+		q = q.Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: desc}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).
+			Limit(limit + 1)
+		return q.Find(models).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slice := reflect.ValueOf(models).Elem()
+	hasMore := slice.Len() > limit
+	if hasMore {
+		slice.Set(slice.Slice(0, limit))
+	}
+	if backward {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
 
-	return q.Find(models).Error
+	page := &Page{}
+	if slice.Len() == 0 {
+		return page, nil
+	}
+	if (!backward && hasMore) || backward {
+		page.NextCursor = cursorAt(slice, slice.Len()-1, false)
+	}
+	if (!backward && cursor != nil) || (backward && hasMore) {
+		page.PrevCursor = cursorAt(slice, 0, true)
+	}
+	return page, nil
 }
 
 // FindByKey builds query By the key and the value.
@@ -183,9 +295,39 @@ func (r *Repo) AssociationDelete(ctx context.Context, model interface{}, associa
 	return nil
 }
 
-// Transaction will manage the execution inside a transactions
+// Transaction will manage the execution inside a transactions, on the primary
 // adds the txn db in the context for downstream use case
+//
+// A transaction failing on a deadlock or a serialization failure is rolled back and fc is
+// run again, in a new transaction, up to TransactionRetryConfig.MaxRetries times, unless
+// ctx is WithoutRetry. fc must then leave the state outside of the database as it found it
+// when failing, or restore it when run again.
 func (r *Repo) Transaction(ctx context.Context, fc func(ctx context.Context) error) error {
+	retry := r.Db.transactionRetry(ctx)
+	for attempt := 1; ; attempt++ {
+		err := r.transaction(ctx, fc)
+		reason, retryable := r.Db.retryReason(err)
+		if !retryable || attempt > retry.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+		backoff := retry.backoff(attempt)
+		slog.WarnContext(ctx, "retrying database transaction", "reason", reason, "attempt", attempt,
+			"backoff", backoff, "error", err)
+		if r.Db.onRetry != nil {
+			r.Db.onRetry(reason)
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// transaction runs fc once in a transaction.
+func (r *Repo) transaction(ctx context.Context, fc func(ctx context.Context) error) error {
 	var err = r.DBInstance(ctx).Transaction(func(tx *gorm.DB) error {
 
 		// This will ensure that when db.Instance(context) we return the txn on the context
@@ -196,6 +338,9 @@ func (r *Repo) Transaction(ctx context.Context, fc func(ctx context.Context) err
 
 		return tx.Error
 	})
+	if err != nil {
+		slog.WarnContext(ctx, "database transaction rolled back", "error", err)
+	}
 
 	return err
 }
@@ -205,7 +350,7 @@ func (r *Repo) ApplyListRequest(req FindManyRequester, q *gorm.DB) *gorm.DB {
 	if req.GetLimit() != 0 {
 		q = q.Limit(int(req.GetLimit()))
 	} else {
-		q = q.Limit(10)
+		q = q.Limit(DefaultLimit)
 	}
 	if req.GetOffset() != 0 {
 		q = q.Offset(int(req.GetOffset()))
@@ -242,15 +387,23 @@ func (r FindManyRequest) GetOffset() uint32 {
 type FindManyWithFiltersRequester interface {
 	FindManyRequester
 	GetConditions() []clause.Expression
+	GetOrderBy() clause.OrderBy
 }
 
 // FindManyWithConditionsRequest is a request to find many of a model type.
 type FindManyWithConditionsRequest struct {
 	FindManyRequest
 	Conditions []clause.Expression
+	// OrderBy overrides the default `created_at DESC` ordering when it has columns.
+	OrderBy clause.OrderBy
 }
 
 // GetConditions returns list of additional filters on entity.
 func (r FindManyWithConditionsRequest) GetConditions() []clause.Expression {
 	return r.Conditions
 }
+
+// GetOrderBy returns the ordering of the results.
+func (r FindManyWithConditionsRequest) GetOrderBy() clause.OrderBy {
+	return r.OrderBy
+}
@@ -0,0 +1,133 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/db"
+)
+
+type item struct {
+	db.Model
+	Name string
+}
+
+func (i *item) TableName() string {
+	return "items"
+}
+
+func (i *item) EntityName() string {
+	return "item"
+}
+
+func (i *item) SetDefaults() error {
+	return nil
+}
+
+// setupRepo returns a repo over a fresh in-memory database.
+func setupRepo(t *testing.T) *db.Repo {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&item{}))
+	return &db.Repo{Db: gDb}
+}
+
+// seedItems creates items with ids "item0000000000".."item0000000009", two per created_at second.
+func seedItems(t *testing.T, repo *db.Repo) {
+	for i := 0; i < 10; i++ {
+		model := &item{Model: db.Model{ID: fmt.Sprintf("item%010d", i), CreatedAt: int64(1700000000 + i/2)}, Name: "item"}
+		require.NoError(t, repo.Create(context.Background(), model))
+	}
+}
+
+func ids(items []item) []string {
+	result := make([]string, 0, len(items))
+	for _, i := range items {
+		result = append(result, i.ID[len(i.ID)-1:])
+	}
+	return result
+}
+
+func TestRepo_FindManyWithCursor_PagesForwardAndBackward(t *testing.T) {
+	repo := setupRepo(t)
+	seedItems(t, repo)
+	ctx := context.Background()
+
+	var first []item
+	page, err := repo.FindManyWithCursor(ctx, &first, db.FindManyWithCursorRequest{Limit: 4})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"9", "8", "7", "6"}, ids(first))
+	assert.Empty(t, page.PrevCursor)
+	require.NotEmpty(t, page.NextCursor)
+
+	var second []item
+	page, err = repo.FindManyWithCursor(ctx, &second, db.FindManyWithCursorRequest{Limit: 4, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "4", "3", "2"}, ids(second))
+	require.NotEmpty(t, page.PrevCursor)
+	require.NotEmpty(t, page.NextCursor)
+	next := page.NextCursor
+
+	var previous []item
+	prevPage, err := repo.FindManyWithCursor(ctx, &previous, db.FindManyWithCursorRequest{Limit: 4, Cursor: page.PrevCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"9", "8", "7", "6"}, ids(previous))
+	assert.Empty(t, prevPage.PrevCursor)
+	assert.NotEmpty(t, prevPage.NextCursor)
+
+	var last []item
+	page, err = repo.FindManyWithCursor(ctx, &last, db.FindManyWithCursorRequest{Limit: 4, Cursor: next})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "0"}, ids(last))
+	assert.Empty(t, page.NextCursor)
+	assert.NotEmpty(t, page.PrevCursor)
+}
+
+func TestRepo_FindManyWithCursor_IsStableWhileInserting(t *testing.T) {
+	repo := setupRepo(t)
+	seedItems(t, repo)
+	ctx := context.Background()
+
+	var first []item
+	page, err := repo.FindManyWithCursor(ctx, &first, db.FindManyWithCursorRequest{Limit: 5, Ascending: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, ids(first))
+
+	// A row inserted before the cursor position must not shift the next page.
+	require.NoError(t, repo.Create(ctx, &item{Model: db.Model{ID: "itemx000000000", CreatedAt: 1699999999}}))
+
+	var second []item
+	page, err = repo.FindManyWithCursor(ctx, &second, db.FindManyWithCursorRequest{Limit: 5, Ascending: true, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "6", "7", "8", "9"}, ids(second))
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestRepo_FindManyWithCursor_InvalidCursor(t *testing.T) {
+	repo := setupRepo(t)
+
+	var models []item
+	_, err := repo.FindManyWithCursor(context.Background(), &models, db.FindManyWithCursorRequest{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, db.ErrInvalidCursor)
+}
+
+func TestRepo_CreateIfAbsent_KeepsTheExistingRecord(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &item{Model: db.Model{ID: "item0000000000"}, Name: "first"}))
+
+	require.NoError(t, repo.CreateIfAbsent(ctx, &item{Model: db.Model{ID: "item0000000000"}, Name: "second"}))
+	require.NoError(t, repo.CreateIfAbsent(ctx, &item{Model: db.Model{ID: "item0000000001"}, Name: "third"}))
+
+	var stored item
+	require.NoError(t, repo.FindByID(ctx, &stored, "item0000000000"))
+	assert.Equal(t, "first", stored.Name)
+	assert.NoError(t, repo.FindByID(ctx, &item{}, "item0000000001"))
+	assert.ErrorContains(t, repo.CreateIfAbsent(ctx, &item{Model: db.Model{ID: "invalid"}}), "id")
+}
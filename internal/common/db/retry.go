@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	// RetryReasonDeadlock is the reason of the retries of a transaction chosen as the victim
+	// of a deadlock.
+	RetryReasonDeadlock = "deadlock"
+	// RetryReasonSerializationFailure is the reason of the retries of a transaction which
+	// could not be serialized with concurrent ones.
+	RetryReasonSerializationFailure = "serialization_failure"
+)
+
+const (
+	mysqlErrDeadlock                = 1213
+	postgresErrSerializationFailure = "40001"
+	postgresErrDeadlockDetected     = "40P01"
+)
+
+// retryReasons classify the errors of each dialect on which a transaction is worth running
+// again, by the name of the dialector.
+var retryReasons = map[string]func(err error) (string, bool){
+	DialectMySQL: func(err error) (string, bool) {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDeadlock {
+			return RetryReasonDeadlock, true
+		}
+		return "", false
+	},
+	DialectPostgres: func(err error) (string, bool) {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return "", false
+		}
+		switch pgErr.Code {
+		case postgresErrSerializationFailure:
+			return RetryReasonSerializationFailure, true
+		case postgresErrDeadlockDetected:
+			return RetryReasonDeadlock, true
+		}
+		return "", false
+	},
+}
+
+// TransactionRetryConfig sets how the transactions failing on a deadlock or a serialization
+// failure are run again.
+type TransactionRetryConfig struct {
+	// MaxRetries is how many times a transaction is run again; 0 disables the retries.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the wait before a retry, which doubles with each
+	// retry and is jittered over [0, backoff).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// ITransactionRetryConfigReader has methods to read how transactions are retried.
+// It is optionally implemented by an IConfigReader.
+type ITransactionRetryConfigReader interface {
+	GetTransactionRetryConfig() TransactionRetryConfig
+}
+
+// backoff returns the wait before the retry, the first being 1.
+func (c TransactionRetryConfig) backoff(retry int) time.Duration {
+	backoff := c.MaxBackoff
+	if retry < 32 && c.MinBackoff<<(retry-1) < c.MaxBackoff {
+		backoff = c.MinBackoff << (retry - 1)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a copy of ctx whose transactions are not retried, for those having
+// effects outside the database which must not be repeated.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// OnTransactionRetry if set, is called with the reason of each retry of a transaction.
+func OnTransactionRetry(observe func(reason string)) func(*DB) error {
+	return func(db *DB) error {
+		db.onRetry = observe
+		return nil
+	}
+}
+
+// retryReason returns why the transaction which failed with err is worth running again, if
+// it is.
+func (db *DB) retryReason(err error) (string, bool) {
+	if err == nil || db.dialector == nil {
+		return "", false
+	}
+	if classify, ok := retryReasons[db.dialector.Name()]; ok {
+		return classify(err)
+	}
+	return "", false
+}
+
+// transactionRetry returns how transactions are retried.
+func (db *DB) transactionRetry(ctx context.Context) TransactionRetryConfig {
+	if _, ok := ctx.Value(ContextKeyDatabase).(*gorm.DB); ok {
+		// A nested transaction is retried by the outermost one.
+		return TransactionRetryConfig{}
+	}
+	if noRetry, _ := ctx.Value(noRetryKey{}).(bool); noRetry {
+		return TransactionRetryConfig{}
+	}
+	if retryReader, ok := db.configReader.(ITransactionRetryConfigReader); ok {
+		return retryReader.GetTransactionRetryConfig()
+	}
+	return TransactionRetryConfig{}
+}
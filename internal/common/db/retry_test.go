@@ -0,0 +1,179 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"transaction-server/internal/common/db"
+)
+
+var (
+	errDeadlock             = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	errDuplicate            = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+	errSerializationFailure = &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+)
+
+// faultDialector is an in-memory SQLite dialector passing for the dialect name, whose
+// inserts fail with the queued faults, one each, before succeeding again.
+type faultDialector struct {
+	gorm.Dialector
+	name   string
+	faults []error
+}
+
+func (d *faultDialector) Name() string {
+	return d.name
+}
+
+func (d *faultDialector) Initialize(instance *gorm.DB) error {
+	if err := d.Dialector.Initialize(instance); err != nil {
+		return err
+	}
+	return instance.Callback().Create().Before("gorm:create").Register("test:fault", func(tx *gorm.DB) {
+		if len(d.faults) > 0 {
+			_ = tx.AddError(d.faults[0])
+			d.faults = d.faults[1:]
+		}
+	})
+}
+
+// setupFaultyRepo returns a repo over the dialector, retrying transactions up to maxRetries
+// times, and the reasons of its retries.
+func setupFaultyRepo(t *testing.T, dialector *faultDialector, maxRetries int) (*db.Repo, *[]string) {
+	dialector.Dialector = sqlite.Open("file::memory:")
+	reasons := make([]string, 0)
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+		TransactionRetry:     db.TransactionRetryConfig{MaxRetries: maxRetries, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	}, db.Dialector(dialector), db.OnTransactionRetry(func(reason string) {
+		reasons = append(reasons, reason)
+	}))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&item{}))
+	return &db.Repo{Db: gDb}, &reasons
+}
+
+// createItem creates an item in a transaction and returns how many times it was run.
+func createItem(ctx context.Context, repo *db.Repo) (int, error) {
+	runs := 0
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		runs++
+		return repo.Create(ctx, &item{Model: db.Model{ID: "item0000000000"}, Name: "item"})
+	})
+	return runs, err
+}
+
+func TestRepo_Transaction_Retries(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     string
+		faults      []error
+		maxRetries  int
+		wantRuns    int
+		wantErr     error
+		wantReasons []string
+	}{
+		{
+			name:        "mysql deadlocks",
+			dialect:     db.DialectMySQL,
+			faults:      []error{errDeadlock, errDeadlock},
+			maxRetries:  3,
+			wantRuns:    3,
+			wantReasons: []string{db.RetryReasonDeadlock, db.RetryReasonDeadlock},
+		},
+		{
+			name:        "postgres serialization failure",
+			dialect:     db.DialectPostgres,
+			faults:      []error{errSerializationFailure},
+			maxRetries:  3,
+			wantRuns:    2,
+			wantReasons: []string{db.RetryReasonSerializationFailure},
+		},
+		{
+			name:        "retries exhausted",
+			dialect:     db.DialectMySQL,
+			faults:      []error{errDeadlock, errDeadlock, errDeadlock},
+			maxRetries:  2,
+			wantRuns:    3,
+			wantErr:     errDeadlock,
+			wantReasons: []string{db.RetryReasonDeadlock, db.RetryReasonDeadlock},
+		},
+		{
+			name:        "retries disabled",
+			dialect:     db.DialectMySQL,
+			faults:      []error{errDeadlock},
+			wantRuns:    1,
+			wantErr:     errDeadlock,
+			wantReasons: []string{},
+		},
+		{
+			name:        "not retryable",
+			dialect:     db.DialectMySQL,
+			faults:      []error{errDuplicate},
+			maxRetries:  3,
+			wantRuns:    1,
+			wantErr:     errDuplicate,
+			wantReasons: []string{},
+		},
+		{
+			name:        "error of another dialect",
+			dialect:     db.DialectMySQL,
+			faults:      []error{errSerializationFailure},
+			maxRetries:  3,
+			wantRuns:    1,
+			wantErr:     errSerializationFailure,
+			wantReasons: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, reasons := setupFaultyRepo(t, &faultDialector{name: tt.dialect, faults: tt.faults}, tt.maxRetries)
+
+			runs, err := createItem(context.Background(), repo)
+
+			assert.Equal(t, tt.wantRuns, runs)
+			assert.Equal(t, tt.wantReasons, *reasons)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var count int64
+			require.NoError(t, repo.DBInstance(context.Background()).Model(&item{}).Count(&count).Error)
+			assert.EqualValues(t, 1, count)
+		})
+	}
+}
+
+func TestRepo_Transaction_WithoutRetry(t *testing.T) {
+	repo, reasons := setupFaultyRepo(t, &faultDialector{name: db.DialectMySQL, faults: []error{errDeadlock}}, 3)
+
+	runs, err := createItem(db.WithoutRetry(context.Background()), repo)
+
+	assert.ErrorIs(t, err, errDeadlock)
+	assert.Equal(t, 1, runs)
+	assert.Empty(t, *reasons)
+}
+
+func TestRepo_Transaction_StopsRetryingWhenTheContextIsDone(t *testing.T) {
+	repo, _ := setupFaultyRepo(t, &faultDialector{name: db.DialectMySQL, faults: []error{errDeadlock, errDeadlock}}, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	err := repo.Transaction(ctx, func(txCtx context.Context) error {
+		runs++
+		cancel()
+		return repo.Create(txCtx, &item{Model: db.Model{ID: "item0000000000"}, Name: "item"})
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, runs)
+}
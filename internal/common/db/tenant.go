@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// TenantColumn is the column holding the tenant of a row.
+	TenantColumn = "tenant_id"
+	// DefaultTenant is the tenant of rows created without a tenant on the context.
+	DefaultTenant = "default"
+
+	tenantField   = "TenantID"
+	tenantSetting = "db:tenant_id"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx scoping the repo queries run with it to the tenant.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant the queries run with ctx are scoped to, if any.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// withTenant sets the tenant of ctx on the gorm instance for tenantScope to apply.
+func withTenant(ctx context.Context, instance *gorm.DB) *gorm.DB {
+	if tenantID, ok := TenantFromContext(ctx); ok {
+		return instance.Set(tenantSetting, tenantID)
+	}
+	return instance
+}
+
+// tenantScope is a gorm plugin confining the statements of a tenant to its rows: the rows
+// it creates are stamped with the tenant, and the rows it reads, updates and deletes are
+// filtered by it. Statements without a tenant, run by background workers and commands,
+// see every tenant. Raw SQL is not scoped.
+type tenantScope struct{}
+
+func (tenantScope) Name() string {
+	return "tenant_scope"
+}
+
+func (s tenantScope) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", s.stamp); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", s.filter); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", s.filter); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", s.filterTargeted); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", s.filterTargeted)
+}
+
+// stamp sets the tenant of the rows being created, overriding any other tenant set on them.
+func (tenantScope) stamp(db *gorm.DB) {
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField(tenantField) == nil {
+		return
+	}
+	tenantID, ok := db.Get(tenantSetting)
+	if !ok {
+		// Rows created without a tenant, as by commands, go to the default tenant.
+		if !isZeroTenant(db) {
+			return
+		}
+		tenantID = DefaultTenant
+	}
+	db.Statement.SetColumn(tenantField, tenantID, true)
+}
+
+func (tenantScope) filter(db *gorm.DB) {
+	tenantID, ok := db.Get(tenantSetting)
+	if !ok || db.Statement.Schema == nil || db.Statement.Schema.LookUpField(tenantField) == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: TenantColumn}, Value: tenantID},
+	}})
+}
+
+// filterTargeted filters updates and deletes that target rows. Those without conditions
+// are left for gorm to reject, rather than turned into updates of the whole tenant.
+func (s tenantScope) filterTargeted(db *gorm.DB) {
+	if !hasConditions(db.Statement) {
+		return
+	}
+	s.filter(db)
+}
+
+func hasConditions(stmt *gorm.Statement) bool {
+	if _, ok := stmt.Clauses["WHERE"]; ok {
+		return true
+	}
+	if stmt.Schema == nil {
+		return false
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		return stmt.ReflectValue.Len() > 0
+	case reflect.Struct:
+		for _, field := range stmt.Schema.PrimaryFields {
+			if _, isZero := field.ValueOf(stmt.Context, stmt.ReflectValue); !isZero {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isZeroTenant reports whether the rows being created have no tenant.
+func isZeroTenant(db *gorm.DB) bool {
+	field := db.Statement.Schema.LookUpField(tenantField)
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			if _, isZero := field.ValueOf(db.Statement.Context, db.Statement.ReflectValue.Index(i)); !isZero {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		_, isZero := field.ValueOf(db.Statement.Context, db.Statement.ReflectValue)
+		return isZero
+	}
+	return false
+}
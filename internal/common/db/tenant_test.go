@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"transaction-server/internal/common/db"
+)
+
+func TestRepo_Tenant_Isolation(t *testing.T) {
+	repo := setupRepo(t)
+	acme := db.WithTenant(context.Background(), "acme")
+	globex := db.WithTenant(context.Background(), "globex")
+
+	owned := &item{Model: db.Model{ID: "item0000000001"}, Name: "acme"}
+	require.NoError(t, repo.Create(acme, owned))
+	assert.Equal(t, "acme", owned.TenantID)
+	// The tenant of the context wins over the one set on the row.
+	forged := &item{Model: db.Model{ID: "item0000000002", TenantID: "acme"}, Name: "globex"}
+	require.NoError(t, repo.Create(globex, forged))
+	assert.Equal(t, "globex", forged.TenantID)
+
+	t.Run("reads", func(t *testing.T) {
+		assert.ErrorIs(t, repo.FindByID(globex, &item{}, "item0000000001"), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.FindByConditions(globex, &item{}, []clause.Expression{clause.Eq{Column: "name", Value: "acme"}}), gorm.ErrRecordNotFound)
+
+		var items []item
+		require.NoError(t, repo.FindManyWithFilters(acme, &items, &db.FindManyWithConditionsRequest{}))
+		assert.Equal(t, []string{"1"}, ids(items))
+		page, err := repo.FindManyWithCursor(globex, &items, db.FindManyWithCursorRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.NotNil(t, page)
+		assert.Equal(t, []string{"2"}, ids(items))
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		target := &item{Model: db.Model{ID: "item0000000001"}, Name: "hijacked"}
+		require.NoError(t, repo.Update(globex, target, "name"))
+		require.NoError(t, repo.Delete(globex, &item{Model: db.Model{ID: "item0000000001"}}))
+
+		found := &item{}
+		require.NoError(t, repo.FindByID(acme, found, "item0000000001"))
+		assert.Equal(t, "acme", found.Name)
+	})
+
+	t.Run("transactions", func(t *testing.T) {
+		err := repo.Transaction(globex, func(ctx context.Context) error {
+			return repo.FindByID(ctx, &item{}, "item0000000001")
+		})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("unscoped", func(t *testing.T) {
+		var items []item
+		require.NoError(t, repo.FindManyWithFilters(context.Background(), &items, &db.FindManyWithConditionsRequest{}))
+		assert.Len(t, items, 2)
+	})
+}
+
+func TestRepo_Tenant_Defaults_Unscoped_Creates(t *testing.T) {
+	repo := setupRepo(t)
+	model := &item{Name: "imported"}
+	require.NoError(t, repo.Create(context.Background(), model))
+	assert.Equal(t, db.DefaultTenant, model.TenantID)
+	require.NoError(t, repo.FindByID(db.WithTenant(context.Background(), db.DefaultTenant), &item{}, model.ID))
+}
+
+func TestRepo_Tenant_Keeps_Global_Update_Guard(t *testing.T) {
+	repo := setupRepo(t)
+	err := repo.Update(db.WithTenant(context.Background(), "acme"), &item{Name: "all"}, "name")
+	assert.ErrorIs(t, err, gorm.ErrMissingWhereClause)
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Level is the minimum level logged: "debug", "info", "warn" or "error".
+	Level string
+	// Format is "json", or "text" for local development.
+	Format string
+	// SlowQuery is the duration above which a SQL query is logged as slow; 0 turns it off.
+	SlowQuery time.Duration
+}
+
+// Validate validates the level and format.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Level, validation.By(isLevel)),
+		validation.Field(&c.Format, validation.In(FormatJSON, FormatText)),
+		validation.Field(&c.SlowQuery, validation.Min(time.Duration(0))),
+	)
+}
+
+func isLevel(value interface{}) error {
+	name, _ := value.(string)
+	var level slog.Level
+	if name != "" && level.UnmarshalText([]byte(strings.ToUpper(name))) != nil {
+		return errors.New("must be debug, info, warn or error")
+	}
+	return nil
+}
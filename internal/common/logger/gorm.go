@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// GormLogger logs the SQL queries of gorm, with the request ID of their context. Failed
+// queries are logged at the error level, slow ones at the warn level and, in the info
+// mode of gorm (db.debug), every query at the info level.
+type GormLogger struct {
+	log       *slog.Logger
+	level     gormLogger.LogLevel
+	slowQuery time.Duration
+}
+
+func NewGormLogger(log *slog.Logger, slowQuery time.Duration) *GormLogger {
+	return &GormLogger{log: log, level: gormLogger.Warn, slowQuery: slowQuery}
+}
+
+func (l *GormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= gormLogger.Info {
+		l.log.InfoContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= gormLogger.Warn {
+		l.log.WarnContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if l.level >= gormLogger.Error {
+		l.log.ErrorContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	level, message := slog.LevelInfo, "query"
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormLogger.Error:
+		level, message = slog.LevelError, "query failed"
+	case l.slowQuery > 0 && elapsed > l.slowQuery && l.level >= gormLogger.Warn:
+		level, message = slog.LevelWarn, "slow query"
+	case l.level < gormLogger.Info:
+		return
+	}
+	if !l.log.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", milliseconds(elapsed)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.log.LogAttrs(ctx, level, message, attrs...)
+}
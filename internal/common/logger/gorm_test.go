@@ -0,0 +1,64 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"transaction-server/internal/common/logger"
+)
+
+type row struct {
+	ID   int
+	Name string
+}
+
+func setupDB(t *testing.T, out *bytes.Buffer, level gormLogger.LogLevel, slowQuery time.Duration) *gorm.DB {
+	log := logger.New(logger.Config{Format: logger.FormatJSON}, out)
+	gDb, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.NewGormLogger(log, slowQuery).LogMode(level),
+	})
+	require.NoError(t, err)
+	require.NoError(t, gDb.AutoMigrate(&row{}))
+	out.Reset()
+	return gDb
+}
+
+func TestGormLogger_Logs_Queries_With_Request_ID(t *testing.T) {
+	out := &bytes.Buffer{}
+	gDb := setupDB(t, out, gormLogger.Info, 0)
+	ctx := logger.WithRequestID(context.Background(), "req-42")
+
+	require.NoError(t, gDb.WithContext(ctx).Create(&row{Name: "a"}).Error)
+
+	logged := records(t, out)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "query", logged[0]["msg"])
+	assert.Equal(t, "req-42", logged[0][logger.KeyRequestID])
+	assert.Contains(t, logged[0]["sql"], "INSERT INTO `rows`")
+	assert.Equal(t, float64(1), logged[0]["rows"])
+	assert.Contains(t, logged[0], "duration_ms")
+}
+
+func TestGormLogger_Warn_Mode(t *testing.T) {
+	out := &bytes.Buffer{}
+	gDb := setupDB(t, out, gormLogger.Warn, time.Hour)
+	ctx := logger.WithRequestID(context.Background(), "req-42")
+
+	// Fast queries and missing records are not logged, failed queries are.
+	require.NoError(t, gDb.WithContext(ctx).Create(&row{Name: "a"}).Error)
+	assert.ErrorIs(t, gDb.WithContext(ctx).First(&row{}, 42).Error, gorm.ErrRecordNotFound)
+	assert.Error(t, gDb.WithContext(ctx).Exec("SELECT * FROM missing").Error)
+
+	logged := records(t, out)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "query failed", logged[0]["msg"])
+	assert.Equal(t, "ERROR", logged[0]["level"])
+	assert.Contains(t, logged[0]["error"], "missing")
+}
@@ -0,0 +1,33 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"transaction-server/internal/common/logger"
+)
+
+func TestNew_Adds_Context(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := logger.New(logger.Config{Level: "warn"}, out)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = logger.WithRequestID(ctx, "req-42")
+
+	log.InfoContext(ctx, "below the level")
+	log.With("component", "relay").WarnContext(ctx, "logged")
+
+	logged := records(t, out)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "logged", logged[0]["msg"])
+	assert.Equal(t, "relay", logged[0]["component"])
+	assert.Equal(t, "req-42", logged[0][logger.KeyRequestID])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", logged[0][logger.KeyTraceID])
+	assert.Equal(t, "00f067aa0ba902b7", logged[0][logger.KeySpanID])
+}
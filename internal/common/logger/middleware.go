@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID is the header carrying the ID of a request, from the client or the proxy
+// in front of the API, and back in the response.
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern is what a request ID received must look like to be kept, so the IDs
+// logged are safe to log.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRequestID returns the ID of a request, keeping a valid one received.
+func NewRequestID(received string) string {
+	if requestIDPattern.MatchString(received) {
+		return received
+	}
+	return uuid.NewString()
+}
+
+// Middleware assigns each request its ID, puts it on the request context and the response,
+// and logs the request once served. It replaces the default logger of gin.
+func Middleware(log *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		requestID := NewRequestID(ctx.GetHeader(HeaderRequestID))
+		ctx.Request = ctx.Request.WithContext(WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(HeaderRequestID, requestID)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("route", ctx.FullPath()),
+			slog.Int("status", status),
+			slog.Int("bytes", ctx.Writer.Size()),
+			slog.Float64("duration_ms", milliseconds(time.Since(start))),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if errs := ctx.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			attrs = append(attrs, slog.String("error", errs.String()))
+		}
+		log.LogAttrs(ctx.Request.Context(), level, "request served", attrs...)
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
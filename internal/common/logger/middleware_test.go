@@ -0,0 +1,72 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/logger"
+)
+
+func setupRouter(out *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(logger.Config{Format: logger.FormatJSON}, out)
+	router := gin.New()
+	router.Use(logger.Middleware(log))
+	router.GET("/accounts/:accountId", func(ctx *gin.Context) {
+		log.InfoContext(ctx.Request.Context(), "handling")
+		ctx.Status(http.StatusNotFound)
+	})
+	return router
+}
+
+// records decodes the JSON records logged.
+func records(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var logged []map[string]interface{}
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var record map[string]interface{}
+		require.NoError(t, decoder.Decode(&record))
+		logged = append(logged, record)
+	}
+	return logged
+}
+
+func TestMiddleware_Propagates_Request_ID(t *testing.T) {
+	out := &bytes.Buffer{}
+	req := httptest.NewRequest(http.MethodGet, "/accounts/0b0e0000000000", nil)
+	req.Header.Set(logger.HeaderRequestID, "req-42")
+	recorder := httptest.NewRecorder()
+	setupRouter(out).ServeHTTP(recorder, req)
+
+	assert.Equal(t, "req-42", recorder.Header().Get(logger.HeaderRequestID))
+	logged := records(t, out)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "handling", logged[0]["msg"])
+	assert.Equal(t, "req-42", logged[0][logger.KeyRequestID])
+	assert.Equal(t, "request served", logged[1]["msg"])
+	assert.Equal(t, "req-42", logged[1][logger.KeyRequestID])
+	assert.Equal(t, slog.LevelWarn.String(), logged[1]["level"])
+	assert.Equal(t, "/accounts/:accountId", logged[1]["route"])
+	assert.Equal(t, float64(http.StatusNotFound), logged[1]["status"])
+}
+
+func TestMiddleware_Assigns_Request_ID(t *testing.T) {
+	for _, received := range []string{"", "bad id\n"} {
+		out := &bytes.Buffer{}
+		req := httptest.NewRequest(http.MethodGet, "/accounts/0b0e0000000000", nil)
+		req.Header.Set(logger.HeaderRequestID, received)
+		recorder := httptest.NewRecorder()
+		setupRouter(out).ServeHTTP(recorder, req)
+
+		requestID := recorder.Header().Get(logger.HeaderRequestID)
+		assert.Len(t, requestID, 36)
+		assert.Equal(t, requestID, records(t, out)[0][logger.KeyRequestID])
+	}
+}
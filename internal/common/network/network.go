@@ -0,0 +1,38 @@
+// Package network guards the outbound connections made to addresses given by clients, such
+// as the webhook deliveries, from reaching the internal network of the server.
+package network
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), internal as the private ranges.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublic reports whether ip is a public unicast address: not loopback, link-local, private,
+// shared, multicast or unspecified.
+func IsPublic(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsPrivate() &&
+		!sharedAddressSpace.Contains(ip) &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!ip.Equal(net.IPv4bcast)
+}
+
+// DialControl is the Control of a net.Dialer refusing to connect to addresses that are not
+// public. It checks the address dialed, once resolved, so a hostname resolving to an internal
+// address, even after it was checked, is refused as well.
+func DialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublic(ip) {
+		return fmt.Errorf("connecting to %s is not allowed: not a public address", host)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package network_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/common/network"
+)
+
+func TestIsPublic(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:2800::1":    true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"224.0.0.1":       false,
+		"ff02::1":         false,
+		"0.0.0.0":         false,
+		"::":              false,
+		"255.255.255.255": false,
+		"::ffff:10.0.0.1": false,
+	} {
+		assert.Equal(t, public, network.IsPublic(net.ParseIP(address)), address)
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	assert.NoError(t, network.DialControl("tcp", "93.184.216.34:443", nil))
+	assert.ErrorContains(t, network.DialControl("tcp", "127.0.0.1:8080", nil), "not a public address")
+	assert.ErrorContains(t, network.DialControl("tcp6", "[fd00::1]:443", nil), "not a public address")
+}
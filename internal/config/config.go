@@ -1,16 +1,118 @@
 package config
 
 import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"time"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/common/db"
+	"transaction-server/internal/common/logger"
+	"transaction-server/internal/health"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/ratelimit"
+	"transaction-server/internal/stream"
+	"transaction-server/internal/tenant"
+	"transaction-server/internal/timeout"
+	"transaction-server/internal/tracing"
+	"transaction-server/internal/transaction"
+	"transaction-server/internal/webhook"
 )
 
 type AppConfig struct {
-	App App
-	Db  db.Config
+	App         App
+	Log         logger.Config
+	Tracing     tracing.Config
+	Db          db.Config
+	Transaction transaction.Config
+	Outbox      outbox.Config
+	Webhook     webhook.Config
+	Stream      stream.Config
+	Auth        auth.Config
+	Policy      policy.Config
+	Tenant      tenant.Config
+	RateLimit   ratelimit.Config
+	Timeout     timeout.Config
+	Health      health.Config
 }
 
+// Validate validates the configuration of the application, reporting every invalid key.
+func (c AppConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.App),
+		validation.Field(&c.Log),
+		validation.Field(&c.Tracing),
+		validation.Field(&c.Db),
+		validation.Field(&c.Transaction),
+		validation.Field(&c.Outbox),
+		validation.Field(&c.Webhook),
+		validation.Field(&c.Stream),
+		validation.Field(&c.Auth),
+		validation.Field(&c.Policy),
+		validation.Field(&c.Tenant),
+		validation.Field(&c.RateLimit),
+		validation.Field(&c.Timeout),
+		validation.Field(&c.Health),
+	)
+}
+
+// Redacted returns the configuration with its secrets replaced, for display.
+func (c AppConfig) Redacted() AppConfig {
+	if c.Db.Password != "" {
+		c.Db.Password = redacted
+	}
+	if c.Auth.EncryptionKey != "" {
+		c.Auth.EncryptionKey = redacted
+	}
+	// The replicas are copied, not to redact those of the configuration redacted.
+	c.Db.Replicas = append([]db.ConnectionConfig(nil), c.Db.Replicas...)
+	for i := range c.Db.Replicas {
+		if c.Db.Replicas[i].Password != "" {
+			c.Db.Replicas[i].Password = redacted
+		}
+	}
+	return c
+}
+
+const redacted = "REDACTED"
+
 type App struct {
+	// AppEnv is the environment the configuration is for, see AppEnv.
+	AppEnv      string
 	ServiceName string
 	Hostname    string
 	Port        string
+	// GrpcPort is the address the gRPC API listens on; the gRPC API is off when empty.
+	GrpcPort string
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound the HTTP
+	// connections as in http.Server; zero is no limit. Transaction streams and statement
+	// exports are exempt from WriteTimeout.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ExportWriteTimeout bounds each write of a statement export in place of WriteTimeout:
+	// the export lasts as long as its client keeps reading, but one not reading for that
+	// long is cut off.
+	ExportWriteTimeout time.Duration
+	// ShutdownTimeout bounds the draining of the in-flight requests and background work
+	// on SIGINT or SIGTERM.
+	ShutdownTimeout time.Duration
+	// RuntimeConfig names the runtime configuration file of the config directory, without
+	// its extension, watched for changes to the settings of RuntimeConfig. There is no
+	// runtime configuration file when empty.
+	RuntimeConfig string
+}
+
+// Validate validates the addresses and timeouts.
+func (a App) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.ServiceName, validation.Required),
+		validation.Field(&a.Port, validation.Required),
+		validation.Field(&a.ReadTimeout, validation.Min(time.Duration(0))),
+		validation.Field(&a.ReadHeaderTimeout, validation.Min(time.Duration(0))),
+		validation.Field(&a.WriteTimeout, validation.Min(time.Duration(0))),
+		validation.Field(&a.ExportWriteTimeout, validation.Required, validation.Min(time.Duration(0))),
+		validation.Field(&a.IdleTimeout, validation.Min(time.Duration(0))),
+		validation.Field(&a.ShutdownTimeout, validation.Min(time.Duration(0))),
+	)
 }
@@ -2,21 +2,25 @@
 //
 // Primitives:
 //   - Application should have struct for containing configuration. E.g. refer
-//     growth/internal/config/config.go file.
+//     internal/config/config.go file.
 //   - Application should have a directory holding default file and environment
-//     specific file. E.g. refer growth/configs/* directory.
+//     specific file. E.g. refer config/* directory.
 //
 // Usage:
-//   - E.g. NewDefaultConfig().Load("dev", &config), where config is a struct
+//   - E.g. NewDefaultConfig().Load(Env(), &config), where config is a struct
 //     where configuration gets unmarshalled into.
 package config
 
 import (
+	"fmt"
 	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
@@ -26,6 +30,12 @@ const (
 	DefaultConfigDir      = "./config"
 	DefaultConfigFileName = "default"
 	WorkDirEnv            = "WORKDIR"
+	// AppEnv names the environment, whose configuration file is merged over the default one.
+	AppEnv = "APP_ENV"
+	// EnvPrefix prefixes the environment variables overriding configuration keys.
+	EnvPrefix = "TRANSACTION_SERVER"
+	// FileEnvSuffix suffixes the environment variables naming a file to read a key from.
+	FileEnvSuffix = "_FILE"
 )
 
 // Options is config options.
@@ -33,6 +43,7 @@ type Options struct {
 	configType            string
 	configPath            string
 	defaultConfigFileName string
+	envPrefix             string
 }
 
 // Config is a wrapper over a underlying config loader implementation.
@@ -68,7 +79,23 @@ func NewDefaultOptions() Options {
 
 // NewOptions returns new Options struct.
 func NewOptions(configType string, configPath string, defaultConfigFileName string) Options {
-	return Options{configType, configPath, defaultConfigFileName}
+	return Options{configType, configPath, defaultConfigFileName, EnvPrefix}
+}
+
+// Env returns the environment named by AppEnv, the default one when unset.
+func Env() string {
+	if env := os.Getenv(AppEnv); env != "" {
+		return env
+	}
+	return DefaultConfigFileName
+}
+
+// NewDefaultRuntimeConfig returns new config struct reading the named file of the default
+// directory, for the runtime configuration.
+func NewDefaultRuntimeConfig(name string) *Config {
+	opts := NewDefaultOptions()
+	opts.defaultConfigFileName = name
+	return NewConfig(opts)
 }
 
 // NewDefaultConfig returns new config struct with default options.
@@ -81,25 +108,114 @@ func NewConfig(opts Options) *Config {
 	return &Config{opts, viper.New()}
 }
 
-// Load reads environment specific configurations and along with the defaults
-// unmarshalls into config.
+// Load reads the default configuration, merges the configuration of env over it and the
+// environment variables over both, and unmarshalls the result into config. Keys unknown
+// to config are an error, so that misspelled keys do not go unnoticed.
+//
+// Every key of config is overridden by the environment variable named after the key
+// prefixed with EnvPrefix, upper cased with dots replaced by underscores, e.g.
+// TRANSACTION_SERVER_DB_CONNECTIONCONFIG_PASSWORD for db.ConnectionConfig.password.
+// Lists are comma separated. The same variable suffixed with FileEnvSuffix names a file
+// holding the value instead, for secrets mounted as files. Keys within maps, such as
+// policy.roles, can only be set in the configuration files.
 func (c *Config) Load(env string, config interface{}) error {
-	if err := c.loadByConfigName(c.opts.defaultConfigFileName, config); err != nil {
+	c.viper.SetConfigType(c.opts.configType)
+	c.viper.AddConfigPath(c.opts.configPath)
+	c.viper.SetConfigName(c.opts.defaultConfigFileName)
+	if err := c.viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read the %s config: %w", c.opts.defaultConfigFileName, err)
+	}
+	if env != "" && env != c.opts.defaultConfigFileName {
+		c.viper.SetConfigName(env)
+		if err := c.viper.MergeInConfig(); err != nil {
+			return fmt.Errorf("failed to read the %s config: %w", env, err)
+		}
+	}
+	if err := c.bindEnv(config); err != nil {
 		return err
 	}
-	return c.loadByConfigName(env, config)
+	if err := c.viper.UnmarshalExact(config); err != nil {
+		return fmt.Errorf("failed to unmarshal the config: %w", err)
+	}
+	return nil
 }
 
-// loadByConfigName reads configuration from file and unmarshalls into config.
-func (c *Config) loadByConfigName(configName string, config interface{}) error {
-	c.viper.SetEnvPrefix(strings.ToUpper("growth"))
-	c.viper.SetConfigName(configName)
+// Read reads the default configuration file alone into config, strictly, without the
+// environment variables: it is how the runtime configuration file is read.
+func (c *Config) Read(config interface{}) error {
 	c.viper.SetConfigType(c.opts.configType)
 	c.viper.AddConfigPath(c.opts.configPath)
-	c.viper.AutomaticEnv()
-	c.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	c.viper.SetConfigName(c.opts.defaultConfigFileName)
 	if err := c.viper.ReadInConfig(); err != nil {
-		return err
+		return fmt.Errorf("failed to read the %s config: %w", c.opts.defaultConfigFileName, err)
+	}
+	if err := c.viper.UnmarshalExact(config); err != nil {
+		return fmt.Errorf("failed to unmarshal the %s config: %w", c.opts.defaultConfigFileName, err)
+	}
+	return nil
+}
+
+// Watch calls onChange each time the file read by Read changes.
+func (c *Config) Watch(onChange func()) {
+	c.viper.OnConfigChange(func(fsnotify.Event) {
+		onChange()
+	})
+	c.viper.WatchConfig()
+}
+
+// bindEnv binds each key of config to its environment variable, and sets the keys whose
+// value is to be read from a file.
+func (c *Config) bindEnv(config interface{}) error {
+	for _, key := range keys(reflect.TypeOf(config), "") {
+		name := c.EnvName(key)
+		if err := c.viper.BindEnv(key, name); err != nil {
+			return err
+		}
+		path, ok := os.LookupEnv(name + FileEnvSuffix)
+		if !ok {
+			continue
+		}
+		if _, ok = os.LookupEnv(name); ok {
+			return fmt.Errorf("both %s and %s%s are set", name, name, FileEnvSuffix)
+		}
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s%s: %w", name, FileEnvSuffix, err)
+		}
+		c.viper.Set(key, strings.TrimRight(string(value), "\r\n"))
+	}
+	return nil
+}
+
+// EnvName returns the environment variable overriding the key.
+func (c *Config) EnvName(key string) string {
+	return strings.ToUpper(c.opts.envPrefix + "_" + strings.ReplaceAll(key, ".", "_"))
+}
+
+// keys returns the keys of the fields of a struct type, as unmarshalled into by viper,
+// down to the fields that are not structs. Maps are left out as their keys are not known.
+func keys(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var result []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := prefix + strings.ToLower(field.Name)
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType.Kind() == reflect.Map:
+		case fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}):
+			result = append(result, keys(fieldType, key+".")...)
+		default:
+			result = append(result, key)
+		}
 	}
-	return c.viper.Unmarshal(config)
+	return result
 }
@@ -0,0 +1,134 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/config"
+)
+
+type testConfig struct {
+	Name   string
+	Server struct {
+		Port    int
+		Timeout time.Duration
+		Hosts   []string
+	}
+	Limits map[string]int
+}
+
+func writeConfigs(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".toml"), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func load(t *testing.T, dir string, env string) (testConfig, error) {
+	var loaded testConfig
+	err := config.NewConfig(config.NewOptions("toml", dir, "default")).Load(env, &loaded)
+	return loaded, err
+}
+
+const defaultToml = `
+name = "default"
+[server]
+    port = 80
+    timeout = "1s"
+    hosts = ["a"]
+[limits]
+    x = 1
+`
+
+func TestConfig_Load_Merges_Env(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"default": defaultToml,
+		"staging": "[server]\n    port = 8080\n",
+	})
+
+	loaded, err := load(t, dir, "staging")
+
+	require.NoError(t, err)
+	assert.Equal(t, "default", loaded.Name)
+	assert.Equal(t, 8080, loaded.Server.Port)
+	assert.Equal(t, time.Second, loaded.Server.Timeout)
+	assert.Equal(t, map[string]int{"x": 1}, loaded.Limits)
+
+	_, err = load(t, dir, "production")
+	assert.ErrorContains(t, err, "failed to read the production config")
+}
+
+func TestConfig_Load_Env_Overrides(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{"default": "name = \"default\"\n"})
+	t.Setenv("TRANSACTION_SERVER_SERVER_PORT", "9090")
+	t.Setenv("TRANSACTION_SERVER_SERVER_TIMEOUT", "5s")
+	t.Setenv("TRANSACTION_SERVER_SERVER_HOSTS", "b,c")
+
+	loaded, err := load(t, dir, "default")
+
+	require.NoError(t, err)
+	assert.Equal(t, 9090, loaded.Server.Port)
+	assert.Equal(t, 5*time.Second, loaded.Server.Timeout)
+	assert.Equal(t, []string{"b", "c"}, loaded.Server.Hosts)
+}
+
+func TestConfig_Load_Env_Files(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{"default": defaultToml})
+	secret := filepath.Join(t.TempDir(), "name")
+	require.NoError(t, os.WriteFile(secret, []byte("from-file\n"), 0o600))
+	t.Setenv("TRANSACTION_SERVER_NAME_FILE", secret)
+
+	loaded, err := load(t, dir, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", loaded.Name)
+
+	t.Setenv("TRANSACTION_SERVER_NAME", "from-env")
+	_, err = load(t, dir, "default")
+	assert.EqualError(t, err, "both TRANSACTION_SERVER_NAME and TRANSACTION_SERVER_NAME_FILE are set")
+}
+
+func TestConfig_Load_Unknown_Key(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{"default": defaultToml + "[server.tls]\n    enabled = true\n"})
+
+	_, err := load(t, dir, "default")
+
+	assert.ErrorContains(t, err, "'Server' has invalid keys: tls")
+}
+
+func TestAppConfig_Validate(t *testing.T) {
+	for _, env := range []string{"default", "dev_docker"} {
+		var appConfig config.AppConfig
+		require.NoError(t, config.NewConfig(config.NewOptions("toml", "../../config", "default")).Load(env, &appConfig), env)
+		assert.NoError(t, appConfig.Validate(), env)
+		assert.Equal(t, env, appConfig.App.AppEnv)
+	}
+
+	var appConfig config.AppConfig
+	require.NoError(t, config.NewConfig(config.NewOptions("toml", "../../config", "default")).Load("default", &appConfig))
+	appConfig.Db.Dialect = "oracle"
+	appConfig.Tracing.Exporter = "otlp"
+	appConfig.Tracing.Endpoint = ""
+	appConfig.Policy.DefaultRole = "owner"
+	appConfig.Webhook.MaxAttempts = 0
+	appConfig.Webhook.PollInterval = -time.Second
+	appConfig.Stream.Heartbeat = 0
+	appConfig.Auth.ReplayWindow = 0
+	appConfig.Auth.EncryptionKey = "c2hvcnQ="
+	appConfig.Health.Timeout = -time.Second
+
+	err := appConfig.Validate()
+	assert.ErrorContains(t, err, "Dialect: must be a valid value")
+	assert.ErrorContains(t, err, "Endpoint: cannot be blank")
+	assert.ErrorContains(t, err, "DefaultRole: must be one of the roles")
+	assert.ErrorContains(t, err, "MaxAttempts: cannot be blank")
+	assert.ErrorContains(t, err, "PollInterval: must be no less than 0")
+	assert.ErrorContains(t, err, "Heartbeat: cannot be blank")
+	assert.ErrorContains(t, err, "ReplayWindow: cannot be blank")
+	assert.ErrorContains(t, err, "EncryptionKey: encryption key must be the base64 encoding of 32 bytes")
+	assert.ErrorContains(t, err, "Health: (Timeout: must be no less than 0")
+}
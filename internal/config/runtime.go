@@ -0,0 +1,197 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"transaction-server/internal/common/logger"
+	"transaction-server/internal/ratelimit"
+	"transaction-server/internal/transaction"
+)
+
+// RuntimeConfig holds the settings that can be changed while the application runs, by
+// editing the runtime configuration file. The file overrides the keys it sets in the
+// values of AppConfig; within maps, it adds and replaces entries.
+type RuntimeConfig struct {
+	Log         RuntimeLog
+	Transaction transaction.Config
+	RateLimit   RuntimeRateLimit
+	// Features are feature flags by name. Names are case-insensitive.
+	Features map[string]bool
+}
+
+type RuntimeLog struct {
+	// Level is the minimum level logged, as logger.Config.Level.
+	Level string
+}
+
+// RuntimeRateLimit holds the limits of ratelimit.Config. Turning rate limiting on or off and
+// changing its store require a restart.
+type RuntimeRateLimit struct {
+	Default ratelimit.Limit
+	Routes  map[string]ratelimit.Limit
+}
+
+// Runtime returns the reloadable settings of the configuration.
+func (c AppConfig) Runtime() RuntimeConfig {
+	return RuntimeConfig{
+		Log:         RuntimeLog{Level: c.Log.Level},
+		Transaction: c.Transaction,
+		RateLimit:   RuntimeRateLimit{Default: c.RateLimit.Default, Routes: c.RateLimit.Routes},
+	}.clone()
+}
+
+// Apply returns appConfig with its reloadable settings replaced by those of c.
+func (c RuntimeConfig) Apply(appConfig AppConfig) AppConfig {
+	appConfig.Log.Level = c.Log.Level
+	appConfig.Transaction = c.Transaction
+	appConfig.RateLimit.Default = c.RateLimit.Default
+	appConfig.RateLimit.Routes = c.RateLimit.Routes
+	return appConfig
+}
+
+// Feature reports whether the named feature flag is on.
+func (c RuntimeConfig) Feature(name string) bool {
+	return c.Features[strings.ToLower(name)]
+}
+
+// Validate validates the settings as in AppConfig.
+func (c RuntimeConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Log, validation.By(func(interface{}) error {
+			return logger.Config{Level: c.Log.Level}.Validate()
+		})),
+		validation.Field(&c.Transaction),
+		validation.Field(&c.RateLimit),
+	)
+}
+
+// Validate validates the limits.
+func (c RuntimeRateLimit) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Default),
+		validation.Field(&c.Routes),
+	)
+}
+
+// clone returns a deep copy of c, for reading the runtime file over without changing c.
+func (c RuntimeConfig) clone() RuntimeConfig {
+	var copied RuntimeConfig
+	encoded, _ := json.Marshal(c)
+	_ = json.Unmarshal(encoded, &copied)
+	return copied
+}
+
+// RuntimeSnapshot is a version of the runtime configuration.
+type RuntimeSnapshot struct {
+	// Version starts at 1 and is incremented by each reload changing the configuration.
+	Version  uint64
+	LoadedAt time.Time
+	Config   RuntimeConfig
+}
+
+// Runtime keeps the runtime configuration, read from its file over the values of
+// AppConfig and read again when the file changes. A change is only applied when valid,
+// the previous version is kept otherwise.
+type Runtime struct {
+	file *Config
+	base RuntimeConfig
+
+	current atomic.Pointer[RuntimeSnapshot]
+
+	// mu serializes the reloads and the calls of the subscribers.
+	mu          sync.Mutex
+	subscribers []func(RuntimeConfig)
+	lastError   atomic.Pointer[string]
+}
+
+// NewRuntime reads the runtime configuration from file over base. Without a file, the
+// runtime configuration is base and never changes.
+func NewRuntime(file *Config, base RuntimeConfig) (*Runtime, error) {
+	r := &Runtime{file: file, base: base}
+	config, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(&RuntimeSnapshot{Version: 1, LoadedAt: time.Now(), Config: config})
+	return r, nil
+}
+
+// Current returns the current version of the runtime configuration.
+func (r *Runtime) Current() *RuntimeSnapshot {
+	return r.current.Load()
+}
+
+// Feature reports whether the named feature flag is currently on.
+func (r *Runtime) Feature(name string) bool {
+	return r.Current().Config.Feature(name)
+}
+
+// LastError returns why the last reload was rejected, or an empty string when it was not.
+func (r *Runtime) LastError() string {
+	if lastError := r.lastError.Load(); lastError != nil {
+		return *lastError
+	}
+	return ""
+}
+
+// OnChange calls apply with the current configuration, then with each new version.
+func (r *Runtime) OnChange(apply func(RuntimeConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, apply)
+	apply(r.Current().Config)
+}
+
+// Reload reads the runtime configuration file again and, when it is valid and changed,
+// makes it the current version.
+func (r *Runtime) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	config, err := r.read()
+	if err != nil {
+		message := err.Error()
+		r.lastError.Store(&message)
+		return err
+	}
+	r.lastError.Store(nil)
+	previous := r.Current()
+	if reflect.DeepEqual(previous.Config, config) {
+		return nil
+	}
+	r.current.Store(&RuntimeSnapshot{Version: previous.Version + 1, LoadedAt: time.Now(), Config: config})
+	for _, apply := range r.subscribers {
+		apply(config)
+	}
+	slog.Info("reloaded the runtime config", "version", previous.Version+1)
+	return nil
+}
+
+// Watch reloads the runtime configuration whenever its file changes.
+func (r *Runtime) Watch() {
+	if r.file == nil {
+		return
+	}
+	r.file.Watch(func() {
+		if err := r.Reload(); err != nil {
+			slog.Error("rejected the runtime config", "version", r.Current().Version, "error", err)
+		}
+	})
+}
+
+func (r *Runtime) read() (RuntimeConfig, error) {
+	config := r.base.clone()
+	if r.file == nil {
+		return config, nil
+	}
+	if err := r.file.Read(&config); err != nil {
+		return RuntimeConfig{}, err
+	}
+	return config, config.Validate()
+}
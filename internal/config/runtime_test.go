@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/logger"
+	"transaction-server/internal/config"
+	"transaction-server/internal/ratelimit"
+	"transaction-server/internal/transaction"
+)
+
+func runtimeBase() config.RuntimeConfig {
+	return config.AppConfig{
+		Log:         loggerConfig("info"),
+		Transaction: transaction.Config{BatchMaxSize: 100},
+		RateLimit: ratelimit.Config{
+			Default: ratelimit.Limit{Requests: 50},
+			Routes:  map[string]ratelimit.Limit{"post /transactions": {Requests: 20}},
+		},
+	}.Runtime()
+}
+
+func setupRuntime(t *testing.T, content string) (*config.Runtime, string) {
+	dir := writeConfigs(t, map[string]string{"runtime": content})
+	runtime, err := config.NewRuntime(config.NewConfig(config.NewOptions("toml", dir, "runtime")), runtimeBase())
+	require.NoError(t, err)
+	return runtime, filepath.Join(dir, "runtime.toml")
+}
+
+func TestRuntime_Reads_Over_Base(t *testing.T) {
+	runtime, _ := setupRuntime(t, `
+[log]
+    level = "debug"
+[rateLimit.routes."GET /accounts"]
+    requests = 5
+[features]
+    newCheckout = true
+`)
+
+	snapshot := runtime.Current()
+	assert.Equal(t, uint64(1), snapshot.Version)
+	assert.Equal(t, "debug", snapshot.Config.Log.Level)
+	assert.Equal(t, 100, snapshot.Config.Transaction.BatchMaxSize)
+	assert.Equal(t, 50, snapshot.Config.RateLimit.Default.Requests)
+	assert.Equal(t, map[string]ratelimit.Limit{"post /transactions": {Requests: 20}, "get /accounts": {Requests: 5}},
+		snapshot.Config.RateLimit.Routes)
+	assert.True(t, runtime.Feature("NewCheckout"))
+	assert.False(t, runtime.Feature("other"))
+}
+
+func TestRuntime_Reload(t *testing.T) {
+	runtime, path := setupRuntime(t, "[transaction]\n    batchMaxSize = 10\n")
+	var applied []int
+	runtime.OnChange(func(runtimeConfig config.RuntimeConfig) {
+		applied = append(applied, runtimeConfig.Transaction.BatchMaxSize)
+	})
+
+	// Unchanged.
+	require.NoError(t, runtime.Reload())
+	assert.Equal(t, uint64(1), runtime.Current().Version)
+
+	require.NoError(t, os.WriteFile(path, []byte("[transaction]\n    batchMaxSize = 20\n"), 0o600))
+	require.NoError(t, runtime.Reload())
+	assert.Equal(t, uint64(2), runtime.Current().Version)
+	assert.Equal(t, 20, runtime.Current().Config.Transaction.BatchMaxSize)
+
+	// Invalid changes are rejected, keeping the current version.
+	require.NoError(t, os.WriteFile(path, []byte("[transaction]\n    batchMaxSize = -1\n"), 0o600))
+	assert.Error(t, runtime.Reload())
+	require.NoError(t, os.WriteFile(path, []byte("[db]\n    debug = true\n"), 0o600))
+	assert.Error(t, runtime.Reload())
+	assert.Equal(t, uint64(2), runtime.Current().Version)
+	assert.Contains(t, runtime.LastError(), "invalid keys: db")
+
+	assert.Equal(t, []int{10, 20}, applied)
+}
+
+func TestRuntime_Watch(t *testing.T) {
+	runtime, path := setupRuntime(t, "[log]\n    level = \"info\"\n")
+	runtime.Watch()
+
+	require.NoError(t, os.WriteFile(path, []byte("[log]\n    level = \"warn\"\n"), 0o600))
+
+	assert.Eventually(t, func() bool {
+		return runtime.Current().Config.Log.Level == "warn"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRuntime_Without_File(t *testing.T) {
+	runtime, err := config.NewRuntime(nil, runtimeBase())
+	require.NoError(t, err)
+
+	require.NoError(t, runtime.Reload())
+	assert.Equal(t, uint64(1), runtime.Current().Version)
+	assert.Equal(t, "info", runtime.Current().Config.Log.Level)
+}
+
+func TestRuntimeConfig_Apply(t *testing.T) {
+	appConfig := config.AppConfig{Log: loggerConfig("info")}
+	appConfig.Db.Password = "secret"
+	appConfig.Auth.EncryptionKey = "secret"
+	runtimeConfig := appConfig.Runtime()
+	runtimeConfig.Log.Level = "error"
+
+	effective := runtimeConfig.Apply(appConfig).Redacted()
+
+	assert.Equal(t, "error", effective.Log.Level)
+	assert.Equal(t, "REDACTED", effective.Db.Password)
+	assert.Equal(t, "REDACTED", effective.Auth.EncryptionKey)
+	assert.Equal(t, "secret", appConfig.Db.Password)
+}
+
+func loggerConfig(level string) logger.Config {
+	return logger.Config{Level: level, Format: logger.FormatJSON}
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterAccountsAddCurrency, downAlterAccountsAddCurrency)
+}
+
+func upAlterAccountsAddCurrency(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`ALTER TABLE accounts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD'`)
+	return err
+}
+
+func downAlterAccountsAddCurrency(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`ALTER TABLE accounts DROP COLUMN currency`)
+	return err
+}
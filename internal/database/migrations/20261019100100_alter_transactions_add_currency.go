@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterTransactionsAddCurrency, downAlterTransactionsAddCurrency)
+}
+
+func upAlterTransactionsAddCurrency(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	// The amounts are widened as the settled amount, for currencies without minor units
+	// (JPY) and with three decimals (BHD, KWD, TND).
+	if _, err := tx.Exec(`ALTER TABLE transactions
+		ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD',
+		ADD COLUMN settled_amount DECIMAL(19,4) DEFAULT 0,
+		ADD COLUMN settled_currency CHAR(3) NOT NULL DEFAULT 'USD',
+		ADD COLUMN fx_rate DECIMAL(19,8) DEFAULT 1,
+		MODIFY COLUMN amount DECIMAL(19,4),
+		MODIFY COLUMN balance DECIMAL(19,4) DEFAULT 0`); err != nil {
+		return err
+	}
+	// Existing transactions were all posted in the account currency.
+	_, err := tx.Exec(`UPDATE transactions SET settled_amount = amount`)
+	return err
+}
+
+func downAlterTransactionsAddCurrency(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`ALTER TABLE transactions
+		DROP COLUMN currency,
+		DROP COLUMN settled_amount,
+		DROP COLUMN settled_currency,
+		DROP COLUMN fx_rate,
+		MODIFY COLUMN amount DECIMAL(5,2),
+		MODIFY COLUMN balance DECIMAL(5,2) DEFAULT 0`)
+	return err
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateFxRates, downCreateFxRates)
+}
+
+func upCreateFxRates(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS fx_rates (
+		id VARCHAR(14) NOT NULL,
+		base_currency CHAR(3) NOT NULL,
+		quote_currency CHAR(3) NOT NULL,
+		rate DECIMAL(19,8) NOT NULL,
+		effective_from INT(11) NOT NULL,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		INDEX idx_fx_rates_pair_effective_from (base_currency, quote_currency, effective_from)
+	);`)
+
+	return err
+}
+
+func downCreateFxRates(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS fx_rates`)
+	return err
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateOutboxEvents, downCreateOutboxEvents)
+}
+
+func upCreateOutboxEvents(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS outbox_events (
+		id VARCHAR(14) NOT NULL,
+		type VARCHAR(64) NOT NULL,
+		aggregate_type VARCHAR(32) NOT NULL,
+		aggregate_id VARCHAR(14) NOT NULL,
+		payload TEXT NOT NULL,
+		occurred_at BIGINT NOT NULL,
+		published_at INT(11) NOT NULL DEFAULT 0,
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		INDEX idx_outbox_events_pending (published_at, occurred_at)
+	);`)
+
+	return err
+}
+
+func downCreateOutboxEvents(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS outbox_events`)
+	return err
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateWebhooks, downCreateWebhooks)
+}
+
+func upCreateWebhooks(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id VARCHAR(14) NOT NULL,
+		url VARCHAR(2048) NOT NULL,
+		event_types VARCHAR(255) NOT NULL,
+		secret VARCHAR(128) NOT NULL,
+		active TINYINT(1) NOT NULL DEFAULT 1,
+		description VARCHAR(255) NOT NULL DEFAULT '',
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id)
+	);`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id VARCHAR(14) NOT NULL,
+		subscription_id VARCHAR(14) NOT NULL,
+		event_id VARCHAR(14) NOT NULL,
+		event_type VARCHAR(64) NOT NULL,
+		payload TEXT NOT NULL,
+		status VARCHAR(16) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		next_attempt_at INT(11) NOT NULL DEFAULT 0,
+		last_error TEXT,
+		delivered_at INT(11) NOT NULL DEFAULT 0,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		UNIQUE INDEX idx_webhook_deliveries_subscription_event (subscription_id, event_id),
+		INDEX idx_webhook_deliveries_due (status, next_attempt_at)
+	);`,
+		`CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+		id VARCHAR(14) NOT NULL,
+		delivery_id VARCHAR(14) NOT NULL,
+		subscription_id VARCHAR(14) NOT NULL,
+		number INT NOT NULL,
+		status_code INT NOT NULL DEFAULT 0,
+		error TEXT,
+		duration_ms BIGINT NOT NULL DEFAULT 0,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		INDEX idx_webhook_delivery_attempts_delivery (delivery_id, number)
+	);`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downCreateWebhooks(tx *sql.Tx) error {
+	for _, table := range []string{"webhook_delivery_attempts", "webhook_deliveries", "webhook_subscriptions"} {
+		if _, err := tx.Exec(`DROP TABLE IF EXISTS ` + table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
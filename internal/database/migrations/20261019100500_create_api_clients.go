@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateApiClients, downCreateApiClients)
+}
+
+func upCreateApiClients(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS api_clients (
+		id VARCHAR(14) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		UNIQUE INDEX idx_api_clients_name (name)
+	);`,
+		`CREATE TABLE IF NOT EXISTS api_keys (
+		id VARCHAR(14) NOT NULL,
+		client_id VARCHAR(14) NOT NULL,
+		secret_hash CHAR(64) NOT NULL,
+		revoked_at INT(11) NOT NULL DEFAULT 0,
+		created_at INT(11) NOT NULL,
+		updated_at INT(11) NOT NULL,
+		PRIMARY KEY (id),
+		INDEX idx_api_keys_client (client_id)
+	);`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downCreateApiClients(tx *sql.Tx) error {
+	for _, table := range []string{"api_keys", "api_clients"} {
+		if _, err := tx.Exec(`DROP TABLE IF EXISTS ` + table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
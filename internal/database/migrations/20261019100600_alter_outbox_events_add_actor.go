@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterOutboxEventsAddActor, downAlterOutboxEventsAddActor)
+}
+
+func upAlterOutboxEventsAddActor(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`ALTER TABLE outbox_events ADD COLUMN actor VARCHAR(14) NOT NULL DEFAULT ''`)
+	return err
+}
+
+func downAlterOutboxEventsAddActor(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`ALTER TABLE outbox_events DROP COLUMN actor`)
+	return err
+}
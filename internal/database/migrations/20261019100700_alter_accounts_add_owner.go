@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterAccountsAddOwner, downAlterAccountsAddOwner)
+}
+
+func upAlterAccountsAddOwner(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	statements := []string{
+		`ALTER TABLE accounts ADD COLUMN owner_id VARCHAR(14) NOT NULL DEFAULT ''`,
+		`CREATE INDEX idx_accounts_owner ON accounts (owner_id)`,
+		`ALTER TABLE api_clients ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT ''`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downAlterAccountsAddOwner(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	statements := []string{
+		`ALTER TABLE api_clients DROP COLUMN role`,
+		`DROP INDEX idx_accounts_owner ON accounts`,
+		`ALTER TABLE accounts DROP COLUMN owner_id`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
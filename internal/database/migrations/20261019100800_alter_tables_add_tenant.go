@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+// tenantTables are the tables whose rows belong to a tenant. Existing rows go to the
+// default tenant.
+var tenantTables = []string{
+	"accounts",
+	"transactions",
+	"fx_rates",
+	"outbox_events",
+	"webhook_subscriptions",
+	"webhook_deliveries",
+	"webhook_delivery_attempts",
+	"api_clients",
+	"api_keys",
+}
+
+func init() {
+	goose.AddMigration(upAlterTablesAddTenant, downAlterTablesAddTenant)
+}
+
+func upAlterTablesAddTenant(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	for _, table := range tenantTables {
+		statements := []string{
+			`ALTER TABLE ` + table + ` ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default'`,
+			`CREATE INDEX idx_` + table + `_tenant ON ` + table + ` (tenant_id)`,
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func downAlterTablesAddTenant(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	for _, table := range tenantTables {
+		statements := []string{
+			`DROP INDEX idx_` + table + `_tenant ON ` + table,
+			`ALTER TABLE ` + table + ` DROP COLUMN tenant_id`,
+		}
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateRateLimitBuckets, downCreateRateLimitBuckets)
+}
+
+func upCreateRateLimitBuckets(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+		id VARCHAR(255) NOT NULL,
+		tokens DOUBLE PRECISION NOT NULL,
+		refilled_at BIGINT NOT NULL,
+		full_at BIGINT NOT NULL,
+		version BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (id),
+		INDEX idx_rate_limit_buckets_full_at (full_at)
+	);`)
+	return err
+}
+
+func downCreateRateLimitBuckets(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS rate_limit_buckets`)
+	return err
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateOutboxLeases, downCreateOutboxLeases)
+}
+
+func upCreateOutboxLeases(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS outbox_leases (
+		id VARCHAR(255) NOT NULL,
+		holder VARCHAR(255) NOT NULL,
+		expires_at BIGINT NOT NULL,
+		PRIMARY KEY (id)
+	);`)
+	return err
+}
+
+func downCreateOutboxLeases(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS outbox_leases`)
+	return err
+}
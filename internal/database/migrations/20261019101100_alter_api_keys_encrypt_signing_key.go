@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterApiKeysEncryptSigningKey, downAlterApiKeysEncryptSigningKey)
+}
+
+func upAlterApiKeysEncryptSigningKey(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	// The signing keys are kept encrypted; those stored in clear are encrypted by
+	// `apikey encrypt`, as the encryption key is not known to the migrations.
+	_, err := tx.Exec(`ALTER TABLE api_keys CHANGE COLUMN secret_hash encrypted_signing_key VARCHAR(255) NOT NULL`)
+	return err
+}
+
+func downAlterApiKeysEncryptSigningKey(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	// The keys encrypted since can no longer be verified and must be issued again.
+	_, err := tx.Exec(`ALTER TABLE api_keys CHANGE COLUMN encrypted_signing_key secret_hash VARCHAR(255) NOT NULL`)
+	return err
+}
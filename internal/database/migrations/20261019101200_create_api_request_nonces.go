@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCreateApiRequestNonces, downCreateApiRequestNonces)
+}
+
+func upCreateApiRequestNonces(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS api_request_nonces (
+		key_id VARCHAR(14) NOT NULL,
+		signature CHAR(64) NOT NULL,
+		expires_at BIGINT NOT NULL,
+		PRIMARY KEY (key_id, signature),
+		INDEX idx_api_request_nonces_expires_at (expires_at)
+	);`)
+	return err
+}
+
+func downCreateApiRequestNonces(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS api_request_nonces`)
+	return err
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"database/sql"
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAlterOutboxEventsAddSequence, downAlterOutboxEventsAddSequence)
+}
+
+func upAlterOutboxEventsAddSequence(tx *sql.Tx) error {
+	// This code is executed when the migration is applied.
+	// The events recorded so far are numbered in the order they were relayed in, by the time
+	// they occurred, before the column numbers the new ones as they are inserted.
+	statements := []string{
+		`ALTER TABLE outbox_events ADD COLUMN sequence BIGINT NULL,
+			ADD COLUMN next_attempt_at BIGINT NOT NULL DEFAULT 0`,
+		`SET @sequence = 0`,
+		`UPDATE outbox_events SET sequence = (@sequence := @sequence + 1) ORDER BY occurred_at, id`,
+		`ALTER TABLE outbox_events MODIFY COLUMN sequence BIGINT NOT NULL AUTO_INCREMENT,
+			ADD UNIQUE INDEX uq_outbox_events_sequence (sequence),
+			DROP INDEX idx_outbox_events_pending,
+			ADD INDEX idx_outbox_events_pending (published_at, sequence),
+			ADD INDEX idx_outbox_events_aggregate (aggregate_type, aggregate_id, published_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downAlterOutboxEventsAddSequence(tx *sql.Tx) error {
+	// This code is executed when the migration is rolled back.
+	_, err := tx.Exec(`ALTER TABLE outbox_events
+		DROP INDEX idx_outbox_events_aggregate,
+		DROP INDEX idx_outbox_events_pending,
+		ADD INDEX idx_outbox_events_pending (published_at, occurred_at),
+		DROP INDEX uq_outbox_events_sequence,
+		DROP COLUMN sequence,
+		DROP COLUMN next_attempt_at`)
+	return err
+}
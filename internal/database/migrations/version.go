@@ -0,0 +1,5 @@
+package migrations
+
+// Latest is the version of the newest migration, the version the application expects the
+// database to be migrated to. Bump it when adding a migration.
+const Latest int64 = 20261019101300
@@ -0,0 +1,19 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/pressly/goose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/database/migrations"
+)
+
+func TestLatest(t *testing.T) {
+	collected, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+	require.NoError(t, err)
+	last, err := collected.Last()
+	require.NoError(t, err)
+
+	assert.Equal(t, last.Version, migrations.Latest, "migrations.Latest is not the newest migration")
+}
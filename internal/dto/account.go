@@ -23,6 +23,47 @@ type GetAccountResponse struct {
 	Account *Account `json:"account,omitempty"`
 }
 
+// ListAccountRequest represents the request object for listing accounts.
+// swagger:model
+type ListAccountRequest struct {
+	// The limit for the number of accounts.
+	Limit uint32 `json:"limit" form:"limit"`
+	// The offset for pagination.
+	Offset uint32 `json:"offset" form:"offset"`
+	// The document number to filter accounts.
+	DocumentNumber string `json:"document_number" form:"document_number"`
+	// The owner the accounts are restricted to, set from the access policy rather than the request.
+	OwnerID string `json:"-" form:"-"`
+}
+
+// GetLimit returns the limit value for pagination.
+func (l *ListAccountRequest) GetLimit() uint32 {
+	return l.Limit
+}
+
+// GetOffset returns the offset value for pagination.
+func (l *ListAccountRequest) GetOffset() uint32 {
+	return l.Offset
+}
+
+// GetDocumentNumber returns the document number.
+func (l *ListAccountRequest) GetDocumentNumber() string {
+	return l.DocumentNumber
+}
+
+// GetOwnerID returns the owner the accounts are restricted to.
+func (l *ListAccountRequest) GetOwnerID() string {
+	return l.OwnerID
+}
+
+// swagger:model
+type ListAccountResponse struct {
+	// Base response object.
+	*Base
+	// The list of accounts, newest first.
+	Accounts []*Account `json:"accounts,omitempty"`
+}
+
 // Account represents account details.
 type Account struct {
 	// The ID of the account.
@@ -31,6 +72,8 @@ type Account struct {
 	Name string `json:"name"`
 	// The document number associated with the account.
 	DocumentNumber string `json:"document_number"`
+	// The ISO 4217 currency the account settles in. Defaults to USD.
+	Currency string `json:"currency"`
 	// The timestamp when the account was created.
 	CreatedAt int64 `json:"created_at"`
 	// The timestamp when the account was last updated.
@@ -0,0 +1,18 @@
+package dto
+
+// GetConfigResponse represents the response object for the effective configuration.
+// swagger:model
+type GetConfigResponse struct {
+	// The base response object.
+	*Base
+	// The version of the runtime configuration, incremented by each reload changing it.
+	Version uint64 `json:"version,omitempty"`
+	// The timestamp when the version was loaded.
+	LoadedAt int64 `json:"loaded_at,omitempty"`
+	// Why the last change of the runtime configuration file was rejected, if it was.
+	ReloadError string `json:"reload_error,omitempty"`
+	// The configuration in effect, with its secrets redacted.
+	Config interface{} `json:"config,omitempty"`
+	// The feature flags by name.
+	Features map[string]bool `json:"features,omitempty"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+// FxRate represents an exchange rate between two currencies.
+// swagger:model
+type FxRate struct {
+	// The ID of the rate.
+	ID string `json:"id"`
+	// The ISO 4217 code of the currency being converted from.
+	BaseCurrency string `json:"base_currency"`
+	// The ISO 4217 code of the currency being converted to.
+	QuoteCurrency string `json:"quote_currency"`
+	// The units of quote currency for one unit of base currency.
+	Rate float64 `json:"rate"`
+	// The timestamp from which the rate applies. Defaults to the creation time.
+	EffectiveFrom int64 `json:"effective_from"`
+	// The timestamp when the rate was created.
+	CreatedAt int64 `json:"created_at"`
+	// The timestamp when the rate was last updated.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// CreateFxRateRequest represents the request object for creating an exchange rate.
+// swagger:model
+type CreateFxRateRequest struct {
+	// The rate to be created.
+	FxRate *FxRate `json:"fx_rate"`
+}
+
+// CreateFxRateResponse represents the response object for creating an exchange rate.
+// swagger:model
+type CreateFxRateResponse struct {
+	// The base response object.
+	*Base
+	// The created rate.
+	FxRate *FxRate `json:"fx_rate,omitempty"`
+}
+
+// ListFxRateRequest represents the request object for listing exchange rates.
+// swagger:model
+type ListFxRateRequest struct {
+	// The limit for the number of rates.
+	Limit uint32 `json:"limit"`
+	// The offset for pagination.
+	Offset uint32 `json:"offset"`
+	// The base currency to filter rates.
+	BaseCurrency string `json:"base_currency"`
+	// The quote currency to filter rates.
+	QuoteCurrency string `json:"quote_currency"`
+}
+
+// GetLimit returns the limit value for pagination.
+func (l *ListFxRateRequest) GetLimit() uint32 {
+	return l.Limit
+}
+
+// GetOffset returns the offset value for pagination.
+func (l *ListFxRateRequest) GetOffset() uint32 {
+	return l.Offset
+}
+
+// GetBaseCurrency returns the base currency.
+func (l *ListFxRateRequest) GetBaseCurrency() string {
+	return l.BaseCurrency
+}
+
+// GetQuoteCurrency returns the quote currency.
+func (l *ListFxRateRequest) GetQuoteCurrency() string {
+	return l.QuoteCurrency
+}
+
+// ListFxRateResponse represents the response object for listing exchange rates.
+// swagger:model
+type ListFxRateResponse struct {
+	// The base response object.
+	*Base
+	// The list of rates.
+	FxRates []*FxRate `json:"fx_rates,omitempty"`
+}
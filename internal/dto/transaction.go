@@ -29,6 +29,20 @@ var NegativeOperationTypes = [...]OperationType{
 	OperationTypeWithdraw,
 }
 
+// Sort orders accepted when listing transactions.
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// TransactionSortFields are the columns transactions can be sorted by.
+var TransactionSortFields = []string{
+	"created_at",
+	"event_date",
+	"amount",
+	"balance",
+}
+
 func (o OperationType) IsPresent(arr []OperationType) bool {
 	for _, a := range arr {
 		if a == o {
@@ -51,8 +65,18 @@ type Transaction struct {
 	AccountID string `json:"account_id"`
 	// The type of operation.
 	OperationType string `json:"operation_type"`
-	// The amount of the transaction.
+	// The amount of the transaction in its own currency.
 	Amount float64 `json:"amount"`
+	// The ISO 4217 currency of the amount. Defaults to the account currency.
+	Currency string `json:"currency"`
+	// The amount converted into the account currency at posting time.
+	SettledAmount float64 `json:"settled_amount"`
+	// The ISO 4217 currency of the account the transaction settled into.
+	SettledCurrency string `json:"settled_currency"`
+	// The rate applied to convert the amount into the settled amount.
+	FxRate float64 `json:"fx_rate"`
+	// Whether balances held in other currencies may be discharged by converting this transaction.
+	ConvertCurrency bool `json:"convert_currency,omitempty"`
 	// The balance of the transaction.
 	Balance float64 `json:"balance"`
 	// The event date of the transaction.
@@ -84,19 +108,81 @@ type GetTransactionResponse struct {
 	Transaction *Transaction `json:"transaction,omitempty"`
 }
 
+// Modes of a batch request.
+const (
+	// BatchModeAtomic creates all transactions of the batch or none of them.
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort creates every valid transaction and reports failures per item.
+	BatchModeBestEffort = "best_effort"
+)
+
+// BatchCreateTransactionRequest represents the request object for creating transactions in bulk.
+// swagger:model
+type BatchCreateTransactionRequest struct {
+	// The batch mode: atomic or best_effort. Defaults to atomic.
+	Mode string `json:"mode"`
+	// The transactions to be created, in posting order.
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// BatchTransactionResult represents the outcome of one transaction of a batch.
+// swagger:model
+type BatchTransactionResult struct {
+	// The position of the transaction in the request.
+	Index int `json:"index"`
+	// Indicates whether the transaction was created.
+	Success bool `json:"success"`
+	// The created transaction.
+	Transaction *Transaction `json:"transaction,omitempty"`
+	// Error details if the transaction was not created.
+	Error *ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchCreateTransactionResponse represents the response object for creating transactions in bulk.
+// swagger:model
+type BatchCreateTransactionResponse struct {
+	// The base response object.
+	*Base
+	// The outcome of each transaction, in request order.
+	Results []*BatchTransactionResult `json:"results,omitempty"`
+}
+
 // ListTransactionRequest represents the request object for listing transactions.
 // swagger:model
 type ListTransactionRequest struct {
 	// The limit for the number of transactions.
-	Limit uint32 `json:"limit"`
+	Limit uint32 `json:"limit" form:"limit"`
 	// The offset for pagination.
-	Offset uint32 `json:"offset"`
+	Offset uint32 `json:"offset" form:"offset"`
 	// The account ID to filter transactions.
-	AccountId string `json:"account_id"`
+	AccountId string `json:"account_id" form:"account_id"`
 	// The operation type to filter transactions.
-	OperationType string `json:"operation_type"`
+	OperationType string `json:"operation_type" form:"operation_type"`
 	// The operation types to filter transactions.
-	OperationTypes []string `json:"operation_types"`
+	OperationTypes []string `json:"operation_types" form:"operation_types"`
+	// The currency to filter transactions.
+	Currency string `json:"currency" form:"currency"`
+	// The earliest event date (Unix timestamp, inclusive) to filter transactions.
+	EventDateFrom int64 `json:"event_date_from" form:"event_date_from"`
+	// The latest event date (Unix timestamp, inclusive) to filter transactions.
+	EventDateTo int64 `json:"event_date_to" form:"event_date_to"`
+	// The earliest creation time (Unix timestamp, inclusive) to filter transactions.
+	CreatedAtFrom int64 `json:"created_at_from" form:"created_at_from"`
+	// The latest creation time (Unix timestamp, inclusive) to filter transactions.
+	CreatedAtTo int64 `json:"created_at_to" form:"created_at_to"`
+	// The minimum signed amount (inclusive) to filter transactions.
+	MinAmount *float64 `json:"min_amount" form:"min_amount"`
+	// The maximum signed amount (inclusive) to filter transactions.
+	MaxAmount *float64 `json:"max_amount" form:"max_amount"`
+	// Whether to return only transactions with an open (non-zero) balance.
+	OnlyOpenBalance bool `json:"only_open_balance" form:"only_open_balance"`
+	// The field to sort by: created_at, event_date, amount or balance. Defaults to created_at.
+	SortBy string `json:"sort_by" form:"sort_by"`
+	// The sort direction: asc or desc. Defaults to desc.
+	SortOrder string `json:"sort_order" form:"sort_order"`
+	// The opaque cursor of the page to fetch, taken from next_cursor or prev_cursor of a previous response.
+	// Cursors are only supported when sorting by created_at without an offset.
+	Cursor string `json:"cursor" form:"cursor"`
 }
 
 // GetLimit returns the limit value for pagination.
@@ -124,6 +210,61 @@ func (l *ListTransactionRequest) GetOperationTypes() []string {
 	return l.OperationTypes
 }
 
+// GetCurrency returns the currency.
+func (l *ListTransactionRequest) GetCurrency() string {
+	return l.Currency
+}
+
+// GetEventDateFrom returns the earliest event date.
+func (l *ListTransactionRequest) GetEventDateFrom() int64 {
+	return l.EventDateFrom
+}
+
+// GetEventDateTo returns the latest event date.
+func (l *ListTransactionRequest) GetEventDateTo() int64 {
+	return l.EventDateTo
+}
+
+// GetCreatedAtFrom returns the earliest creation time.
+func (l *ListTransactionRequest) GetCreatedAtFrom() int64 {
+	return l.CreatedAtFrom
+}
+
+// GetCreatedAtTo returns the latest creation time.
+func (l *ListTransactionRequest) GetCreatedAtTo() int64 {
+	return l.CreatedAtTo
+}
+
+// GetMinAmount returns the minimum amount.
+func (l *ListTransactionRequest) GetMinAmount() *float64 {
+	return l.MinAmount
+}
+
+// GetMaxAmount returns the maximum amount.
+func (l *ListTransactionRequest) GetMaxAmount() *float64 {
+	return l.MaxAmount
+}
+
+// GetOnlyOpenBalance returns whether only open balances are requested.
+func (l *ListTransactionRequest) GetOnlyOpenBalance() bool {
+	return l.OnlyOpenBalance
+}
+
+// GetSortBy returns the sort field.
+func (l *ListTransactionRequest) GetSortBy() string {
+	return l.SortBy
+}
+
+// GetSortOrder returns the sort direction.
+func (l *ListTransactionRequest) GetSortOrder() string {
+	return l.SortOrder
+}
+
+// GetCursor returns the page cursor.
+func (l *ListTransactionRequest) GetCursor() string {
+	return l.Cursor
+}
+
 // ListTransactionResponse represents the response object for listing transactions.
 // swagger:model
 type ListTransactionResponse struct {
@@ -131,4 +272,58 @@ type ListTransactionResponse struct {
 	*Base
 	// The list of transactions.
 	Transactions []*Transaction `json:"transactions,omitempty"`
+	// The cursor of the next page, empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// The cursor of the previous page, empty on the first page.
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// ExportTransactionRequest represents the request object for exporting the statement of an account.
+type ExportTransactionRequest struct {
+	// The account ID, taken from the path.
+	AccountId string `json:"account_id" form:"-"`
+	// The statement format: csv, ofx or camt053.
+	Format string `json:"format" form:"format"`
+	// The earliest event date (Unix timestamp, inclusive). Defaults to the account creation.
+	From int64 `json:"from" form:"from"`
+	// The latest event date (Unix timestamp, inclusive). Defaults to now.
+	To int64 `json:"to" form:"to"`
+}
+
+// GetAccountId returns the account ID.
+func (e *ExportTransactionRequest) GetAccountId() string {
+	return e.AccountId
+}
+
+// GetFrom returns the earliest event date.
+func (e *ExportTransactionRequest) GetFrom() int64 {
+	return e.From
+}
+
+// GetTo returns the latest event date.
+func (e *ExportTransactionRequest) GetTo() int64 {
+	return e.To
+}
+
+// ExportTransactionResponse represents the response object of a failed export;
+// a successful export responds with the statement itself.
+type ExportTransactionResponse struct {
+	// The base response object.
+	*Base
+}
+
+// StreamTransactionRequest represents the request object for streaming the live transactions of an account.
+type StreamTransactionRequest struct {
+	// The ID of the account, taken from the path.
+	AccountId string `json:"account_id" form:"-"`
+	// The ID of the last event received, to resume a stream. Taken from the
+	// Last-Event-ID header when the query parameter is not set.
+	LastEventID string `json:"last_event_id" form:"last_event_id"`
+}
+
+// StreamTransactionResponse represents the response object of a stream that could not be
+// opened; an open stream responds with Server-Sent Events.
+type StreamTransactionResponse struct {
+	// The base response object.
+	*Base
 }
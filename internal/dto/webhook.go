@@ -0,0 +1,186 @@
+package dto
+
+// WebhookSubscription represents an endpoint notified of domain events.
+// swagger:model
+type WebhookSubscription struct {
+	// The ID of the subscription.
+	ID string `json:"id"`
+	// The HTTPS or HTTP URL deliveries are posted to.
+	URL string `json:"url"`
+	// The event types delivered, e.g. transaction.created, or * for all.
+	EventTypes []string `json:"event_types"`
+	// The secret deliveries are signed with. Generated when not given and only
+	// returned when the subscription is created or the secret is rotated.
+	Secret string `json:"secret,omitempty"`
+	// Whether deliveries are made. Defaults to true.
+	Active *bool `json:"active,omitempty"`
+	// A description of the subscription.
+	Description string `json:"description"`
+	// The timestamp when the subscription was created.
+	CreatedAt int64 `json:"created_at"`
+	// The timestamp when the subscription was last updated.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// CreateWebhookSubscriptionRequest represents the request object for creating a webhook subscription.
+// swagger:model
+type CreateWebhookSubscriptionRequest struct {
+	// The subscription to be created.
+	Subscription *WebhookSubscription `json:"subscription"`
+}
+
+// UpdateWebhookSubscriptionRequest represents the request object for updating a webhook subscription.
+// The whole subscription is replaced; an empty secret keeps the current one.
+// swagger:model
+type UpdateWebhookSubscriptionRequest struct {
+	// The ID of the subscription, taken from the path.
+	ID string `json:"-"`
+	// The new state of the subscription.
+	Subscription *WebhookSubscription `json:"subscription"`
+}
+
+// WebhookSubscriptionResponse represents the response object holding a webhook subscription.
+// swagger:model
+type WebhookSubscriptionResponse struct {
+	// The base response object.
+	*Base
+	// The subscription.
+	Subscription *WebhookSubscription `json:"subscription,omitempty"`
+}
+
+// DeleteWebhookSubscriptionResponse represents the response object for deleting a webhook subscription.
+// swagger:model
+type DeleteWebhookSubscriptionResponse struct {
+	// The base response object.
+	*Base
+}
+
+// ListWebhookSubscriptionRequest represents the request object for listing webhook subscriptions.
+type ListWebhookSubscriptionRequest struct {
+	// The limit for the number of subscriptions.
+	Limit uint32 `json:"limit" form:"limit"`
+	// The offset for pagination.
+	Offset uint32 `json:"offset" form:"offset"`
+}
+
+// GetLimit returns the limit value for pagination.
+func (l *ListWebhookSubscriptionRequest) GetLimit() uint32 {
+	return l.Limit
+}
+
+// GetOffset returns the offset value for pagination.
+func (l *ListWebhookSubscriptionRequest) GetOffset() uint32 {
+	return l.Offset
+}
+
+// ListWebhookSubscriptionResponse represents the response object for listing webhook subscriptions.
+// swagger:model
+type ListWebhookSubscriptionResponse struct {
+	// The base response object.
+	*Base
+	// The list of subscriptions.
+	Subscriptions []*WebhookSubscription `json:"subscriptions,omitempty"`
+}
+
+// WebhookDelivery represents the delivery of an event to a subscription.
+// swagger:model
+type WebhookDelivery struct {
+	// The ID of the delivery.
+	ID string `json:"id"`
+	// The ID of the subscription.
+	SubscriptionID string `json:"subscription_id"`
+	// The ID of the delivered event.
+	EventID string `json:"event_id"`
+	// The type of the delivered event.
+	EventType string `json:"event_type"`
+	// The state of the delivery: pending, succeeded or dead.
+	Status string `json:"status"`
+	// The number of attempts made.
+	Attempts int `json:"attempts"`
+	// The timestamp of the next attempt of a pending delivery.
+	NextAttemptAt int64 `json:"next_attempt_at"`
+	// The error of the last failed attempt.
+	LastError string `json:"last_error,omitempty"`
+	// The timestamp when the delivery succeeded.
+	DeliveredAt int64 `json:"delivered_at,omitempty"`
+	// The timestamp when the delivery was created.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// WebhookDeliveryResponse represents the response object holding a webhook delivery.
+// swagger:model
+type WebhookDeliveryResponse struct {
+	// The base response object.
+	*Base
+	// The delivery.
+	Delivery *WebhookDelivery `json:"delivery,omitempty"`
+}
+
+// ListWebhookDeliveryRequest represents the request object for listing the deliveries of a subscription.
+type ListWebhookDeliveryRequest struct {
+	// The ID of the subscription, taken from the path.
+	SubscriptionID string `json:"-" form:"-"`
+	// The state to filter deliveries: pending, succeeded or dead.
+	Status string `json:"status" form:"status"`
+	// The limit for the number of deliveries.
+	Limit uint32 `json:"limit" form:"limit"`
+	// The offset for pagination.
+	Offset uint32 `json:"offset" form:"offset"`
+}
+
+// GetLimit returns the limit value for pagination.
+func (l *ListWebhookDeliveryRequest) GetLimit() uint32 {
+	return l.Limit
+}
+
+// GetOffset returns the offset value for pagination.
+func (l *ListWebhookDeliveryRequest) GetOffset() uint32 {
+	return l.Offset
+}
+
+// GetSubscriptionID returns the subscription ID.
+func (l *ListWebhookDeliveryRequest) GetSubscriptionID() string {
+	return l.SubscriptionID
+}
+
+// GetStatus returns the delivery state.
+func (l *ListWebhookDeliveryRequest) GetStatus() string {
+	return l.Status
+}
+
+// ListWebhookDeliveryResponse represents the response object for listing webhook deliveries.
+// swagger:model
+type ListWebhookDeliveryResponse struct {
+	// The base response object.
+	*Base
+	// The list of deliveries.
+	Deliveries []*WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+// WebhookAttempt represents one attempt at a webhook delivery.
+// swagger:model
+type WebhookAttempt struct {
+	// The ID of the attempt.
+	ID string `json:"id"`
+	// The ID of the delivery.
+	DeliveryID string `json:"delivery_id"`
+	// The 1-based number of the attempt.
+	Number int `json:"number"`
+	// The HTTP status returned by the endpoint, 0 when no response was received.
+	StatusCode int `json:"status_code"`
+	// The error of a failed attempt.
+	Error string `json:"error,omitempty"`
+	// The duration of the attempt in milliseconds.
+	DurationMs int64 `json:"duration_ms"`
+	// The timestamp of the attempt.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// ListWebhookAttemptResponse represents the response object for listing the attempts of a delivery.
+// swagger:model
+type ListWebhookAttemptResponse struct {
+	// The base response object.
+	*Base
+	// The list of attempts, oldest first.
+	Attempts []*WebhookAttempt `json:"attempts,omitempty"`
+}
@@ -0,0 +1,88 @@
+package fxrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"transaction-server/internal/common/db"
+)
+
+// ErrRateNotFound is returned when no rate is effective for a currency pair at the requested time.
+var ErrRateNotFound = errors.New("no fx rate effective for currency pair")
+
+type ICore interface {
+	Create(ctx context.Context, rate *Rate) error
+	List(ctx context.Context, request IListRequest) (*[]Rate, error)
+	GetRate(ctx context.Context, base string, quote string, at int64) (float64, error)
+}
+
+type Core struct {
+	repo IRepo
+}
+
+func NewCore(repo IRepo) ICore {
+	return &Core{repo: repo}
+}
+
+func (c *Core) Create(ctx context.Context, rate *Rate) error {
+	return c.repo.Create(ctx, rate)
+}
+
+func (c *Core) List(ctx context.Context, request IListRequest) (*[]Rate, error) {
+	conditions := make([]clause.Expression, 0)
+	if request.GetBaseCurrency() != "" {
+		conditions = append(conditions, clause.Eq{Column: "base_currency", Value: request.GetBaseCurrency()})
+	}
+	if request.GetQuoteCurrency() != "" {
+		conditions = append(conditions, clause.Eq{Column: "quote_currency", Value: request.GetQuoteCurrency()})
+	}
+	repoRequest := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{
+			Limit:  request.GetLimit(),
+			Offset: request.GetOffset(),
+		},
+		Conditions: conditions,
+	}
+	listResponse := make([]Rate, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
+
+// GetRate returns the units of quote currency for one unit of base currency, using the
+// latest rate effective at the given time. If only the inverse pair is maintained, its
+// reciprocal is used.
+func (c *Core) GetRate(ctx context.Context, base string, quote string, at int64) (float64, error) {
+	if base == quote {
+		return 1, nil
+	}
+	rate := new(Rate)
+	err := c.repo.FindByConditions(ctx, rate, effectiveRateConditions(base, quote, at))
+	if err == nil {
+		return rate.Rate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	inverse := new(Rate)
+	if err = c.repo.FindByConditions(ctx, inverse, effectiveRateConditions(quote, base, at)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+		}
+		return 0, err
+	}
+	return 1 / inverse.Rate, nil
+}
+
+// effectiveRateConditions selects the most recent rate of the pair which became effective at or before the given time.
+func effectiveRateConditions(base string, quote string, at int64) []clause.Expression {
+	return []clause.Expression{
+		clause.Eq{Column: "base_currency", Value: base},
+		clause.Eq{Column: "quote_currency", Value: quote},
+		clause.Lte{Column: "effective_from", Value: at},
+		clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "effective_from"}, Desc: true}}},
+	}
+}
@@ -0,0 +1,101 @@
+package fxrate_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"testing"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/fxrate/mock"
+)
+
+type testDependencies struct {
+	mockRepoCtrl *gomock.Controller
+	mockRepo     *mock.MockIRepo
+	core         fxrate.ICore
+}
+
+func setupTest(t *testing.T) *testDependencies {
+	mockRepoCtrl := gomock.NewController(t)
+	mockRepo := mock.NewMockIRepo(mockRepoCtrl)
+	core := fxrate.NewCore(mockRepo)
+	return &testDependencies{
+		mockRepoCtrl: mockRepoCtrl,
+		mockRepo:     mockRepo,
+		core:         core,
+	}
+}
+
+func teardownTest(td *testDependencies) {
+	td.mockRepoCtrl.Finish()
+}
+
+func TestCore_GetRate_SameCurrency(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	rate, err := td.core.GetRate(context.Background(), "USD", "USD", 1710000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+}
+
+func TestCore_GetRate_Direct(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindByConditions(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, model interface{}, conditions []clause.Expression) error {
+			assert.Contains(t, conditions, clause.Eq{Column: "base_currency", Value: "EUR"})
+			assert.Contains(t, conditions, clause.Eq{Column: "quote_currency", Value: "USD"})
+			assert.Contains(t, conditions, clause.Lte{Column: "effective_from", Value: int64(1710000000)})
+			model.(*fxrate.Rate).Rate = 1.08
+			return nil
+		})
+
+	rate, err := td.core.GetRate(context.Background(), "EUR", "USD", 1710000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.08, rate)
+}
+
+func TestCore_GetRate_Inverse(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	gomock.InOrder(
+		td.mockRepo.EXPECT().FindByConditions(gomock.Any(), gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound),
+		td.mockRepo.EXPECT().FindByConditions(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, model interface{}, conditions []clause.Expression) error {
+				assert.Contains(t, conditions, clause.Eq{Column: "base_currency", Value: "USD"})
+				model.(*fxrate.Rate).Rate = 0.8
+				return nil
+			}),
+	)
+
+	rate, err := td.core.GetRate(context.Background(), "EUR", "USD", 1710000000)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, rate)
+}
+
+func TestCore_GetRate_NotFound(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindByConditions(gomock.Any(), gomock.Any(), gomock.Any()).Return(gorm.ErrRecordNotFound).Times(2)
+
+	_, err := td.core.GetRate(context.Background(), "EUR", "USD", 1710000000)
+	assert.True(t, errors.Is(err, fxrate.ErrRateNotFound))
+}
+
+func TestCore_GetRate_RepoError(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindByConditions(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("repo error"))
+
+	_, err := td.core.GetRate(context.Background(), "EUR", "USD", 1710000000)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, fxrate.ErrRateNotFound))
+}
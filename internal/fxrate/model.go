@@ -0,0 +1,62 @@
+package fxrate
+
+import (
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/dto"
+)
+
+// Rate represents the exchange rate of one unit of BaseCurrency in QuoteCurrency.
+type Rate struct {
+	db.Model              // Embedding the common database model
+	BaseCurrency  string  `json:"base_currency"`  // ISO 4217 code of the currency being converted from
+	QuoteCurrency string  `json:"quote_currency"` // ISO 4217 code of the currency being converted to
+	Rate          float64 `json:"rate"`           // Units of QuoteCurrency for one unit of BaseCurrency
+	EffectiveFrom int64   `json:"effective_from"` // Time from which the rate applies (Unix timestamp)
+}
+
+// TableName returns the name of the database table for the Rate entity.
+func (e *Rate) TableName() string {
+	return "fx_rates"
+}
+
+// EntityName returns the name of the entity.
+func (e *Rate) EntityName() string {
+	return "fx_rate"
+}
+
+// SetDefaults sets default values for the Rate entity.
+func (e *Rate) SetDefaults() error {
+	if e.EffectiveFrom == 0 {
+		e.EffectiveFrom = e.CreatedAt
+	}
+	return nil
+}
+
+// ToDto converts the Rate entity to its DTO (data transfer object) representation.
+func (e *Rate) ToDto() *dto.FxRate {
+	return &dto.FxRate{
+		ID:            e.ID,
+		BaseCurrency:  e.BaseCurrency,
+		QuoteCurrency: e.QuoteCurrency,
+		Rate:          e.Rate,
+		EffectiveFrom: e.EffectiveFrom,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+	}
+}
+
+// ApplyDto updates the Rate entity fields based on the values provided in the DTO.
+func (e *Rate) ApplyDto(val *dto.FxRate) {
+	e.BaseCurrency = val.BaseCurrency
+	e.QuoteCurrency = val.QuoteCurrency
+	e.Rate = val.Rate
+	e.EffectiveFrom = val.EffectiveFrom
+}
+
+// IListRequest is the interface that wraps basic request attribute getters for list requests.
+type IListRequest interface {
+	GetLimit() uint32
+	GetOffset() uint32
+	GetBaseCurrency() string
+	GetQuoteCurrency() string
+}
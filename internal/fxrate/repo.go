@@ -0,0 +1,13 @@
+package fxrate
+
+import (
+	"context"
+	"gorm.io/gorm/clause"
+	"transaction-server/internal/common/db"
+)
+
+type IRepo interface {
+	Create(ctx context.Context, receiver db.IModel) error
+	FindByConditions(ctx context.Context, model interface{}, conditions []clause.Expression) error
+	FindManyWithFilters(ctx context.Context, models interface{}, req db.FindManyWithFiltersRequester) error
+}
@@ -0,0 +1,53 @@
+package fxrate
+
+import (
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/validator"
+)
+
+type IServer interface {
+	Create(ctx *gin.Context, req *dto.CreateFxRateRequest) *dto.CreateFxRateResponse
+	List(ctx *gin.Context, req *dto.ListFxRateRequest) *dto.ListFxRateResponse
+}
+
+type Server struct {
+	core   ICore
+	policy policy.IPolicy
+}
+
+func NewServer(core ICore, policy policy.IPolicy) IServer {
+	return &Server{core: core, policy: policy}
+}
+
+func (s *Server) Create(ctx *gin.Context, req *dto.CreateFxRateRequest) *dto.CreateFxRateResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionFxRatesWrite); err != nil {
+		return &dto.CreateFxRateResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidFxRate(req, validator.CreateFxRateValidator); err != nil {
+		return &dto.CreateFxRateResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	rate := new(Rate)
+	rate.ApplyDto(req.FxRate)
+	if err := s.core.Create(ctx, rate); err != nil {
+		return &dto.CreateFxRateResponse{Base: dto.GetErrorResponse(common.ErrDBPersistError, err.Error())}
+	}
+	return &dto.CreateFxRateResponse{FxRate: rate.ToDto(), Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) List(ctx *gin.Context, req *dto.ListFxRateRequest) *dto.ListFxRateResponse {
+	if err := validator.NewValidFxRate(req, validator.ListFxRateValidator); err != nil {
+		return &dto.ListFxRateResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	rates, err := s.core.List(ctx, req)
+	if err != nil {
+		return &dto.ListFxRateResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	ratesDto := make([]*dto.FxRate, 0)
+	for _, rate := range *rates {
+		ratesDto = append(ratesDto, rate.ToDto())
+	}
+	return &dto.ListFxRateResponse{FxRates: ratesDto, Base: &dto.Base{Success: true}}
+}
@@ -0,0 +1,109 @@
+package fxrate_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/fxrate/mock"
+	"transaction-server/internal/policy"
+)
+
+type ServerTest struct {
+	mockCoreCtrl *gomock.Controller
+	core         *mock.MockICore
+	server       fxrate.IServer
+}
+
+func setupServerTest(t *testing.T) *ServerTest {
+	mockCoreCtrl := gomock.NewController(t)
+	mockCore := mock.NewMockICore(mockCoreCtrl)
+	server := fxrate.NewServer(mockCore, policy.NewPolicy(nil, policy.Config{}))
+	return &ServerTest{
+		mockCoreCtrl: mockCoreCtrl,
+		core:         mockCore,
+		server:       server,
+	}
+}
+
+func teardownServerTest(td *ServerTest) {
+	td.mockCoreCtrl.Finish()
+}
+
+func TestServer_Create_Success(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.CreateFxRateRequest{
+		FxRate: &dto.FxRate{
+			BaseCurrency:  "EUR",
+			QuoteCurrency: "USD",
+			Rate:          1.08,
+		},
+	}
+
+	td.core.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	resp := td.server.Create(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Equal(t, 1.08, resp.FxRate.Rate)
+}
+
+func TestServer_Create_ValidationFailed(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.CreateFxRateRequest{
+		FxRate: &dto.FxRate{
+			BaseCurrency:  "EUR",
+			QuoteCurrency: "EUR",
+			Rate:          -1,
+		},
+	}
+
+	resp := td.server.Create(ctx, req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_List_DBQueryError(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.ListFxRateRequest{BaseCurrency: "EUR"}
+
+	td.core.EXPECT().List(ctx, req).Return(nil, errors.New("DB error"))
+
+	resp := td.server.List(ctx, req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrDBQueryError, resp.Error.Code)
+}
+
+// asClient returns a context authenticated as a client with the role.
+func asClient(role string) *gin.Context {
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: role})
+	return ctx
+}
+
+func TestServer_Create_Forbidden(t *testing.T) {
+	for _, role := range []string{policy.RoleAgent, policy.RolePartner} {
+		t.Run(role, func(t *testing.T) {
+			td := setupServerTest(t)
+			defer teardownServerTest(td)
+
+			req := &dto.CreateFxRateRequest{FxRate: &dto.FxRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: 1.08}}
+			resp := td.server.Create(asClient(role), req)
+			assert.False(t, resp.Success)
+			assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pressly/goose"
+	"transaction-server/internal/common/db"
+)
+
+// DBCheck pings the database.
+func DBCheck(database *db.DB) Check {
+	return database.AliveContext
+}
+
+// MigrationsCheck checks that the database is migrated to at least the expected version.
+// A database migrated further, as during a rolling deploy, is compatible.
+func MigrationsCheck(database *db.DB, expected int64) Check {
+	return func(ctx context.Context) error {
+		version, err := migrationVersion(ctx, database)
+		if err != nil {
+			return err
+		}
+		if version < expected {
+			return fmt.Errorf("database is at migration %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
+
+// migrationVersion reads the version of the database from the goose table the way goose
+// does, without creating the table when it is missing: the latest migration applied and
+// not rolled back since.
+func migrationVersion(ctx context.Context, database *db.DB) (int64, error) {
+	rows, err := database.Instance(ctx).
+		Raw(fmt.Sprintf("SELECT version_id, is_applied FROM %s ORDER BY id DESC", goose.TableName())).Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the migration version: %w", err)
+	}
+	defer rows.Close()
+
+	rolledBack := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err = rows.Scan(&version, &applied); err != nil {
+			return 0, fmt.Errorf("failed to read the migration version: %w", err)
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read the migration version: %w", err)
+	}
+	return 0, nil
+}
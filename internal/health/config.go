@@ -0,0 +1,19 @@
+package health
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Timeout bounds each readiness check; it defaults to two seconds.
+	Timeout time.Duration
+}
+
+// Validate validates the timeout is not negative.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Timeout, validation.Min(time.Duration(0))),
+	)
+}
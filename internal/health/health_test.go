@@ -0,0 +1,101 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/health"
+)
+
+func setupDB(t *testing.T) *db.DB {
+	gDb, err := db.NewDb(&db.Config{ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1}},
+		db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).Exec(`CREATE TABLE goose_db_version (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version_id INTEGER NOT NULL,
+		is_applied INTEGER NOT NULL
+	)`).Error)
+	return gDb
+}
+
+func migrate(t *testing.T, gDb *db.DB, version int64, applied bool) {
+	require.NoError(t, gDb.Instance(context.Background()).
+		Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, ?)", version, applied).Error)
+}
+
+func TestChecker_Ready(t *testing.T) {
+	checker := health.NewChecker(health.Config{})
+	checker.Register("up", func(ctx context.Context) error { return nil })
+	checker.Register("down", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := checker.Ready(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["up"].Status)
+	assert.Equal(t, health.StatusUnavailable, report.Checks["down"].Status)
+	assert.Equal(t, "connection refused", report.Checks["down"].Error)
+}
+
+func TestChecker_Ready_Timeout(t *testing.T) {
+	checker := health.NewChecker(health.Config{Timeout: 10 * time.Millisecond})
+	release := make(chan struct{})
+	defer close(release)
+	checker.Register("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	report := checker.Ready(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.False(t, report.Ready())
+	assert.Equal(t, health.ErrTimeout.Error(), report.Checks["stuck"].Error)
+}
+
+func TestDBCheck(t *testing.T) {
+	gDb := setupDB(t)
+	assert.NoError(t, health.DBCheck(gDb)(context.Background()))
+
+	sqlDB, err := gDb.Instance(context.Background()).DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	assert.Error(t, health.DBCheck(gDb)(context.Background()))
+}
+
+func TestMigrationsCheck(t *testing.T) {
+	gDb := setupDB(t)
+	check := health.MigrationsCheck(gDb, 3)
+
+	assert.EqualError(t, check(context.Background()), "database is at migration 0, expected 3")
+
+	migrate(t, gDb, 1, true)
+	migrate(t, gDb, 2, true)
+	migrate(t, gDb, 3, true)
+	assert.NoError(t, check(context.Background()))
+
+	// Rolled back.
+	migrate(t, gDb, 3, false)
+	assert.EqualError(t, check(context.Background()), "database is at migration 2, expected 3")
+
+	// Migrated further.
+	migrate(t, gDb, 3, true)
+	migrate(t, gDb, 4, true)
+	assert.NoError(t, check(context.Background()))
+}
+
+func TestMigrationsCheck_NotMigrated(t *testing.T) {
+	gDb, err := db.NewDb(&db.Config{ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1}},
+		db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+
+	assert.Error(t, health.MigrationsCheck(gDb, 1)(context.Background()))
+}
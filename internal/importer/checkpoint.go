@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint records how far an import got, so it can resume after a crash.
+// It is saved after every batch; a batch that was in flight when the process died
+// is imported again on resume.
+type Checkpoint struct {
+	path string
+	// Input is the file being imported.
+	Input string `json:"input"`
+	// Records is the number of records processed so far.
+	Records int `json:"records"`
+	// Imported is the number of records created so far.
+	Imported int `json:"imported"`
+	// Rejected is the number of records written to the reject file so far.
+	Rejected int `json:"rejected"`
+}
+
+// LoadCheckpoint reads the checkpoint at path, or starts a new one when none exists.
+// It fails when the checkpoint belongs to another input file.
+func LoadCheckpoint(path, input string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{path: path, Input: input}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if checkpoint.Input != input {
+		return nil, fmt.Errorf("checkpoint %s belongs to %s, not %s", path, checkpoint.Input, input)
+	}
+	return checkpoint, nil
+}
+
+// IsResumed reports whether the checkpoint was loaded from an earlier run.
+func (c *Checkpoint) IsResumed() bool {
+	return c.Records > 0
+}
+
+// Save writes the checkpoint atomically.
+func (c *Checkpoint) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,272 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"transaction-server/internal/account"
+	"transaction-server/internal/common/db/datatype"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/transaction"
+	"transaction-server/internal/validator"
+)
+
+const (
+	KindAccounts     = "accounts"
+	KindTransactions = "transactions"
+)
+
+// Config holds the import settings.
+type Config struct {
+	// Kind is either KindAccounts or KindTransactions.
+	Kind string
+	// BatchSize is the number of records created, and checkpointed, together.
+	BatchSize int
+}
+
+// Importer creates the records of an import file through the core layer.
+//
+// Records keep their id when one is given, so transactions can refer to imported accounts
+// and a batch imported again after a crash is rejected as a duplicate instead of created twice.
+// Transactions are created in file order and discharge open debits like live ones do,
+// so the file should be sorted by event date.
+type Importer struct {
+	accountCore     account.ICore
+	transactionCore transaction.ICore
+	config          Config
+}
+
+func New(accountCore account.ICore, transactionCore transaction.ICore, config Config) (*Importer, error) {
+	if config.Kind != KindAccounts && config.Kind != KindTransactions {
+		return nil, fmt.Errorf("unsupported kind %q", config.Kind)
+	}
+	if config.BatchSize < 1 {
+		return nil, errors.New("batch size must be positive")
+	}
+	return &Importer{accountCore: accountCore, transactionCore: transactionCore, config: config}, nil
+}
+
+// row is a validated record waiting to be created.
+type row struct {
+	record      *Record
+	account     *dto.Account
+	transaction *dto.Transaction
+	eventDate   int64
+}
+
+// Run imports the records after the checkpoint, writing rejected records to rejects and
+// saving the checkpoint after every batch.
+func (i *Importer) Run(ctx context.Context, reader Reader, rejects RejectWriter, checkpoint *Checkpoint) error {
+	batch := make([]*row, 0, i.config.BatchSize)
+	processed := checkpoint.Records
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if record.Number <= checkpoint.Records {
+			continue
+		}
+		processed = record.Number
+
+		r, err := i.decode(record)
+		if err != nil {
+			if err := rejects.Reject(record, err.Error()); err != nil {
+				return err
+			}
+			checkpoint.Rejected++
+		} else {
+			batch = append(batch, r)
+		}
+		if len(batch) == i.config.BatchSize {
+			if err := i.flush(ctx, batch, rejects, checkpoint, processed); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return i.flush(ctx, batch, rejects, checkpoint, processed)
+}
+
+// flush creates the batch, then saves the rejects and the checkpoint up to the processed record.
+func (i *Importer) flush(ctx context.Context, batch []*row, rejects RejectWriter, checkpoint *Checkpoint, processed int) error {
+	var errs []error
+	if i.config.Kind == KindAccounts {
+		errs = i.createAccounts(ctx, batch)
+	} else {
+		errs = i.createTransactions(ctx, batch)
+	}
+	for j, err := range errs {
+		if err != nil {
+			if err := rejects.Reject(batch[j].record, err.Error()); err != nil {
+				return err
+			}
+			checkpoint.Rejected++
+			continue
+		}
+		checkpoint.Imported++
+	}
+	if err := rejects.Flush(); err != nil {
+		return err
+	}
+	if processed == checkpoint.Records {
+		return nil
+	}
+	checkpoint.Records = processed
+	return checkpoint.Save()
+}
+
+func (i *Importer) createAccounts(ctx context.Context, batch []*row) []error {
+	errs := make([]error, len(batch))
+	for j, r := range batch {
+		model := new(account.Account)
+		model.ApplyDto(r.account)
+		model.ID = r.account.ID
+		errs[j] = i.accountCore.Create(ctx, model)
+	}
+	return errs
+}
+
+// createTransactions creates the batch atomically and, if that fails, transaction by
+// transaction so only the failing ones are rejected.
+func (i *Importer) createTransactions(ctx context.Context, batch []*row) []error {
+	if len(batch) == 0 {
+		return nil
+	}
+	errs := i.transactionCore.CreateBatch(ctx, newTransactions(batch), true)
+	for _, err := range errs {
+		if err != nil {
+			return i.transactionCore.CreateBatch(ctx, newTransactions(batch), false)
+		}
+	}
+	return errs
+}
+
+func newTransactions(batch []*row) []*transaction.Transaction {
+	models := make([]*transaction.Transaction, len(batch))
+	for j, r := range batch {
+		model := new(transaction.Transaction)
+		model.ApplyDto(r.transaction)
+		model.ID = r.transaction.ID
+		if r.eventDate != 0 {
+			model.EventDate = r.eventDate
+		}
+		models[j] = model
+	}
+	return models
+}
+
+// decode parses and validates a record with the same rules as the API.
+func (i *Importer) decode(record *Record) (*row, error) {
+	if record.Err != nil {
+		return nil, record.Err
+	}
+	if i.config.Kind == KindAccounts {
+		acc, err := decodeAccount(record)
+		if err != nil {
+			return nil, err
+		}
+		if err := validator.NewValidAccount(&dto.CreateAccountRequest{Account: acc}, validator.CreateAccountValidator); err != nil {
+			return nil, err
+		}
+		if err := validateID(acc.ID); err != nil {
+			return nil, err
+		}
+		return &row{record: record, account: acc}, nil
+	}
+
+	txn, err := decodeTransaction(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.NewValidTransaction(&dto.CreateTransactionRequest{Transaction: txn}, validator.CreateTransactionValidator); err != nil {
+		return nil, err
+	}
+	if err := validateID(txn.ID); err != nil {
+		return nil, err
+	}
+	eventDate, err := parseEventDate(txn.EventDate)
+	if err != nil {
+		return nil, err
+	}
+	return &row{record: record, transaction: txn, eventDate: eventDate}, nil
+}
+
+func decodeAccount(record *Record) (*dto.Account, error) {
+	acc := new(dto.Account)
+	if record.JSON != nil {
+		if err := json.Unmarshal(record.JSON, acc); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		return acc, nil
+	}
+	acc.ID = record.Fields["id"]
+	acc.Name = record.Fields["name"]
+	acc.DocumentNumber = record.Fields["document_number"]
+	acc.Currency = record.Fields["currency"]
+	return acc, nil
+}
+
+func decodeTransaction(record *Record) (*dto.Transaction, error) {
+	txn := new(dto.Transaction)
+	if record.JSON != nil {
+		if err := json.Unmarshal(record.JSON, txn); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		return txn, nil
+	}
+	txn.ID = record.Fields["id"]
+	txn.AccountID = record.Fields["account_id"]
+	txn.OperationType = record.Fields["operation_type"]
+	txn.Currency = record.Fields["currency"]
+	txn.EventDate = record.Fields["event_date"]
+	if value := record.Fields["amount"]; value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("amount: %q is not a number.", value)
+		}
+		txn.Amount = amount
+	}
+	if value := record.Fields["convert_currency"]; value != "" {
+		convert, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("convert_currency: %q is not a boolean.", value)
+		}
+		txn.ConvertCurrency = convert
+	}
+	return txn, nil
+}
+
+func validateID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if err := datatype.IsUUID(id); err != nil {
+		return fmt.Errorf("id: %w", err)
+	}
+	return nil
+}
+
+// parseEventDate accepts a unix timestamp, an RFC 3339 time or a YYYY-MM-DD date (UTC).
+// An empty event date means the time of the import.
+func parseEventDate(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return unix, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("event_date: %q is not a unix timestamp, RFC 3339 time or YYYY-MM-DD date.", value)
+}
@@ -0,0 +1,161 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"transaction-server/internal/account"
+	accountmock "transaction-server/internal/account/mock"
+	"transaction-server/internal/importer"
+	"transaction-server/internal/transaction"
+	"transaction-server/internal/transaction/mock"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type importerTest struct {
+	ctrl            *gomock.Controller
+	accountCore     *accountmock.MockICore
+	transactionCore *mock.MockICore
+	checkpointPath  string
+}
+
+func setupImporterTest(t *testing.T) *importerTest {
+	ctrl := gomock.NewController(t)
+	return &importerTest{
+		ctrl:            ctrl,
+		accountCore:     accountmock.NewMockICore(ctrl),
+		transactionCore: mock.NewMockICore(ctrl),
+		checkpointPath:  filepath.Join(t.TempDir(), "input.checkpoint"),
+	}
+}
+
+func (td *importerTest) run(t *testing.T, kind, format, input string, batchSize int, rejects *bytes.Buffer) *importer.Checkpoint {
+	imp, err := importer.New(td.accountCore, td.transactionCore, importer.Config{Kind: kind, BatchSize: batchSize})
+	require.NoError(t, err)
+	reader, err := importer.NewReader(strings.NewReader(input), format)
+	require.NoError(t, err)
+	var header []string
+	if h, ok := reader.(interface{ Header() []string }); ok {
+		header = h.Header()
+	}
+	writer, err := importer.NewRejectWriter(rejects, format, header, rejects.Len() == 0)
+	require.NoError(t, err)
+	cp, err := importer.LoadCheckpoint(td.checkpointPath, "input")
+	require.NoError(t, err)
+	require.NoError(t, imp.Run(context.Background(), reader, writer, cp))
+	return cp
+}
+
+func TestImporter_CSV_Transactions_Rejects_Invalid_Rows(t *testing.T) {
+	td := setupImporterTest(t)
+	defer td.ctrl.Finish()
+
+	input := "account_id,operation_type,amount,event_date\n" +
+		"0b0e0000000000,Normal_Purchase,100,2019-03-01\n" +
+		"0b0e0000000000,Unknown,100,2019-03-02\n" +
+		"0b0e0000000000,Credit_Voucher,abc,2019-03-03\n" +
+		"0b0e0000000000,Credit_Voucher,60,1551657600\n"
+
+	td.transactionCore.EXPECT().CreateBatch(gomock.Any(), gomock.Any(), true).DoAndReturn(
+		func(ctx context.Context, models []*transaction.Transaction, atomic bool) []error {
+			require.Len(t, models, 2)
+			assert.Equal(t, float64(-100), models[0].Amount)
+			assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), models[0].EventDate)
+			assert.Equal(t, int64(1551657600), models[1].EventDate)
+			return []error{nil, nil}
+		},
+	)
+
+	rejects := new(bytes.Buffer)
+	cp := td.run(t, importer.KindTransactions, importer.FormatCSV, input, 10, rejects)
+
+	assert.Equal(t, 4, cp.Records)
+	assert.Equal(t, 2, cp.Imported)
+	assert.Equal(t, 2, cp.Rejected)
+	lines := strings.Split(strings.TrimSpace(rejects.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "account_id,operation_type,amount,event_date,reject_reason", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "0b0e0000000000,Unknown,100,2019-03-02,"))
+	assert.Contains(t, lines[2], "amount")
+}
+
+func TestImporter_Falls_Back_To_Each_Transaction_When_Batch_Fails(t *testing.T) {
+	td := setupImporterTest(t)
+	defer td.ctrl.Finish()
+
+	input := `{"account_id":"0b0e0000000000","operation_type":"Withdraw","amount":10}
+{"account_id":"0c0e0000000000","operation_type":"Withdraw","amount":20}
+`
+	failure := errors.New("account not found")
+	td.transactionCore.EXPECT().CreateBatch(gomock.Any(), gomock.Len(2), true).Return([]error{transaction.ErrBatchAborted, failure})
+	td.transactionCore.EXPECT().CreateBatch(gomock.Any(), gomock.Len(2), false).Return([]error{nil, failure})
+
+	rejects := new(bytes.Buffer)
+	cp := td.run(t, importer.KindTransactions, importer.FormatNDJSON, input, 10, rejects)
+
+	assert.Equal(t, 1, cp.Imported)
+	assert.Equal(t, 1, cp.Rejected)
+	assert.JSONEq(t,
+		`{"record":2,"reason":"account not found","data":{"account_id":"0c0e0000000000","operation_type":"Withdraw","amount":20}}`,
+		rejects.String(),
+	)
+}
+
+func TestImporter_Resumes_From_Checkpoint(t *testing.T) {
+	td := setupImporterTest(t)
+	defer td.ctrl.Finish()
+
+	input := `{"id":"a00000000000001","name":"one","document_number":"1"}
+{"id":"a0000000000002","name":"two","document_number":"2"}
+
+{"id":"a0000000000003","name":"three","document_number":"3"}
+{"id":"a0000000000004","name":"four","document_number":"4"}
+`
+	created := make([]string, 0)
+	create := func(ctx context.Context, model *account.Account) error {
+		created = append(created, model.ID)
+		return nil
+	}
+	// The first run crashes while creating the second batch.
+	td.accountCore.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(create).Times(2)
+	td.accountCore.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, model *account.Account) error {
+			panic("crash")
+		},
+	)
+	rejects := new(bytes.Buffer)
+	assert.Panics(t, func() { td.run(t, importer.KindAccounts, importer.FormatNDJSON, input, 2, rejects) })
+
+	// Record 1 has an invalid id and records 2 and 3 were checkpointed, so the resumed run starts at record 4.
+	td.accountCore.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(create)
+	cp := td.run(t, importer.KindAccounts, importer.FormatNDJSON, input, 2, rejects)
+
+	assert.Equal(t, []string{"a0000000000002", "a0000000000003", "a0000000000004"}, created)
+	assert.Equal(t, 4, cp.Records)
+	assert.Equal(t, 3, cp.Imported)
+	assert.Equal(t, 1, cp.Rejected)
+	assert.Contains(t, rejects.String(), `"record":1`)
+}
+
+func TestLoadCheckpoint_Rejects_Other_Input(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cp, err := importer.LoadCheckpoint(path, "first.csv")
+	require.NoError(t, err)
+	cp.Records = 3
+	require.NoError(t, cp.Save())
+
+	_, err = importer.LoadCheckpoint(path, "second.csv")
+	assert.Error(t, err)
+
+	cp, err = importer.LoadCheckpoint(path, "first.csv")
+	require.NoError(t, err)
+	assert.True(t, cp.IsResumed())
+	assert.Equal(t, 3, cp.Records)
+}
@@ -0,0 +1,116 @@
+// Package importer loads historical accounts and transactions from CSV or NDJSON files.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Record is a single row read from an import file.
+type Record struct {
+	// Number is the 1-based position of the record in the file, not counting the CSV header.
+	Number int
+	// Values holds the CSV values in column order.
+	Values []string
+	// Fields holds the CSV values by column name.
+	Fields map[string]string
+	// JSON holds the raw NDJSON line.
+	JSON []byte
+	// Err is set when the row could not be parsed; the record is rejected.
+	Err error
+}
+
+// Reader reads records one at a time and returns io.EOF after the last one.
+type Reader interface {
+	Read() (*Record, error)
+}
+
+// NewReader returns a Reader for the given format. CSV files must start with a header row.
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+	number int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv header is missing")
+		}
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	return &csvReader{reader: reader, header: header}, nil
+}
+
+// Header returns the column names of the file.
+func (c *csvReader) Header() []string {
+	return c.header
+}
+
+func (c *csvReader) Read() (*Record, error) {
+	values, err := c.reader.Read()
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		return nil, err
+	}
+	c.number++
+	record := &Record{Number: c.number, Values: values, Err: err}
+	if err == nil {
+		record.Fields = make(map[string]string, len(values))
+		for i, value := range values {
+			record.Fields[c.header[i]] = value
+		}
+	}
+	return record, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	number  int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (n *ndjsonReader) Read() (*Record, error) {
+	for n.scanner.Scan() {
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n.number++
+		return &Record{Number: n.number, JSON: append([]byte(nil), line...)}, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// RejectReasonColumn is the column added to rejected CSV rows.
+const RejectReasonColumn = "reject_reason"
+
+// RejectWriter writes rejected records along with the reason they were rejected.
+type RejectWriter interface {
+	Reject(record *Record, reason string) error
+	Flush() error
+}
+
+// NewRejectWriter returns a RejectWriter in the format of the input file. CSV rejects keep
+// the original columns and add RejectReasonColumn, so the file can be fixed and imported again.
+// The header is written only when writeHeader is set.
+func NewRejectWriter(w io.Writer, format string, header []string, writeHeader bool) (RejectWriter, error) {
+	if format == FormatCSV {
+		writer := csv.NewWriter(w)
+		if writeHeader {
+			if err := writer.Write(append(append([]string(nil), header...), RejectReasonColumn)); err != nil {
+				return nil, err
+			}
+		}
+		return &csvRejectWriter{writer: writer}, nil
+	}
+	return &ndjsonRejectWriter{writer: bufio.NewWriter(w)}, nil
+}
+
+type csvRejectWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvRejectWriter) Reject(record *Record, reason string) error {
+	return c.writer.Write(append(append([]string(nil), record.Values...), reason))
+}
+
+func (c *csvRejectWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonRejectWriter struct {
+	writer *bufio.Writer
+}
+
+type ndjsonReject struct {
+	Record int         `json:"record"`
+	Reason string      `json:"reason"`
+	Data   interface{} `json:"data"`
+}
+
+func (n *ndjsonRejectWriter) Reject(record *Record, reason string) error {
+	reject := ndjsonReject{Record: record.Number, Reason: reason, Data: string(record.JSON)}
+	if json.Valid(record.JSON) {
+		reject.Data = json.RawMessage(record.JSON)
+	}
+	data, err := json.Marshal(reject)
+	if err != nil {
+		return err
+	}
+	if _, err := n.writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (n *ndjsonRejectWriter) Flush() error {
+	return n.writer.Flush()
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+var (
+	queryDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of the SQL statements run by gorm, by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	queryErrors = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "SQL statements run by gorm that failed, by operation and table. Missing records are not failures.",
+	}, []string{"operation", "table"})
+	transactionRetries = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_retries_total",
+		Help:      "DB transactions run again after a deadlock or a serialization failure, by reason.",
+	}, []string{"reason"})
+)
+
+// ObserveDBTransactionRetry counts a retried DB transaction.
+func ObserveDBTransactionRetry(reason string) {
+	transactionRetries.WithLabelValues(reason).Inc()
+}
+
+// GormPlugin observes the latency and failures of the statements run by gorm.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("metrics:before_create", p.start),
+		callback.Create().After("gorm:create").Register("metrics:after_create", p.observe("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", p.start),
+		callback.Query().After("gorm:query").Register("metrics:after_query", p.observe("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", p.start),
+		callback.Update().After("gorm:update").Register("metrics:after_update", p.observe("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", p.start),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", p.observe("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", p.start),
+		callback.Row().After("gorm:row").Register("metrics:after_row", p.observe("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", p.start),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", p.observe("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (GormPlugin) start(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func (GormPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		queryDuration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package metrics exposes the metrics of the application to Prometheus: HTTP requests,
+// created transactions and discharged balances, SQL queries, DB transaction retries and the
+// database pool.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "transaction_server"
+
+// Registry holds the collectors of the application, along with the Go runtime and process ones.
+var Registry = prometheus.NewRegistry()
+
+var (
+	transactionsCreated = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Transactions created, by operation type.",
+	}, []string{"operation_type"})
+	dischargeAmount = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "discharge_amount",
+		Help:      "Amounts of the open balances discharged by credits, in the currency of the balance.",
+		Buckets:   prometheus.ExponentialBuckets(1, 10, 7),
+	}, []string{"currency"})
+)
+
+func init() {
+	Registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveTransactionCreated counts a created transaction.
+func ObserveTransactionCreated(operationType string) {
+	transactionsCreated.WithLabelValues(operationType).Inc()
+}
+
+// ObserveDischarge records the amount of a balance discharged.
+func ObserveDischarge(currency string, amount float64) {
+	dischargeAmount.WithLabelValues(currency).Observe(amount)
+}
+
+// RegisterDBStats exposes the connection pool stats of the database under the name.
+func RegisterDBStats(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
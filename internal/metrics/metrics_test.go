@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/metrics"
+)
+
+type row struct {
+	ID   int
+	Name string
+}
+
+func TestHandler_Scrape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/accounts/:accountId", func(ctx *gin.Context) { ctx.Status(http.StatusNotFound) })
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	gDb, err := db.NewDb(&db.Config{ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1}},
+		db.Dialector(sqlite.Open("file::memory:")), db.Plugins(metrics.GormPlugin{}))
+	require.NoError(t, err)
+	sqlDB, err := gDb.GetInstance(context.Background()).DB()
+	require.NoError(t, err)
+	require.NoError(t, metrics.RegisterDBStats("test", sqlDB))
+	instance := gDb.Instance(context.Background())
+	require.NoError(t, instance.AutoMigrate(&row{}))
+	require.NoError(t, instance.Create(&row{Name: "a"}).Error)
+	assert.Error(t, instance.Exec("SELECT * FROM missing").Error)
+
+	metrics.ObserveTransactionCreated("Credit_Voucher")
+	metrics.ObserveDischarge("BRL", 50)
+	metrics.ObserveDBTransactionRetry(db.RetryReasonDeadlock)
+
+	for _, path := range []string{"/accounts/0b0e0000000000", "/accounts/0b0e0000000001", "/missing"} {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	scraped := string(body)
+
+	for _, line := range []string{
+		`transaction_server_http_requests_total{method="GET",route="/accounts/:accountId",status="404"} 2`,
+		`transaction_server_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`transaction_server_http_request_duration_seconds_count{method="GET",route="/accounts/:accountId",status="404"} 2`,
+		`transaction_server_transactions_created_total{operation_type="Credit_Voucher"} 1`,
+		`transaction_server_discharge_amount_bucket{currency="BRL",le="100"} 1`,
+		`transaction_server_db_query_duration_seconds_count{operation="create",table="rows"} 1`,
+		`transaction_server_db_query_errors_total{operation="raw",table="unknown"} 1`,
+		`transaction_server_db_transaction_retries_total{reason="deadlock"} 1`,
+		`go_sql_max_open_connections{db_name="test"} 1`,
+	} {
+		assert.Contains(t, scraped, line)
+	}
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Publisher names accepted in Config.
+const (
+	PublisherStdout = "stdout"
+	PublisherFile   = "file"
+)
+
+type Config struct {
+	// Publisher is where the relay delivers events: stdout or file. Empty disables the relay;
+	// events are still recorded in the outbox.
+	Publisher string
+	// FilePath is the file the file publisher appends events to.
+	FilePath string
+	// PollInterval is how often the relay checks the outbox when it is drained.
+	PollInterval time.Duration
+	// BatchSize is the number of events read from the outbox at a time.
+	BatchSize int
+	// LeaseDuration is how long the relay publishing the outbox holds it without renewing
+	// its lease, before a relay in another instance takes over. It must outlast publishing
+	// a batch, or its events may be published twice.
+	LeaseDuration time.Duration
+	// InitialBackoff is how long an event failing to publish waits before it is retried,
+	// along with the later events of its aggregate; each later delay doubles.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between the attempts of an event.
+	MaxBackoff time.Duration
+}
+
+// Validate validates the publisher and its file, and the delays between attempts.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Publisher, validation.In(PublisherStdout, PublisherFile)),
+		validation.Field(&c.FilePath, validation.Required.When(c.Publisher == PublisherFile)),
+		validation.Field(&c.InitialBackoff, validation.Min(time.Duration(0))),
+		validation.Field(&c.MaxBackoff, validation.Min(c.InitialBackoff)),
+	)
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"context"
+	"transaction-server/internal/auth"
+)
+
+type ICore interface {
+	// Record writes the events to the outbox. Called within a Repo.Transaction, the events
+	// are committed or rolled back with the rest of it. Events are attributed to the
+	// authenticated client of the context, if any.
+	Record(ctx context.Context, events ...*Event) error
+}
+
+type Core struct {
+	repo IRepo
+}
+
+func NewCore(repo IRepo) *Core {
+	return &Core{repo: repo}
+}
+
+func (c *Core) Record(ctx context.Context, events ...*Event) error {
+	identity, _ := auth.IdentityFromContext(ctx)
+	for _, event := range events {
+		if identity != nil && event.Actor == "" {
+			event.Actor = identity.ClientID
+		}
+		if err := c.repo.Create(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
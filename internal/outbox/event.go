@@ -0,0 +1,111 @@
+// Package outbox records domain events in the outbox table, in the same DB transaction
+// as the change they describe, and relays them to a publisher.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+	"transaction-server/internal/common/db"
+)
+
+// Event types.
+const (
+	TypeAccountCreated     = "account.created"
+	TypeTransactionCreated = "transaction.created"
+	TypeBalanceDischarged  = "balance.discharged"
+)
+
+// Types lists the event types.
+var Types = []string{TypeAccountCreated, TypeTransactionCreated, TypeBalanceDischarged}
+
+// Aggregate types.
+const (
+	AggregateAccount     = "account"
+	AggregateTransaction = "transaction"
+)
+
+// Event is a domain event waiting in the outbox to be published.
+type Event struct {
+	db.Model             // Embedding the common database model
+	Type          string `json:"type"`               // Type of the event, one of the Type constants
+	AggregateType string `json:"aggregate_type"`     // Type of the entity the event is about
+	AggregateID   string `json:"aggregate_id"`       // ID of the entity the event is about
+	Payload       string `json:"payload"`            // JSON payload of the event
+	OccurredAt    int64  `json:"occurred_at"`        // Time the event occurred (Unix nanoseconds)
+	PublishedAt   int64  `json:"published_at"`       // Time the event was published (Unix timestamp), 0 while pending
+	Attempts      int    `json:"attempts"`           // Number of failed publish attempts
+	LastError     string `json:"last_error"`         // Error of the last failed publish attempt
+	NextAttemptAt int64  `json:"next_attempt_at"`    // Time before which a failed event is not retried (Unix nanoseconds)
+	Actor         string `json:"actor"`              // ID of the API client that caused the event, if any
+	Sequence      int64  `json:"sequence" gorm:"->"` // Order the event was inserted in, assigned by the database, orders the outbox
+}
+
+// TableName returns the name of the database table for the Event entity.
+func (e *Event) TableName() string {
+	return "outbox_events"
+}
+
+// EntityName returns the name of the entity.
+func (e *Event) EntityName() string {
+	return "outbox_event"
+}
+
+// SetDefaults sets default values for the Event entity.
+func (e *Event) SetDefaults() error {
+	if e.OccurredAt == 0 {
+		e.OccurredAt = time.Now().UnixNano()
+	}
+	return nil
+}
+
+// NewEvent returns an event about the aggregate with payload marshalled to JSON.
+func NewEvent(eventType, aggregateType, aggregateID string, payload interface{}) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		OccurredAt:    time.Now().UnixNano(),
+	}, nil
+}
+
+// Message is the published form of an event.
+type Message struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	TenantID      string          `json:"tenant_id,omitempty"`
+	Actor         string          `json:"actor,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// ToMessage converts the event to the message published for it.
+func (e *Event) ToMessage() *Message {
+	return &Message{
+		ID:            e.ID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		OccurredAt:    time.Unix(0, e.OccurredAt).UTC(),
+		TenantID:      e.TenantID,
+		Actor:         e.Actor,
+		Payload:       json.RawMessage(e.Payload),
+	}
+}
+
+// BalanceDischarged is the payload of a TypeBalanceDischarged event: a debit whose
+// open balance was reduced by a later credit.
+type BalanceDischarged struct {
+	TransactionID    string  `json:"transaction_id"`
+	AccountID        string  `json:"account_id"`
+	DischargedBy     string  `json:"discharged_by"`
+	Amount           float64 `json:"amount"`
+	Currency         string  `json:"currency"`
+	RemainingBalance float64 `json:"remaining_balance"`
+}
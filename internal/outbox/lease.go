@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// relayLeaseID is the ID of the lease held by the relay publishing the outbox.
+const relayLeaseID = "relay"
+
+// Lease is held by one relay at a time, for the events to be published once and in order
+// when relays run in every instance. The relays not holding it stand by, taking it over
+// once it expires.
+type Lease struct {
+	ID string `gorm:"primaryKey"`
+	// Holder is the ID of the relay holding the lease.
+	Holder string
+	// ExpiresAt is when the lease expires unless renewed, in Unix nanoseconds.
+	ExpiresAt int64
+}
+
+func (Lease) TableName() string {
+	return "outbox_leases"
+}
+
+// acquireLease takes or renews the lease of the relay and reports whether it holds it.
+func (r *Relay) acquireLease(ctx context.Context, now time.Time) (bool, error) {
+	expiresAt := now.Add(r.config.LeaseDuration).UnixNano()
+	q := r.repo.DBInstance(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Lease{ID: relayLeaseID, Holder: r.id, ExpiresAt: expiresAt})
+	if q.Error != nil {
+		return false, q.Error
+	}
+	if q.RowsAffected == 1 {
+		return true, nil
+	}
+	q = r.repo.DBInstance(ctx).Model(&Lease{}).
+		Where("id = ? AND (holder = ? OR expires_at < ?)", relayLeaseID, r.id, now.UnixNano()).
+		Updates(map[string]interface{}{"holder": r.id, "expires_at": expiresAt})
+	return q.RowsAffected == 1, q.Error
+}
+
+// releaseLease gives up the lease if the relay holds it, for another to take over at once.
+func (r *Relay) releaseLease(ctx context.Context) error {
+	return r.repo.DBInstance(ctx).Model(&Lease{}).
+		Where("id = ? AND holder = ?", relayLeaseID, r.id).
+		Update("expires_at", 0).Error
+}
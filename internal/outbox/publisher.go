@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Publisher delivers outbox messages to downstream systems. The relay publishes messages
+// one at a time in outbox order and retries a message until Publish succeeds, so
+// delivery is at least once.
+type Publisher interface {
+	Publish(ctx context.Context, message *Message) error
+}
+
+// NewPublisher returns the publisher named by the config, or nil when none is configured.
+// The returned closer releases the publisher's resources.
+func NewPublisher(config Config) (Publisher, io.Closer, error) {
+	switch config.Publisher {
+	case "":
+		return nil, io.NopCloser(nil), nil
+	case PublisherStdout:
+		return NewWriterPublisher(os.Stdout), io.NopCloser(nil), nil
+	case PublisherFile:
+		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewWriterPublisher(file), file, nil
+	}
+	return nil, nil, fmt.Errorf("unknown outbox publisher %q", config.Publisher)
+}
+
+// WriterPublisher writes each message to a writer as a line of JSON.
+type WriterPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewWriterPublisher(writer io.Writer) *WriterPublisher {
+	return &WriterPublisher{writer: writer}
+}
+
+func (w *WriterPublisher) Publish(ctx context.Context, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.writer.Write(append(data, '\n'))
+	return err
+}
+
+// MemoryPublisher keeps published messages in memory, for tests.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []*Message
+	err      error
+	failing  map[string]error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (m *MemoryPublisher) Publish(ctx context.Context, message *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.failing[message.AggregateID]; err != nil {
+		return err
+	}
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// FailWith makes Publish fail with err until it is called again with nil.
+func (m *MemoryPublisher) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// FailAggregateWith makes Publish fail with err for the messages about the aggregate until
+// it is called again with nil.
+func (m *MemoryPublisher) FailAggregateWith(aggregateID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failing == nil {
+		m.failing = make(map[string]error)
+	}
+	m.failing[aggregateID] = err
+}
+
+// Messages returns the messages published so far.
+func (m *MemoryPublisher) Messages() []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Message(nil), m.messages...)
+}
+
+// MultiPublisher publishes each message to several publishers in turn. A failure stops the
+// message from reaching the later publishers and the relay retries it with all of them,
+// so each publisher must tolerate receiving a message more than once.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher returns a publisher fanning out to the non-nil publishers, or nil when
+// there are none.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	multi := &MultiPublisher{}
+	for _, publisher := range publishers {
+		if publisher != nil {
+			multi.publishers = append(multi.publishers, publisher)
+		}
+	}
+	switch len(multi.publishers) {
+	case 0:
+		return nil
+	case 1:
+		return multi.publishers[0]
+	}
+	return multi
+}
+
+func (m *MultiPublisher) Publish(ctx context.Context, message *Message) error {
+	for _, publisher := range m.publishers {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
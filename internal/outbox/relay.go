@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"transaction-server/internal/common/db"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Relay delivers the events of the outbox to a publisher in the order they were recorded.
+// Relays may run in every instance: only the one holding the lease publishes.
+//
+// The order holds between the events of an aggregate, which are recorded by transactions
+// writing the aggregate, one after the other. Events of different aggregates recorded by
+// concurrent transactions may be published in another order than they were committed in.
+type Relay struct {
+	id        string
+	repo      IRepo
+	publisher Publisher
+	config    Config
+}
+
+func NewRelay(repo IRepo, publisher Publisher, config Config) *Relay {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = 30 * time.Second
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	return &Relay{id: uuid.NewString(), repo: repo, publisher: publisher, config: config}
+}
+
+// Run relays events until ctx is done, polling the outbox once it is drained. A batch
+// being relayed when ctx is done is finished first, so its events are not published
+// without being marked as such, then the lease is released.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	defer func() {
+		if err := r.releaseLease(db.WithPrimary(context.WithoutCancel(ctx))); err != nil {
+			slog.ErrorContext(ctx, "failed to release the outbox relay lease", "error", err)
+		}
+	}()
+	for {
+		published, err := r.RelayOnce(context.WithoutCancel(ctx))
+		if err != nil {
+			slog.ErrorContext(ctx, "outbox relay failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		// A full batch means more events are likely pending.
+		if err == nil && published == r.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes the oldest pending events and returns how many were published,
+// none unless the relay holds the lease. An event failing to publish is retried after a
+// backoff, recorded on the event, and the later events of its aggregate wait for it, so
+// they are never published out of order; the events of other aggregates are published
+// meanwhile. The errors of the failed events are returned. Events are read from the
+// primary, as a replica lagging behind would return events already published.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	ctx = db.WithPrimary(ctx)
+	now := time.Now()
+	if leased, err := r.acquireLease(ctx, now); err != nil || !leased {
+		return 0, err
+	}
+	events := make([]*Event, 0)
+	request := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{Limit: uint32(r.config.BatchSize)},
+		Conditions: []clause.Expression{
+			clause.Eq{Column: "published_at", Value: 0},
+			// Neither an event waiting to be retried nor the later events of its aggregate.
+			clause.Expr{SQL: `NOT EXISTS (SELECT 1 FROM outbox_events waiting
+				WHERE waiting.aggregate_type = outbox_events.aggregate_type
+				AND waiting.aggregate_id = outbox_events.aggregate_id
+				AND waiting.published_at = 0 AND waiting.next_attempt_at > ?
+				AND waiting.sequence <= outbox_events.sequence)`, Vars: []interface{}{now.UnixNano()}},
+		},
+		OrderBy: clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "sequence"}}}},
+	}
+	if err := r.repo.FindManyWithFilters(ctx, &events, request); err != nil {
+		return 0, err
+	}
+	published := 0
+	failed := make(map[aggregate]bool)
+	var errs []error
+	for _, event := range events {
+		key := aggregate{event.AggregateType, event.AggregateID}
+		if failed[key] {
+			continue
+		}
+		if err := r.publisher.Publish(ctx, event.ToMessage()); err != nil {
+			failed[key] = true
+			errs = append(errs, fmt.Errorf("publishing outbox event %s: %w", event.ID, err))
+			event.Attempts++
+			event.LastError = err.Error()
+			event.NextAttemptAt = now.Add(r.Backoff(event.Attempts)).UnixNano()
+			if err := r.repo.Update(ctx, event, "attempts", "last_error", "next_attempt_at"); err != nil {
+				return published, err
+			}
+			continue
+		}
+		event.PublishedAt = time.Now().Unix()
+		if err := r.repo.Update(ctx, event, "published_at"); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, errors.Join(errs...)
+}
+
+// aggregate identifies the entity events are about.
+type aggregate struct {
+	Type string
+	ID   string
+}
+
+// Backoff returns the delay before retrying an event after its attempts failed.
+func (r *Relay) Backoff(attempts int) time.Duration {
+	backoff := r.config.InitialBackoff
+	for i := 1; i < attempts && backoff < r.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.config.MaxBackoff {
+		backoff = r.config.MaxBackoff
+	}
+	return backoff
+}
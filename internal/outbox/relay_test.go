@@ -0,0 +1,260 @@
+package outbox_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/outbox"
+)
+
+// setupRepo returns a repo over a fresh in-memory database holding the outbox table.
+func setupRepo(t *testing.T) *db.Repo {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&outbox.Event{}, &outbox.Lease{}))
+	// SQLite only auto-increments primary keys: number the events as AUTO_INCREMENT does.
+	require.NoError(t, gDb.Instance(context.Background()).Exec(`CREATE TRIGGER outbox_events_sequence
+		AFTER INSERT ON outbox_events BEGIN
+			UPDATE outbox_events SET sequence = NEW.rowid WHERE rowid = NEW.rowid;
+		END`).Error)
+	return &db.Repo{Db: gDb}
+}
+
+// pending returns the events not yet published, in the order of the outbox.
+func pending(t *testing.T, repo *db.Repo) []outbox.Event {
+	events := make([]outbox.Event, 0)
+	require.NoError(t, repo.DBInstance(context.Background()).
+		Where("published_at = 0").Order("sequence").Find(&events).Error)
+	return events
+}
+
+func record(t *testing.T, repo *db.Repo, fail bool, aggregateIDs ...string) error {
+	core := outbox.NewCore(repo)
+	return repo.Transaction(context.Background(), func(ctx context.Context) error {
+		for _, id := range aggregateIDs {
+			event, err := outbox.NewEvent(outbox.TypeAccountCreated, outbox.AggregateAccount, id, map[string]string{"id": id})
+			require.NoError(t, err)
+			if err := core.Record(ctx, event); err != nil {
+				return err
+			}
+		}
+		if fail {
+			return errors.New("rolled back")
+		}
+		return nil
+	})
+}
+
+func TestRelay_Publishes_Committed_Events_In_Order(t *testing.T) {
+	repo := setupRepo(t)
+	require.NoError(t, record(t, repo, false, "a0000000000001", "a0000000000002"))
+	require.Error(t, record(t, repo, true, "a0000000000003"))
+	require.NoError(t, record(t, repo, false, "a0000000000004"))
+
+	publisher := outbox.NewMemoryPublisher()
+	relay := outbox.NewRelay(repo, publisher, outbox.Config{BatchSize: 10})
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+
+	messages := publisher.Messages()
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.AggregateID)
+	}
+	assert.Equal(t, []string{"a0000000000001", "a0000000000002", "a0000000000004"}, ids)
+	assert.JSONEq(t, `{"id":"a0000000000001"}`, string(messages[0].Payload))
+
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestRelay_Orders_By_Sequence_Not_By_Clock(t *testing.T) {
+	repo := setupRepo(t)
+	core := outbox.NewCore(repo)
+	for i, id := range []string{"a0000000000001", "a0000000000002"} {
+		event, err := outbox.NewEvent(outbox.TypeAccountCreated, outbox.AggregateAccount, id, map[string]string{"id": id})
+		require.NoError(t, err)
+		// The clock of the instance recording the first event is ahead.
+		event.OccurredAt -= int64(i) * int64(time.Hour)
+		require.NoError(t, core.Record(context.Background(), event))
+	}
+
+	publisher := outbox.NewMemoryPublisher()
+	_, err := outbox.NewRelay(repo, publisher, outbox.Config{BatchSize: 10}).RelayOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, publisher.Messages(), 2)
+	assert.Equal(t, "a0000000000001", publisher.Messages()[0].AggregateID)
+	assert.Equal(t, "a0000000000002", publisher.Messages()[1].AggregateID)
+}
+
+func TestRelay_Holds_Back_The_Aggregate_Of_A_Failed_Event_Only(t *testing.T) {
+	repo := setupRepo(t)
+	require.NoError(t, record(t, repo, false, "a0000000000001", "a0000000000002", "a0000000000001", "a0000000000003"))
+	failing := pending(t, repo)
+
+	publisher := outbox.NewMemoryPublisher()
+	publisher.FailAggregateWith("a0000000000001", errors.New("broker unavailable"))
+	relay := outbox.NewRelay(repo, publisher, outbox.Config{BatchSize: 10, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	before := time.Now()
+	published, err := relay.RelayOnce(context.Background())
+	assert.ErrorContains(t, err, "broker unavailable")
+	assert.Equal(t, 2, published)
+
+	events := pending(t, repo)
+	require.Len(t, events, 2)
+	assert.Equal(t, failing[0].ID, events[0].ID)
+	assert.Equal(t, 1, events[0].Attempts)
+	assert.Equal(t, "broker unavailable", events[0].LastError)
+	assert.GreaterOrEqual(t, events[0].NextAttemptAt, before.Add(time.Hour).UnixNano())
+	// The later event of the aggregate waits without being attempted.
+	assert.Equal(t, failing[2].ID, events[1].ID)
+	assert.Zero(t, events[1].Attempts)
+
+	// Neither is retried before the backoff has passed, even once the publisher recovers.
+	publisher.FailAggregateWith("a0000000000001", nil)
+	require.NoError(t, record(t, repo, false, "a0000000000004"))
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	require.NoError(t, repo.DBInstance(context.Background()).Model(&outbox.Event{}).
+		Where("published_at = 0").Update("next_attempt_at", 0).Error)
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	ids := make([]string, 0)
+	for _, message := range publisher.Messages() {
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(t, []string{failing[1].ID, failing[3].ID, ids[2], failing[0].ID, failing[2].ID}, ids)
+	assert.Empty(t, pending(t, repo))
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := outbox.NewRelay(nil, nil, outbox.Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, relay.Backoff(1))
+	assert.Equal(t, 4*time.Second, relay.Backoff(3))
+	assert.Equal(t, 5*time.Second, relay.Backoff(10))
+}
+
+func TestRelay_Publishes_Only_While_Holding_The_Lease(t *testing.T) {
+	repo := setupRepo(t)
+	require.NoError(t, record(t, repo, false, "a0000000000001"))
+
+	firstPublisher, secondPublisher := outbox.NewMemoryPublisher(), outbox.NewMemoryPublisher()
+	first := outbox.NewRelay(repo, firstPublisher, outbox.Config{BatchSize: 10, LeaseDuration: time.Hour})
+	second := outbox.NewRelay(repo, secondPublisher, outbox.Config{BatchSize: 10, LeaseDuration: time.Hour})
+	published, err := first.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	require.NoError(t, record(t, repo, false, "a0000000000002"))
+	published, err = second.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Empty(t, secondPublisher.Messages())
+
+	published, err = first.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Len(t, firstPublisher.Messages(), 2)
+}
+
+func TestRelay_Takes_Over_An_Expired_Lease(t *testing.T) {
+	repo := setupRepo(t)
+	first := outbox.NewRelay(repo, outbox.NewMemoryPublisher(), outbox.Config{BatchSize: 10, LeaseDuration: time.Millisecond})
+	second := outbox.NewRelay(repo, outbox.NewMemoryPublisher(), outbox.Config{BatchSize: 10, LeaseDuration: time.Hour})
+	_, err := first.RelayOnce(context.Background())
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	require.NoError(t, record(t, repo, false, "a0000000000001"))
+	published, err := second.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+}
+
+func TestRelay_Releases_The_Lease_When_Stopped(t *testing.T) {
+	repo := setupRepo(t)
+	first := outbox.NewRelay(repo, outbox.NewMemoryPublisher(), outbox.Config{BatchSize: 10, LeaseDuration: time.Hour})
+	second := outbox.NewRelay(repo, outbox.NewMemoryPublisher(), outbox.Config{BatchSize: 10, LeaseDuration: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		first.Run(ctx)
+	}()
+	require.Eventually(t, func() bool {
+		var lease outbox.Lease
+		return repo.DBInstance(context.Background()).Take(&lease).Error == nil
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-stopped
+
+	require.NoError(t, record(t, repo, false, "a0000000000001"))
+	published, err := second.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+}
+
+func TestWriterPublisher_Writes_JSON_Lines(t *testing.T) {
+	buf := new(bytes.Buffer)
+	publisher := outbox.NewWriterPublisher(buf)
+	event, err := outbox.NewEvent(outbox.TypeTransactionCreated, outbox.AggregateTransaction, "t0000000000001", map[string]int{"amount": 10})
+	require.NoError(t, err)
+	event.ID = "e0000000000001"
+
+	require.NoError(t, publisher.Publish(context.Background(), event.ToMessage()))
+	require.NoError(t, publisher.Publish(context.Background(), event.ToMessage()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var message map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &message))
+	assert.Equal(t, "transaction.created", message["type"])
+	assert.Equal(t, "e0000000000001", message["id"])
+	assert.Equal(t, map[string]interface{}{"amount": float64(10)}, message["payload"])
+}
+
+func TestMultiPublisher_Publishes_To_Each_Until_Failure(t *testing.T) {
+	first, second := outbox.NewMemoryPublisher(), outbox.NewMemoryPublisher()
+	assert.Nil(t, outbox.NewMultiPublisher(nil, nil))
+	assert.Same(t, first, outbox.NewMultiPublisher(nil, first))
+
+	multi := outbox.NewMultiPublisher(first, nil, second)
+	require.NoError(t, multi.Publish(context.Background(), &outbox.Message{ID: "e0000000000001"}))
+	assert.Len(t, first.Messages(), 1)
+	assert.Len(t, second.Messages(), 1)
+
+	first.FailWith(errors.New("broker unavailable"))
+	assert.Error(t, multi.Publish(context.Background(), &outbox.Message{ID: "e0000000000002"}))
+	assert.Len(t, second.Messages(), 1)
+}
+
+func TestCore_Record_Attributes_Events_To_Client(t *testing.T) {
+	repo := setupRepo(t)
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{ClientID: "0c000000000000", ClientName: "partner"})
+	event, err := outbox.NewEvent(outbox.TypeAccountCreated, outbox.AggregateAccount, "a0000000000001", map[string]string{})
+	require.NoError(t, err)
+	require.NoError(t, outbox.NewCore(repo).Record(ctx, event))
+
+	publisher := outbox.NewMemoryPublisher()
+	_, err = outbox.NewRelay(repo, publisher, outbox.Config{BatchSize: 10}).RelayOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, publisher.Messages(), 1)
+	assert.Equal(t, "0c000000000000", publisher.Messages()[0].Actor)
+}
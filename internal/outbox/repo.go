@@ -0,0 +1,15 @@
+package outbox
+
+import (
+	"context"
+	"transaction-server/internal/common/db"
+
+	"gorm.io/gorm"
+)
+
+type IRepo interface {
+	Create(ctx context.Context, receiver db.IModel) error
+	Update(ctx context.Context, receiver db.IModel, selectiveList ...string) error
+	FindManyWithFilters(ctx context.Context, models interface{}, req db.FindManyWithFiltersRequester) error
+	DBInstance(ctx context.Context) *gorm.DB
+}
@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Role is the set of permissions granted to the clients holding it.
+type Role struct {
+	Permissions []string
+	// Scope is ScopeAll, or ScopeOwned to restrict the role to the accounts the client created.
+	Scope string
+}
+
+type Config struct {
+	// DefaultRole is the role of clients without a role of their own.
+	DefaultRole string
+	// Roles are the roles by name. The built-in roles are used when none are configured.
+	Roles map[string]Role
+}
+
+// DefaultRoles returns the built-in roles: admins can do everything, support agents can
+// read the accounts and transactions and partners can read and post on the accounts they
+// created. FX rates, webhooks and the configuration are left to admins.
+func DefaultRoles() map[string]Role {
+	return map[string]Role{
+		RoleAdmin:   {Permissions: Permissions, Scope: ScopeAll},
+		RoleAgent:   {Permissions: []string{PermissionAccountsRead, PermissionTransactionsRead}, Scope: ScopeAll},
+		RolePartner: {Permissions: []string{PermissionAccountsRead, PermissionAccountsWrite, PermissionTransactionsRead, PermissionTransactionsWrite}, Scope: ScopeOwned},
+	}
+}
+
+// Validate validates the roles and that the default role is one of them.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.DefaultRole, validation.By(func(interface{}) error {
+			roles := c.Roles
+			if len(roles) == 0 {
+				roles = DefaultRoles()
+			}
+			defaultRole := c.DefaultRole
+			if defaultRole == "" {
+				defaultRole = RolePartner
+			}
+			if _, ok := roles[defaultRole]; !ok {
+				return errors.New("must be one of the roles")
+			}
+			return nil
+		})),
+		validation.Field(&c.Roles),
+	)
+}
+
+// Validate validates the scope and permissions of the role.
+func (r Role) Validate() error {
+	permissions := make([]interface{}, 0, len(Permissions))
+	for _, permission := range Permissions {
+		permissions = append(permissions, permission)
+	}
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Permissions, validation.Each(validation.In(permissions...))),
+		validation.Field(&r.Scope, validation.Required, validation.In(ScopeAll, ScopeOwned)),
+	)
+}
@@ -0,0 +1,202 @@
+// Package policy decides what the authenticated client of a request may do, from the
+// permissions of its role and, for roles scoped to owned accounts, who created the account.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
+)
+
+// Permissions.
+const (
+	PermissionAccountsRead      = "accounts:read"
+	PermissionAccountsWrite     = "accounts:write"
+	PermissionTransactionsRead  = "transactions:read"
+	PermissionTransactionsWrite = "transactions:write"
+	// PermissionFxRatesWrite allows publishing the FX rates transactions are converted with.
+	PermissionFxRatesWrite = "fxrates:write"
+	// PermissionWebhooksRead and PermissionWebhooksWrite allow reading and managing the webhook
+	// subscriptions and their deliveries.
+	PermissionWebhooksRead  = "webhooks:read"
+	PermissionWebhooksWrite = "webhooks:write"
+	// PermissionConfigRead allows reading the effective configuration of the server.
+	PermissionConfigRead = "config:read"
+)
+
+// Permissions lists the permissions.
+var Permissions = []string{PermissionAccountsRead, PermissionAccountsWrite, PermissionTransactionsRead, PermissionTransactionsWrite,
+	PermissionFxRatesWrite, PermissionWebhooksRead, PermissionWebhooksWrite, PermissionConfigRead}
+
+// Built-in roles.
+const (
+	RoleAdmin   = "admin"
+	RoleAgent   = "agent"
+	RolePartner = "partner"
+)
+
+// Scopes of roles.
+const (
+	ScopeAll   = "all"
+	ScopeOwned = "owned"
+)
+
+// ErrForbidden is returned, wrapped, when the client may not perform an action.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUnauthenticated is returned when the context carries no identity: nothing is
+// authorized without one. Trusted callers acting on their own use auth.WithSystemIdentity.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+type IPolicy interface {
+	// Authorize returns ErrForbidden unless the client holds the permission, and
+	// ErrUnauthenticated without a client.
+	Authorize(ctx context.Context, permission string) error
+	// AuthorizeOwner authorizes the permission on an account created by the owner.
+	AuthorizeOwner(ctx context.Context, permission string, ownerID string) error
+	// AuthorizeAccount authorizes the permission on the account. An empty account ID, as
+	// in a list across accounts, is only authorized for clients not scoped to owned accounts.
+	AuthorizeAccount(ctx context.Context, permission string, accountID string) error
+	// OwnerScope returns the ID of the client whose accounts the client is restricted to,
+	// or an empty string when it may see every account.
+	OwnerScope(ctx context.Context) string
+}
+
+type Policy struct {
+	repo   IRepo
+	config Config
+}
+
+func NewPolicy(repo IRepo, config Config) *Policy {
+	if len(config.Roles) == 0 {
+		config.Roles = DefaultRoles()
+	}
+	if config.DefaultRole == "" {
+		config.DefaultRole = RolePartner
+	}
+	return &Policy{repo: repo, config: config}
+}
+
+// caller is the client of a request with its role.
+type caller struct {
+	clientID      string
+	name          string
+	role          Role
+	known         bool
+	authenticated bool
+}
+
+// caller returns the client of the request. The system holds every permission; a request
+// without an identity holds none.
+func (p *Policy) caller(ctx context.Context) *caller {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || identity == nil {
+		return &caller{}
+	}
+	if identity.System {
+		return &caller{name: auth.SystemClientName, role: Role{Permissions: Permissions, Scope: ScopeAll}, known: true, authenticated: true}
+	}
+	name := identity.Role
+	if name == "" {
+		name = p.config.DefaultRole
+	}
+	role, known := p.config.Roles[name]
+	return &caller{clientID: identity.ClientID, name: name, role: role, known: known, authenticated: true}
+}
+
+func (p *Policy) Authorize(ctx context.Context, permission string) error {
+	return p.caller(ctx).authorize(permission)
+}
+
+func (p *Policy) AuthorizeOwner(ctx context.Context, permission string, ownerID string) error {
+	c := p.caller(ctx)
+	if err := c.authorize(permission); err != nil {
+		return err
+	}
+	if c.role.Scope == ScopeOwned && ownerID != c.clientID {
+		return fmt.Errorf("%w: account is not owned by the client", ErrForbidden)
+	}
+	return nil
+}
+
+func (p *Policy) AuthorizeAccount(ctx context.Context, permission string, accountID string) error {
+	c := p.caller(ctx)
+	if err := c.authorize(permission); err != nil {
+		return err
+	}
+	if c.role.Scope != ScopeOwned {
+		return nil
+	}
+	if accountID == "" {
+		return fmt.Errorf("%w: role %s must filter by an owned account", ErrForbidden, c.name)
+	}
+	// The owner is read from the primary, for the client to use an account it just created
+	// before the replicas catch up.
+	account := &ownedAccount{}
+	if err := p.repo.FindByID(db.WithPrimary(ctx), account, accountID); err != nil {
+		// An account that does not exist is not owned, and is reported as any other.
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: account is not owned by the client", ErrForbidden)
+		}
+		return err
+	}
+	if account.OwnerID != c.clientID {
+		return fmt.Errorf("%w: account is not owned by the client", ErrForbidden)
+	}
+	return nil
+}
+
+func (p *Policy) OwnerScope(ctx context.Context) string {
+	if c := p.caller(ctx); c.role.Scope == ScopeOwned {
+		return c.clientID
+	}
+	return ""
+}
+
+func (c *caller) authorize(permission string) error {
+	if !c.authenticated {
+		return ErrUnauthenticated
+	}
+	if !c.known {
+		return fmt.Errorf("%w: unknown role %s", ErrForbidden, c.name)
+	}
+	for _, granted := range c.role.Permissions {
+		if granted == permission {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: role %s lacks %s", ErrForbidden, c.name, permission)
+}
+
+// ErrorCode returns the error code of a failed authorization.
+func ErrorCode(err error) string {
+	if errors.Is(err, ErrForbidden) {
+		return common.ErrForbidden
+	}
+	if errors.Is(err, ErrUnauthenticated) {
+		return common.ErrUnauthorized
+	}
+	return common.ErrDBQueryError
+}
+
+// ownedAccount reads the owner of an account, without depending on the account package.
+type ownedAccount struct {
+	db.Model
+	OwnerID string
+}
+
+func (e *ownedAccount) TableName() string {
+	return "accounts"
+}
+
+func (e *ownedAccount) EntityName() string {
+	return "account"
+}
+
+func (e *ownedAccount) SetDefaults() error {
+	return nil
+}
@@ -0,0 +1,139 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/account"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/policy"
+)
+
+const (
+	partnerID = "0c000000000001"
+	ownedID   = "a0000000000001"
+	othersID  = "a0000000000002"
+)
+
+// setupPolicy returns a policy over an in-memory database holding an account of the
+// partner and one of another client.
+func setupPolicy(t *testing.T, config policy.Config) *policy.Policy {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&account.Account{}))
+	repo := &db.Repo{Db: gDb}
+	for id, owner := range map[string]string{ownedID: partnerID, othersID: "0c000000000002"} {
+		acc := &account.Account{Name: "John Doe", DocumentNumber: "123456789", OwnerID: owner}
+		acc.ID = id
+		require.NoError(t, repo.Create(context.Background(), acc))
+	}
+	return policy.NewPolicy(repo, config)
+}
+
+func as(role string) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{ClientID: partnerID, Role: role})
+}
+
+func TestPolicy_AuthorizeAccount(t *testing.T) {
+	p := setupPolicy(t, policy.Config{})
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		permission string
+		accountID  string
+		allowed    bool
+	}{
+		{"system", auth.WithSystemIdentity(context.Background()), policy.PermissionTransactionsWrite, othersID, true},
+		{"admin", as(policy.RoleAdmin), policy.PermissionTransactionsWrite, othersID, true},
+		{"agent reads", as(policy.RoleAgent), policy.PermissionTransactionsRead, othersID, true},
+		{"agent posts", as(policy.RoleAgent), policy.PermissionTransactionsWrite, othersID, false},
+		{"agent lists", as(policy.RoleAgent), policy.PermissionTransactionsRead, "", true},
+		{"partner posts on owned account", as(policy.RolePartner), policy.PermissionTransactionsWrite, ownedID, true},
+		{"partner posts on other account", as(policy.RolePartner), policy.PermissionTransactionsWrite, othersID, false},
+		{"partner posts on missing account", as(policy.RolePartner), policy.PermissionTransactionsWrite, "a0000000000003", false},
+		{"partner lists across accounts", as(policy.RolePartner), policy.PermissionTransactionsRead, "", false},
+		{"default role", as(""), policy.PermissionTransactionsRead, othersID, false},
+		{"unknown role", as("auditor"), policy.PermissionTransactionsRead, ownedID, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.AuthorizeAccount(tt.ctx, tt.permission, tt.accountID)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, policy.ErrForbidden)
+				assert.Equal(t, common.ErrForbidden, policy.ErrorCode(err))
+			}
+		})
+	}
+}
+
+func TestPolicy_Fails_Closed_Without_An_Identity(t *testing.T) {
+	p := setupPolicy(t, policy.Config{})
+	for _, ctx := range []context.Context{context.Background(), auth.WithIdentity(context.Background(), nil)} {
+		err := p.Authorize(ctx, policy.PermissionAccountsRead)
+		assert.ErrorIs(t, err, policy.ErrUnauthenticated)
+		assert.Equal(t, common.ErrUnauthorized, policy.ErrorCode(err))
+		assert.ErrorIs(t, p.AuthorizeOwner(ctx, policy.PermissionAccountsRead, ""), policy.ErrUnauthenticated)
+		assert.ErrorIs(t, p.AuthorizeAccount(ctx, policy.PermissionTransactionsRead, ownedID), policy.ErrUnauthenticated)
+		assert.ErrorIs(t, p.AuthorizeAccount(ctx, policy.PermissionTransactionsRead, ""), policy.ErrUnauthenticated)
+	}
+}
+
+func TestPolicy_AuthorizeAccount_Reads_The_Primary(t *testing.T) {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")), db.ReplicaDialectors(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = gDb.Close() })
+	replica := gDb.Replicas()[0]
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&account.Account{}))
+	require.NoError(t, replica.AutoMigrate(&account.Account{}))
+	repo := &db.Repo{Db: gDb}
+	p := policy.NewPolicy(repo, policy.Config{})
+
+	// The replica has not caught up with the account the partner just created.
+	acc := &account.Account{Name: "John Doe", DocumentNumber: "123456789", OwnerID: partnerID}
+	acc.ID = ownedID
+	require.NoError(t, repo.Create(context.Background(), acc))
+
+	assert.NoError(t, p.AuthorizeAccount(as(policy.RolePartner), policy.PermissionTransactionsWrite, ownedID))
+}
+
+func TestPolicy_AuthorizeOwner(t *testing.T) {
+	p := setupPolicy(t, policy.Config{})
+	assert.NoError(t, p.AuthorizeOwner(as(policy.RolePartner), policy.PermissionAccountsRead, partnerID))
+	assert.ErrorIs(t, p.AuthorizeOwner(as(policy.RolePartner), policy.PermissionAccountsRead, ""), policy.ErrForbidden)
+	assert.NoError(t, p.AuthorizeOwner(as(policy.RoleAgent), policy.PermissionAccountsRead, ""))
+	assert.ErrorIs(t, p.Authorize(as(policy.RoleAgent), policy.PermissionAccountsWrite), policy.ErrForbidden)
+}
+
+func TestPolicy_OwnerScope(t *testing.T) {
+	p := setupPolicy(t, policy.Config{})
+	assert.Equal(t, partnerID, p.OwnerScope(as(policy.RolePartner)))
+	assert.Empty(t, p.OwnerScope(as(policy.RoleAgent)))
+	assert.Empty(t, p.OwnerScope(context.Background()))
+}
+
+func TestPolicy_Configured_Roles(t *testing.T) {
+	p := setupPolicy(t, policy.Config{
+		DefaultRole: "auditor",
+		Roles:       map[string]policy.Role{"auditor": {Permissions: []string{policy.PermissionTransactionsRead}, Scope: policy.ScopeAll}},
+	})
+	assert.NoError(t, p.AuthorizeAccount(as(""), policy.PermissionTransactionsRead, othersID))
+	assert.ErrorIs(t, p.Authorize(as(""), policy.PermissionAccountsRead), policy.ErrForbidden)
+	// Configured roles replace the built-in ones.
+	assert.ErrorIs(t, p.Authorize(as(policy.RoleAdmin), policy.PermissionAccountsRead), policy.ErrForbidden)
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, common.ErrDBQueryError, policy.ErrorCode(errors.New("DB error")))
+}
@@ -0,0 +1,10 @@
+package policy
+
+import (
+	"context"
+	"transaction-server/internal/common/db"
+)
+
+type IRepo interface {
+	FindByID(ctx context.Context, receiver db.IModel, id string) error
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Result is the outcome of taking a request from a bucket.
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of requests left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next request is allowed, when this one was not.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// bucket is the state of a token bucket at UpdatedAt.
+type bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate returns the number of tokens added to the bucket per second.
+func (l Limit) rate() float64 {
+	period := l.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	return float64(l.Requests) / period.Seconds()
+}
+
+// take refills the bucket for the time elapsed since it was last updated and takes a token
+// from it if there is one. A nil bucket is a full one.
+func (l Limit) take(b *bucket, now time.Time) (bucket, Result) {
+	capacity := float64(l.burst())
+	tokens := capacity
+	if b != nil {
+		elapsed := now.Sub(b.UpdatedAt).Seconds()
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		tokens = math.Min(capacity, b.Tokens+elapsed*l.rate())
+	}
+	result := Result{Limit: l.burst()}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.duration(1 - tokens)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = l.duration(capacity - tokens)
+	return bucket{Tokens: tokens, UpdatedAt: now}, result
+}
+
+// duration returns how long the bucket takes to gain the tokens.
+func (l Limit) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / l.rate() * float64(time.Second)))
+}
+
+// Seconds returns the duration in whole seconds, rounded up, as sent in headers.
+func Seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
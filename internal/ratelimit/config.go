@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreDB     = "db"
+)
+
+type Config struct {
+	// Enabled limits the rate of the API requests of each client.
+	Enabled bool
+	// Store keeps the token buckets: "memory" for a single instance, or "db" for the
+	// instances of a deployment to share them.
+	Store string
+	// Default is the limit of the routes without one in Routes.
+	Default Limit
+	// Routes are the limits of routes by "METHOD /path" as registered, e.g.
+	// "POST /transactions", or by gRPC full method name. Routes match case-insensitively.
+	Routes map[string]Limit
+}
+
+// Limit is a token bucket: it holds up to Burst requests and refills at Requests per Period.
+// A limit without Requests does not limit.
+type Limit struct {
+	Requests int
+	// Period defaults to a second.
+	Period time.Duration
+	// Burst is the number of requests that may be made at once; it defaults to Requests.
+	Burst int
+}
+
+// Validate validates the store and the limits.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Store, validation.In(StoreMemory, StoreDB)),
+		validation.Field(&c.Default),
+		validation.Field(&c.Routes),
+	)
+}
+
+// Validate validates the limit is not negative.
+func (l Limit) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.Requests, validation.Min(0)),
+		validation.Field(&l.Period, validation.Min(time.Duration(0))),
+		validation.Field(&l.Burst, validation.Min(0)),
+	)
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTakeAttempts is how many times DBStore.Take reads a bucket updated concurrently.
+const maxTakeAttempts = 5
+
+var ErrContention = errors.New("rate limit bucket is updated concurrently")
+
+// DBStore keeps the token buckets in the database, for the instances of a deployment to
+// share the limits. Buckets are updated with a compare-and-swap on their version rather
+// than under a lock, and the ones that are full again are deleted. Buckets are read from the
+// primary, never from a replica, for the swaps to compare against their last version.
+type DBStore struct {
+	repo    IRepo
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func NewDBStore(repo IRepo) *DBStore {
+	return &DBStore{repo: repo}
+}
+
+func (s *DBStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if err := s.sweep(ctx, now); err != nil {
+		return Result{}, err
+	}
+	for attempt := 0; attempt < maxTakeAttempts; attempt++ {
+		var stored Bucket
+		err := s.repo.DBInstance(ctx).Where("id = ?", key).Take(&stored).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			next, result := limit.take(nil, now)
+			q := s.repo.DBInstance(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(newBucket(key, next, result, 0))
+			if q.Error != nil {
+				return Result{}, q.Error
+			}
+			if q.RowsAffected == 1 {
+				return result, nil
+			}
+			continue
+		}
+		if err != nil {
+			return Result{}, err
+		}
+
+		next, result := limit.take(&bucket{Tokens: stored.Tokens, UpdatedAt: time.Unix(0, stored.RefilledAt)}, now)
+		if !result.Allowed {
+			// The refill is recomputed from the stored state, so a rejection needs no write.
+			return result, nil
+		}
+		updated := newBucket(key, next, result, stored.Version+1)
+		q := s.repo.DBInstance(ctx).Model(&Bucket{}).
+			Where("id = ? AND version = ?", key, stored.Version).
+			Updates(map[string]interface{}{
+				"tokens":      updated.Tokens,
+				"refilled_at": updated.RefilledAt,
+				"full_at":     updated.FullAt,
+				"version":     updated.Version,
+			})
+		if q.Error != nil {
+			return Result{}, q.Error
+		}
+		if q.RowsAffected == 1 {
+			return result, nil
+		}
+	}
+	return Result{}, ErrContention
+}
+
+func newBucket(key string, next bucket, result Result, version int64) *Bucket {
+	return &Bucket{
+		ID:         key,
+		Tokens:     next.Tokens,
+		RefilledAt: next.UpdatedAt.UnixNano(),
+		FullAt:     next.UpdatedAt.Add(result.Reset).Unix(),
+		Version:    version,
+	}
+}
+
+// sweep deletes the buckets that are full again, at most once per sweep interval.
+func (s *DBStore) sweep(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	if now.Sub(s.sweptAt) < sweepInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.sweptAt = now
+	s.mu.Unlock()
+	return s.repo.DBInstance(ctx).Where("full_at < ?", now.Unix()).Delete(&Bucket{}).Error
+}
@@ -0,0 +1,72 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/ratelimit"
+)
+
+func setupDBStore(t *testing.T) (*ratelimit.DBStore, db.Repoer) {
+	gDb, err := db.NewDb(&db.Config{ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1}},
+		db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(&ratelimit.Bucket{}))
+	repo := &db.Repo{Db: gDb}
+	return ratelimit.NewDBStore(repo), repo
+}
+
+func TestDBStore_Take(t *testing.T) {
+	store, _ := setupDBStore(t)
+	testStore(t, store)
+}
+
+func TestDBStore_Take_Concurrently(t *testing.T) {
+	store, _ := setupDBStore(t)
+	limit := ratelimit.Limit{Requests: 1, Period: time.Hour, Burst: 5}
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Take(context.Background(), "a", limit, now)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if result.Allowed {
+				allowed++
+			}
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, allowed, 5)
+	assert.Greater(t, allowed, 0)
+}
+
+func TestDBStore_Deletes_Full_Buckets(t *testing.T) {
+	store, repo := setupDBStore(t)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Second, Burst: 1}
+	now := time.Now()
+
+	_, err := store.Take(ctx, "a", limit, now)
+	require.NoError(t, err)
+	_, err = store.Take(ctx, "b", limit, now.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	var keys []string
+	require.NoError(t, repo.DBInstance(ctx).Model(&ratelimit.Bucket{}).Pluck("id", &keys).Error)
+	assert.Equal(t, []string{"b"}, keys)
+}
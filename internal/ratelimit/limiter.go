@@ -0,0 +1,71 @@
+// Package ratelimit limits the rate of the API requests of each client to each route with
+// token buckets.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Limiter struct {
+	store  IStore
+	limits atomic.Pointer[limits]
+	now    func() time.Time
+}
+
+// limits are the limits of the routes, by lower cased route.
+type limits struct {
+	defaultLimit Limit
+	routes       map[string]Limit
+}
+
+func NewLimiter(config Config, store IStore) *Limiter {
+	limiter := &Limiter{store: store, now: time.Now}
+	limiter.SetLimits(config.Default, config.Routes)
+	return limiter
+}
+
+// SetLimits replaces the default and route limits. The buckets keep the tokens they hold.
+func (l *Limiter) SetLimits(defaultLimit Limit, routes map[string]Limit) {
+	lowered := make(map[string]Limit, len(routes))
+	for route, limit := range routes {
+		lowered[strings.ToLower(route)] = limit
+	}
+	l.limits.Store(&limits{defaultLimit: defaultLimit, routes: lowered})
+}
+
+// NewStore returns the store named by the config.
+func NewStore(config Config, repo IRepo) IStore {
+	if config.Store == StoreDB {
+		return NewDBStore(repo)
+	}
+	return NewMemoryStore()
+}
+
+// Limit returns the limit of the route.
+func (l *Limiter) Limit(route string) Limit {
+	current := l.limits.Load()
+	if limit, ok := current.routes[strings.ToLower(route)]; ok {
+		return limit
+	}
+	return current.defaultLimit
+}
+
+// Take takes a request of the client to the route from their bucket. It returns false
+// when the route is not limited. Requests are let through when the store fails, so an
+// outage of the store does not take the API down with it.
+func (l *Limiter) Take(ctx context.Context, client string, route string) (Result, bool) {
+	limit := l.Limit(route)
+	if limit.Requests <= 0 {
+		return Result{}, false
+	}
+	result, err := l.store.Take(ctx, client+" "+route, limit, l.now())
+	if err != nil {
+		slog.ErrorContext(ctx, "rate limit store failed", "error", err)
+		return Result{}, false
+	}
+	return result, true
+}
@@ -0,0 +1,26 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/ratelimit"
+)
+
+func TestLimiter_SetLimits(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Default: ratelimit.Limit{Requests: 10},
+		Routes:  map[string]ratelimit.Limit{"POST /transactions": {Requests: 1, Period: time.Minute}},
+	}, ratelimit.NewMemoryStore())
+	assert.Equal(t, 1, limiter.Limit("post /transactions").Requests)
+
+	limiter.SetLimits(ratelimit.Limit{Requests: 5}, map[string]ratelimit.Limit{"GET /accounts": {Requests: 2}})
+
+	assert.Equal(t, 5, limiter.Limit("POST /transactions").Requests)
+	assert.Equal(t, 2, limiter.Limit("GET /accounts").Requests)
+	result, limited := limiter.Take(context.Background(), "client:0c000000000001", "GET /accounts")
+	assert.True(t, limited)
+	assert.Equal(t, 2, result.Limit)
+}
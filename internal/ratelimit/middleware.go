@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware limits the requests of each client to each route, keyed by the authenticated
+// client or, without one, the client IP. It runs after the authentication middleware, if any.
+// Limited routes respond with the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers, and with 429 and Retry-After once the limit is reached.
+func Middleware(limiter *Limiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, limited := limiter.Take(ctx, ClientKey(ctx), ctx.Request.Method+" "+ctx.FullPath())
+		if !limited {
+			ctx.Next()
+			return
+		}
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(Seconds(result.Reset)))
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(Seconds(result.RetryAfter)))
+			message := fmt.Sprintf("rate limit exceeded, retry in %d seconds", Seconds(result.RetryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, dto.GetErrorResponse(common.ErrRateLimited, message))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// ClientKey returns the key of the client of the request in the buckets.
+func ClientKey(ctx *gin.Context) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		return "client:" + identity.ClientID
+	}
+	return "ip:" + ctx.ClientIP()
+}
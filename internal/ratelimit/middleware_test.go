@@ -0,0 +1,67 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/ratelimit"
+)
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Routes: map[string]ratelimit.Limit{"POST /transactions": {Requests: 1, Period: time.Minute}},
+	}, ratelimit.NewMemoryStore())
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		if client := ctx.GetHeader("X-Client"); client != "" {
+			ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: client})
+		}
+	})
+	router.Use(ratelimit.Middleware(limiter))
+	router.POST("/transactions", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.GET("/transactions", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	return router
+}
+
+func serve(router *gin.Engine, method string, client string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/transactions", nil)
+	req.Header.Set("X-Client", client)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestMiddleware_Limits_Client(t *testing.T) {
+	router := setupRouter()
+
+	recorder := serve(router, http.MethodPost, "0c000000000001")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", recorder.Header().Get("RateLimit-Reset"))
+
+	recorder = serve(router, http.MethodPost, "0c000000000001")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"success":false,"error":{"code":"ERR_RATE_LIMITED_ERROR","message":"rate limit exceeded, retry in 60 seconds"}}`,
+		recorder.Body.String())
+
+	// Other clients and routes are not limited by it.
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodPost, "0c000000000002").Code)
+	recorder = serve(router, http.MethodGet, "0c000000000001")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestMiddleware_Limits_Unauthenticated_By_IP(t *testing.T) {
+	router := setupRouter()
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodPost, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodPost, "").Code)
+}
@@ -0,0 +1,19 @@
+package ratelimit
+
+// Bucket is a token bucket kept by DBStore. Buckets are shared by the tenants, as they
+// are keyed by client.
+type Bucket struct {
+	// ID is the key of the bucket.
+	ID     string `gorm:"primaryKey"`
+	Tokens float64
+	// RefilledAt is when the tokens were counted, in Unix nanoseconds.
+	RefilledAt int64
+	// FullAt is when the bucket is full again, in Unix seconds.
+	FullAt int64
+	// Version is incremented on each update, for concurrent updates to be detected.
+	Version int64
+}
+
+func (Bucket) TableName() string {
+	return "rate_limit_buckets"
+}
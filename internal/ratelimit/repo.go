@@ -0,0 +1,11 @@
+package ratelimit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type IRepo interface {
+	DBInstance(ctx context.Context) *gorm.DB
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often stores forget the buckets that are full again.
+const sweepInterval = time.Minute
+
+// IStore keeps the token buckets of the limiter.
+type IStore interface {
+	// Take takes a request from the bucket under key, which is full when there is none.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type memoryBucket struct {
+	bucket
+	// fullAt is when the bucket is full again, after which it is forgotten.
+	fullAt time.Time
+}
+
+// MemoryStore keeps the token buckets in memory, so each instance limits on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]memoryBucket
+	sweptAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	var current *bucket
+	if stored, ok := s.buckets[key]; ok {
+		current = &stored.bucket
+	}
+	next, result := limit.take(current, now)
+	s.buckets[key] = memoryBucket{bucket: next, fullAt: now.Add(result.Reset)}
+	return result, nil
+}
+
+// sweep forgets the buckets that are full again, as a missing bucket is a full one.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for key, stored := range s.buckets {
+		if !now.Before(stored.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/ratelimit"
+)
+
+// testStore takes requests from a bucket of 2 refilling a request per second.
+func testStore(t *testing.T, store ratelimit.IStore) {
+	ctx := context.Background()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Second, Burst: 2}
+	now := time.Unix(1700000000, 0)
+
+	take := func(key string, at time.Time) ratelimit.Result {
+		result, err := store.Take(ctx, key, limit, at)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, ratelimit.Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, take("a", now))
+	assert.Equal(t, ratelimit.Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, take("a", now))
+	assert.Equal(t, ratelimit.Result{Limit: 2, RetryAfter: time.Second, Reset: 2 * time.Second}, take("a", now))
+
+	// Other keys have their own bucket.
+	assert.True(t, take("b", now).Allowed)
+
+	// The bucket refills over time, up to its capacity.
+	half := take("a", now.Add(500*time.Millisecond))
+	assert.False(t, half.Allowed)
+	assert.Equal(t, 500*time.Millisecond, half.RetryAfter)
+	assert.True(t, take("a", now.Add(time.Second)).Allowed)
+	full := take("a", now.Add(time.Hour))
+	assert.True(t, full.Allowed)
+	assert.Equal(t, 1, full.Remaining)
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	testStore(t, ratelimit.NewMemoryStore())
+}
+
+func TestLimiter_Take(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Default: ratelimit.Limit{Requests: 10},
+		Routes: map[string]ratelimit.Limit{
+			"post /transactions":       {Requests: 1},
+			"GET /accounts/:accountId": {},
+		},
+	}, ratelimit.NewMemoryStore())
+	ctx := context.Background()
+
+	result, limited := limiter.Take(ctx, "client:a", "POST /transactions")
+	assert.True(t, limited)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Limit)
+	result, _ = limiter.Take(ctx, "client:a", "POST /transactions")
+	assert.False(t, result.Allowed)
+
+	// Limits are per client and per route.
+	result, _ = limiter.Take(ctx, "client:b", "POST /transactions")
+	assert.True(t, result.Allowed)
+	result, _ = limiter.Take(ctx, "client:a", "POST /accounts")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 10, result.Limit)
+
+	_, limited = limiter.Take(ctx, "client:a", "GET /accounts/:accountId")
+	assert.False(t, limited)
+}
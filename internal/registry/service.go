@@ -4,37 +4,151 @@ import (
 	"context"
 	"transaction-server/app"
 	"transaction-server/internal/account"
+	"transaction-server/internal/admin"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/common/db"
+	"transaction-server/internal/config"
+	"transaction-server/internal/database/migrations"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/health"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/ratelimit"
+	"transaction-server/internal/stream"
+	"transaction-server/internal/tenant"
 	"transaction-server/internal/transaction"
+	"transaction-server/internal/webhook"
 )
 
 type IRegistry interface {
 	GetAccountsServer() account.IServer
 	GetTransactionsServer() transaction.IServer
+	GetFxRatesServer() fxrate.IServer
+	GetWebhooksServer() webhook.IServer
+	GetTransactionStreamsServer() stream.IServer
+	// GetTransactionStreamBroker returns the broker the outbox relay publishes to for
+	// the transaction streams to receive events.
+	GetTransactionStreamBroker() *stream.Broker
+	// GetAuthenticator returns the core authenticating API requests, or nil when
+	// authentication is disabled.
+	GetAuthenticator() auth.ICore
+	// GetTenantResolver returns the resolver of the tenant of API requests.
+	GetTenantResolver() *tenant.Resolver
+	// GetRateLimiter returns the limiter of the rate of API requests, or nil when rate
+	// limiting is disabled.
+	GetRateLimiter() *ratelimit.Limiter
+	// GetHealthChecker returns the checker of the readiness of the application.
+	GetHealthChecker() *health.Checker
+	GetAdminServer() admin.IServer
 }
 
 type Registry struct {
 	accountServer     account.IServer
 	transactionServer transaction.IServer
+	fxRateServer      fxrate.IServer
+	webhookServer     webhook.IServer
+	streamServer      stream.IServer
+	streamBroker      *stream.Broker
+	authenticator     auth.ICore
+	tenantResolver    *tenant.Resolver
+	rateLimiter       *ratelimit.Limiter
+	healthChecker     *health.Checker
+	adminServer       admin.IServer
 }
 
 func (r Registry) GetTransactionsServer() transaction.IServer {
 	return r.transactionServer
 }
 
+func (r Registry) GetFxRatesServer() fxrate.IServer {
+	return r.fxRateServer
+}
+
+func (r Registry) GetWebhooksServer() webhook.IServer {
+	return r.webhookServer
+}
+
+func (r Registry) GetTransactionStreamsServer() stream.IServer {
+	return r.streamServer
+}
+
+func (r Registry) GetTransactionStreamBroker() *stream.Broker {
+	return r.streamBroker
+}
+
+func (r Registry) GetAuthenticator() auth.ICore {
+	return r.authenticator
+}
+
+func (r Registry) GetTenantResolver() *tenant.Resolver {
+	return r.tenantResolver
+}
+
+func (r Registry) GetRateLimiter() *ratelimit.Limiter {
+	return r.rateLimiter
+}
+
+func (r Registry) GetHealthChecker() *health.Checker {
+	return r.healthChecker
+}
+
+func (r Registry) GetAdminServer() admin.IServer {
+	return r.adminServer
+}
+
 func (r Registry) GetAccountsServer() account.IServer {
 	return r.accountServer
 }
 
 func NewRegistry(ctx context.Context) IRegistry {
 	commonRepo := db.NewRepo(app.Context().DB())
-	accountCore := account.NewCore(commonRepo)
-	accountServer := account.NewServer(accountCore)
+	outboxCore := outbox.NewCore(commonRepo)
+	accountCore := account.NewCore(commonRepo, outboxCore)
+	accessPolicy := policy.NewPolicy(commonRepo, app.Context().Config().Policy)
+	accountServer := account.NewServer(accountCore, accessPolicy)
+
+	fxRateCore := fxrate.NewCore(commonRepo)
+	fxRateServer := fxrate.NewServer(fxRateCore, accessPolicy)
+
+	transactionCore := transaction.NewCore(commonRepo, fxRateCore, outboxCore)
+	transactionServer := transaction.NewServer(transactionCore, accessPolicy, app.Context().Config().Transaction)
+
+	webhookServer := webhook.NewServer(webhook.NewCore(commonRepo), accessPolicy, app.Context().Config().Webhook)
+
+	streamBroker := stream.NewBroker(app.Context().Config().Stream)
+	streamServer := stream.NewServer(accountCore, accessPolicy, streamBroker)
+
+	var authenticator auth.ICore
+	if authConfig := app.Context().Config().Auth; authConfig.Enabled {
+		authenticator = auth.NewCore(commonRepo, authConfig)
+	}
+	var rateLimiter *ratelimit.Limiter
+	if rateLimitConfig := app.Context().Config().RateLimit; rateLimitConfig.Enabled {
+		rateLimiter = ratelimit.NewLimiter(rateLimitConfig, ratelimit.NewStore(rateLimitConfig, commonRepo))
+	}
+	// The transaction limits and rate limits follow the runtime configuration.
+	app.Context().Runtime().OnChange(func(runtimeConfig config.RuntimeConfig) {
+		transactionServer.SetConfig(runtimeConfig.Transaction)
+		if rateLimiter != nil {
+			rateLimiter.SetLimits(runtimeConfig.RateLimit.Default, runtimeConfig.RateLimit.Routes)
+		}
+	})
+	adminServer := admin.NewServer(accessPolicy, app.Context().Config(), app.Context().Runtime())
 
-	transactionCore := transaction.NewCore(commonRepo)
-	transactionServer := transaction.NewServer(transactionCore)
+	healthChecker := health.NewChecker(app.Context().Config().Health)
+	healthChecker.Register("database", health.DBCheck(app.Context().DB()))
+	healthChecker.Register("migrations", health.MigrationsCheck(app.Context().DB(), migrations.Latest))
 	return &Registry{
 		accountServer:     accountServer,
 		transactionServer: transactionServer,
+		fxRateServer:      fxRateServer,
+		webhookServer:     webhookServer,
+		streamServer:      streamServer,
+		streamBroker:      streamBroker,
+		authenticator:     authenticator,
+		tenantResolver:    tenant.NewResolver(app.Context().Config().Tenant),
+		rateLimiter:       rateLimiter,
+		healthChecker:     healthChecker,
+		adminServer:       adminServer,
 	}
 }
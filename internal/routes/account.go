@@ -3,6 +3,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"transaction-server/internal/account"
 	"transaction-server/internal/dto"
 )
@@ -96,3 +97,47 @@ func (a *Accounts) Get(ctx *gin.Context) {
 	response := a.server.Get(ctx, id)
 	SendResponse(ctx, response)
 }
+
+// List retrieves a list of accounts.
+// swagger:operation GET /accounts List
+//
+// Retrieves a list of accounts, newest first.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: document_number
+//     in: query
+//     description: The document number to filter accounts.
+//     type: string
+//   - name: limit
+//     in: query
+//     type: integer
+//   - name: offset
+//     in: query
+//     type: integer
+//
+// responses:
+//
+//	'200':
+//	  description: Accounts retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListAccountResponse"
+//	'400':
+//	  description: Bad request. Error response returned.
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'500':
+//	  description: Internal server error. Error response returned.
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Accounts) List(ctx *gin.Context) {
+	var listRequest dto.ListAccountRequest
+
+	if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	response := a.server.List(ctx, &listRequest)
+	SendResponse(ctx, response)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/admin"
+)
+
+// Admin represents the route handler for the operational endpoints.
+type Admin struct {
+	server admin.IServer
+}
+
+// NewAdminRoute creates a new Admin route handler.
+func NewAdminRoute(server admin.IServer) *Admin {
+	return &Admin{
+		server: server,
+	}
+}
+
+// GetConfig returns the configuration in effect.
+// swagger:operation GET /admin/config GetConfig
+//
+// Returns the configuration in effect, with the runtime configuration applied and secrets
+// redacted, and the version of the runtime configuration. Requires the config:read permission.
+// ---
+// produces:
+// - application/json
+//
+// responses:
+//
+//	'200':
+//	  description: The configuration.
+//	  schema:
+//	    "$ref": "#/definitions/GetConfigResponse"
+//	'403':
+//	  description: Forbidden. Error response returned.
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Admin) GetConfig(ctx *gin.Context) {
+	response := a.server.GetConfig(ctx)
+	SendResponse(ctx, response)
+}
@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/fxrate"
+)
+
+// FxRates represents the route handler for exchange rate endpoints.
+type FxRates struct {
+	server fxrate.IServer
+}
+
+// NewFxRatesRoute creates a new FxRates route handler.
+func NewFxRatesRoute(server fxrate.IServer) *FxRates {
+	return &FxRates{
+		server: server,
+	}
+}
+
+// Create handles the creation of a new exchange rate.
+// swagger:operation POST /fx-rates CreateFxRate
+//
+// Creates a new exchange rate effective from the given time.
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+//   - in: body
+//     name: body
+//     description: The rate to be created.
+//     required: true
+//     schema:
+//     "$ref": "#/definitions/CreateFxRateRequest"
+//
+// responses:
+//
+//	'200':
+//	  description: Rate created successfully.
+//	  schema:
+//	    "$ref": "#/definitions/CreateFxRateResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *FxRates) Create(ctx *gin.Context) {
+	var createRequest dto.CreateFxRateRequest
+
+	if err := ctx.BindJSON(&createRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid request payload"))
+		return
+	}
+	response := a.server.Create(ctx, &createRequest)
+	SendResponse(ctx, response)
+}
+
+// List retrieves a list of exchange rates.
+// swagger:operation POST /fx-rates/list ListFxRates
+//
+// Retrieves a list of exchange rates.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - in: body
+//     name: body
+//     description: The filters for the rates.
+//     required: true
+//     schema:
+//     "$ref": "#/definitions/ListFxRateRequest"
+//
+// responses:
+//
+//	'200':
+//	  description: Rates retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListFxRateResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *FxRates) List(ctx *gin.Context) {
+	var listRequest dto.ListFxRateRequest
+
+	if err := ctx.BindJSON(&listRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid request payload"))
+		return
+	}
+	response := a.server.List(ctx, &listRequest)
+	SendResponse(ctx, response)
+}
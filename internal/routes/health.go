@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"transaction-server/internal/health"
+)
+
+// Health represents the route handler for the liveness and readiness probes.
+type Health struct {
+	checker *health.Checker
+}
+
+// NewHealthRoute creates a new Health route handler.
+func NewHealthRoute(checker *health.Checker) *Health {
+	return &Health{
+		checker: checker,
+	}
+}
+
+// Live reports that the process is up and serving.
+// swagger:operation GET /livez Live
+//
+// Reports the server is alive. It checks no dependency, so that an outage of one does not
+// get the server restarted.
+// ---
+// produces:
+// - application/json
+//
+// responses:
+//
+//	'200':
+//	  description: The server is alive.
+func (h *Health) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+// Ready reports whether the dependencies of the server are usable.
+// swagger:operation GET /readyz Ready
+//
+// Reports whether the server is ready to serve: the database answers and is migrated to
+// the version the server expects. The status of each dependency is reported.
+// ---
+// produces:
+// - application/json
+//
+// responses:
+//
+//	'200':
+//	  description: The server is ready.
+//	'503':
+//	  description: A dependency is unavailable.
+func (h *Health) Ready(ctx *gin.Context) {
+	report := h.checker.Ready(ctx)
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
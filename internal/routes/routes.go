@@ -3,31 +3,92 @@ package routes
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/url"
+	"strconv"
+	"strings"
+	"transaction-server/app"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/common"
+	"transaction-server/internal/common/logger"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/metrics"
+	"transaction-server/internal/ratelimit"
 	"transaction-server/internal/registry"
+	"transaction-server/internal/tenant"
+	"transaction-server/internal/timeout"
+	"transaction-server/internal/tracing"
 )
 
-func RegisterRoutes(ctx context.Context) *gin.Engine {
-
-	apiRegistry := registry.NewRegistry(ctx)
+func RegisterRoutes(ctx context.Context, apiRegistry registry.IRegistry) *gin.Engine {
 
 	accountsRoute := NewAccountsRoute(apiRegistry.GetAccountsServer())
-	transactionsRoute := NewTransactionsRoute(apiRegistry.GetTransactionsServer())
+	transactionsRoute := NewTransactionsRoute(apiRegistry.GetTransactionsServer(), app.Context().Config().App.ExportWriteTimeout)
+	fxRatesRoute := NewFxRatesRoute(apiRegistry.GetFxRatesServer())
+	webhooksRoute := NewWebhooksRoute(apiRegistry.GetWebhooksServer())
+	transactionStreamsRoute := NewTransactionStreamsRoute(apiRegistry.GetTransactionStreamsServer())
+	healthRoute := NewHealthRoute(apiRegistry.GetHealthChecker())
+	adminRoute := NewAdminRoute(apiRegistry.GetAdminServer())
 
-	router := gin.Default()
+	// Requests are traced, then logged with their ID and trace by the logger of the
+	// application rather than gin's.
+	router := gin.New()
+	router.Use(tracing.Middleware(), logger.Middleware(app.Context().Logger()), gin.Recovery(), metrics.Middleware())
+	// Lets the request context, carrying the authenticated client and tenant, reach core code.
+	router.ContextWithFallback = true
 	router.POST("/health/check", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status": "ok",
 		})
 	})
+	router.GET("/livez", healthRoute.Live)
+	router.GET("/readyz", healthRoute.Ready)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Every route but the health checks is bounded by its timeout, requires a signed request
+	// when authentication is on, is rate limited per client when rate limiting is on, and is
+	// scoped to the tenant of the request. With authentication off, requests are served as
+	// the system.
+	api := router.Group("")
+	api.Use(timeout.Middleware(app.Context().Config().Timeout))
+	if authenticator := apiRegistry.GetAuthenticator(); authenticator != nil {
+		api.Use(auth.Middleware(authenticator, app.Context().Config().Auth))
+	}
+	if rateLimiter := apiRegistry.GetRateLimiter(); rateLimiter != nil {
+		api.Use(ratelimit.Middleware(rateLimiter))
+	}
+	api.Use(tenant.Middleware(apiRegistry.GetTenantResolver()))
+	if apiRegistry.GetAuthenticator() == nil {
+		api.Use(auth.SystemMiddleware())
+	}
 
-	router.GET("/accounts/:accountId", accountsRoute.Get)
-	router.POST("/accounts", accountsRoute.Create)
+	api.GET("/accounts/:accountId", accountsRoute.Get)
+	api.POST("/accounts", accountsRoute.Create)
+	api.GET("/accounts", accountsRoute.List)
+	api.GET("/accounts/:accountId/transactions", transactionsRoute.ListByAccount)
+	api.GET("/accounts/:accountId/transactions/export", transactionsRoute.Export)
+	api.GET("/accounts/:accountId/transactions/stream", transactionStreamsRoute.Stream)
 
-	router.GET("/transactions/:transactionId", transactionsRoute.Get)
-	router.POST("/transactions", transactionsRoute.Create)
-	router.POST("/transactions/list", transactionsRoute.List)
+	api.GET("/transactions", transactionsRoute.Search)
+	api.GET("/transactions/:transactionId", transactionsRoute.Get)
+	api.POST("/transactions", transactionsRoute.Create)
+	api.POST("/transactions/list", transactionsRoute.List)
+	api.POST("/transactions/batch", transactionsRoute.BatchCreate)
+
+	api.POST("/fx-rates", fxRatesRoute.Create)
+	api.POST("/fx-rates/list", fxRatesRoute.List)
+
+	api.POST("/webhooks/subscriptions", webhooksRoute.CreateSubscription)
+	api.GET("/webhooks/subscriptions", webhooksRoute.ListSubscriptions)
+	api.GET("/webhooks/subscriptions/:subscriptionId", webhooksRoute.GetSubscription)
+	api.PUT("/webhooks/subscriptions/:subscriptionId", webhooksRoute.UpdateSubscription)
+	api.DELETE("/webhooks/subscriptions/:subscriptionId", webhooksRoute.DeleteSubscription)
+	api.GET("/webhooks/subscriptions/:subscriptionId/deliveries", webhooksRoute.ListDeliveries)
+	api.GET("/webhooks/deliveries/:deliveryId/attempts", webhooksRoute.ListAttempts)
+	api.POST("/webhooks/deliveries/:deliveryId/redeliver", webhooksRoute.Redeliver)
+
+	api.GET("/admin/config", adminRoute.GetConfig)
 
 	return router
 }
@@ -42,6 +103,46 @@ func SendResponse(ctx *gin.Context, response interface{}) {
 	ctx.IndentedJSON(200, response)
 }
 
+// setPaginationLinks sets the RFC 8288 Link header pointing at the pages around the
+// current one. Each link repeats the query of the current request with the parameters of
+// its page replaced, a parameter without values being removed; a nil page is not linked.
+func setPaginationLinks(ctx *gin.Context, next url.Values, prev url.Values) {
+	links := make([]string, 0, 2)
+	for _, link := range []struct {
+		rel  string
+		page url.Values
+	}{{"next", next}, {"prev", prev}} {
+		if link.page == nil {
+			continue
+		}
+		query := ctx.Request.URL.Query()
+		for key, values := range link.page {
+			if len(values) == 0 {
+				query.Del(key)
+				continue
+			}
+			query[key] = values
+		}
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="%s"`, ctx.Request.URL.Path, query.Encode(), link.rel))
+	}
+	if len(links) > 0 {
+		ctx.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+// cursorPage returns the parameters of the page at the cursor, or nil without a cursor.
+func cursorPage(cursor string) url.Values {
+	if cursor == "" {
+		return nil
+	}
+	return url.Values{"cursor": {cursor}, "offset": nil}
+}
+
+// offsetPage returns the parameters of the page at the offset.
+func offsetPage(offset uint32) url.Values {
+	return url.Values{"offset": {strconv.FormatUint(uint64(offset), 10)}, "cursor": nil}
+}
+
 func convertToMap(response interface{}) map[string]interface{} {
 	responseBytes, _ := json.Marshal(response)
 	var result map[string]interface{}
@@ -57,7 +158,22 @@ func writeErrorCodeSpecificStatus(ctx *gin.Context, errorResponse interface{}) {
 		ctx.IndentedJSON(400, errorResponse)
 	case common.ErrNotFoundFailed:
 		ctx.IndentedJSON(404, errorResponse)
+	case common.ErrUnauthorized:
+		ctx.IndentedJSON(401, errorResponse)
+	case common.ErrForbidden:
+		ctx.IndentedJSON(403, errorResponse)
+	case common.ErrPayloadTooLarge:
+		ctx.IndentedJSON(413, errorResponse)
+	case common.ErrRateLimited:
+		ctx.IndentedJSON(429, errorResponse)
+	case common.ErrTimeout:
+		ctx.IndentedJSON(504, errorResponse)
 	default:
+		// The queries of a request whose deadline passed, or whose client left, fail with it.
+		if timeout.ErrorCode(ctx.Request.Context(), errorCode) == common.ErrTimeout {
+			ctx.IndentedJSON(504, &dto.ErrorResponse{Code: common.ErrTimeout, Message: fmt.Sprint(errorMap["message"])})
+			return
+		}
 		ctx.IndentedJSON(500, errorResponse)
 	}
 }
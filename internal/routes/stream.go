@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"errors"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/stream"
+)
+
+// TransactionStreams represents the route handler for live transaction streams.
+type TransactionStreams struct {
+	server stream.IServer
+}
+
+// NewTransactionStreamsRoute creates a new TransactionStreams route handler.
+func NewTransactionStreamsRoute(server stream.IServer) *TransactionStreams {
+	return &TransactionStreams{
+		server: server,
+	}
+}
+
+// Stream pushes the live transactions of an account.
+// swagger:operation GET /accounts/{accountId}/transactions/stream StreamTransactions
+//
+// Streams the transactions of an account as Server-Sent Events: transaction.created with
+// the new transaction and balance.discharged when a later transaction updates the balance
+// of an existing one. Each event carries an id; a client reconnecting with the
+// Last-Event-ID header resumes from the recent events it missed.
+// ---
+// produces:
+// - text/event-stream
+// parameters:
+//   - name: accountId
+//     in: path
+//     description: The ID of the account.
+//     required: true
+//     type: string
+//   - name: Last-Event-ID
+//     in: header
+//     description: The ID of the last event received.
+//     type: string
+//   - name: last_event_id
+//     in: query
+//     description: The ID of the last event received, for clients that cannot set headers.
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: The event stream.
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *TransactionStreams) Stream(ctx *gin.Context) {
+	var streamRequest dto.StreamTransactionRequest
+
+	if err := ctx.ShouldBindQuery(&streamRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	streamRequest.AccountId = ctx.Param("accountId")
+	if streamRequest.LastEventID == "" {
+		streamRequest.LastEventID = ctx.GetHeader("Last-Event-ID")
+	}
+	// The stream lasts as long as the client stays, past the write timeout of the server.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+	w := &eventStreamWriter{ctx: ctx}
+	response := a.server.Stream(ctx, &streamRequest, w)
+	if w.started {
+		if !response.Success {
+			_ = ctx.Error(errors.New(response.Error.Message))
+			ctx.Abort()
+		}
+		return
+	}
+	SendResponse(ctx, response)
+}
+
+// eventStreamWriter sends the event stream headers with the first write, so a stream
+// failing to open still responds with a JSON error. Every write is flushed.
+type eventStreamWriter struct {
+	ctx     *gin.Context
+	started bool
+}
+
+func (e *eventStreamWriter) start() {
+	if !e.started {
+		e.started = true
+		e.ctx.Header("Content-Type", sse.ContentType)
+		e.ctx.Header("Cache-Control", "no-cache")
+		e.ctx.Header("Connection", "keep-alive")
+		// Keeps nginx from buffering the stream.
+		e.ctx.Header("X-Accel-Buffering", "no")
+		e.ctx.Status(http.StatusOK)
+	}
+}
+
+func (e *eventStreamWriter) WriteEvent(event *stream.Event) error {
+	e.start()
+	err := sse.Encode(e.ctx.Writer, sse.Event{Id: event.ID, Event: event.Type, Data: event.Data})
+	if err != nil {
+		return err
+	}
+	e.ctx.Writer.Flush()
+	return nil
+}
+
+func (e *eventStreamWriter) WriteHeartbeat() error {
+	e.start()
+	if _, err := e.ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+		return err
+	}
+	e.ctx.Writer.Flush()
+	return nil
+}
@@ -1,21 +1,30 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"net/url"
+	"time"
+	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/statement"
 	"transaction-server/internal/transaction"
 )
 
 // Transactions represents the route handler for transaction-related endpoints.
 type Transactions struct {
 	server transaction.IServer
+	// exportWriteTimeout bounds each write of a statement export.
+	exportWriteTimeout time.Duration
 }
 
 // NewTransactionsRoute creates a new Transactions route handler.
-func NewTransactionsRoute(server transaction.IServer) *Transactions {
+func NewTransactionsRoute(server transaction.IServer, exportWriteTimeout time.Duration) *Transactions {
 	return &Transactions{
-		server: server,
+		server:             server,
+		exportWriteTimeout: exportWriteTimeout,
 	}
 }
 
@@ -62,6 +71,51 @@ func (a *Transactions) Create(ctx *gin.Context) {
 	SendResponse(ctx, response)
 }
 
+// BatchCreate handles the creation of transactions in bulk.
+// swagger:operation POST /transactions/batch BatchCreate
+//
+// Creates transactions in request order. In atomic mode all transactions are created
+// or none are; in best_effort mode each transaction succeeds or fails on its own and
+// the outcome is reported per item.
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+//   - in: body
+//     name: body
+//     description: The transactions to be created.
+//     required: true
+//     schema:
+//     "$ref": "#/definitions/BatchCreateTransactionRequest"
+//
+// responses:
+//
+//	'200':
+//	  description: Batch processed.
+//	  schema:
+//	    "$ref": "#/definitions/BatchCreateTransactionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Transactions) BatchCreate(ctx *gin.Context) {
+	var batchRequest dto.BatchCreateTransactionRequest
+
+	if err := ctx.BindJSON(&batchRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid request payload"))
+		return
+	}
+	response := a.server.BatchCreate(ctx, &batchRequest)
+	SendResponse(ctx, response)
+}
+
 // Get retrieves a transaction by ID.
 // swagger:operation GET /transactions/{transactionId} Get
 //
@@ -136,3 +190,233 @@ func (a *Transactions) List(ctx *gin.Context) {
 	response := a.server.List(ctx, &listRequest)
 	SendResponse(ctx, response)
 }
+
+// Search retrieves a list of transactions filtered by query parameters.
+// swagger:operation GET /transactions Search
+//
+// Retrieves a list of transactions. Accepts the same filters as POST /transactions/list
+// as query parameters; operation_types may be repeated. Pagination links are returned
+// in the Link header.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: account_id
+//     in: query
+//     type: string
+//   - name: operation_type
+//     in: query
+//     type: string
+//   - name: operation_types
+//     in: query
+//     type: array
+//     items:
+//     type: string
+//     collectionFormat: multi
+//   - name: cursor
+//     in: query
+//     type: string
+//   - name: limit
+//     in: query
+//     type: integer
+//
+// responses:
+//
+//	'200':
+//	  description: Transactions retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListTransactionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Transactions) Search(ctx *gin.Context) {
+	var listRequest dto.ListTransactionRequest
+
+	if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	a.sendPage(ctx, &listRequest)
+}
+
+// ListByAccount retrieves a list of transactions of an account.
+// swagger:operation GET /accounts/{accountId}/transactions ListByAccount
+//
+// Retrieves a list of transactions of an account. Accepts the same query parameters
+// as GET /transactions except account_id.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: accountId
+//     in: path
+//     description: The ID of the account.
+//     required: true
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: Transactions retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListTransactionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Transactions) ListByAccount(ctx *gin.Context) {
+	var listRequest dto.ListTransactionRequest
+
+	if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	listRequest.AccountId = ctx.Param("accountId")
+	a.sendPage(ctx, &listRequest)
+}
+
+// sendPage lists the transactions and links the adjacent pages in the Link header: by
+// cursor, or by offset when sorted by another field than created_at or given an offset.
+// An offset page is followed by a next one whenever it is full.
+func (a *Transactions) sendPage(ctx *gin.Context, listRequest *dto.ListTransactionRequest) {
+	response := a.server.List(ctx, listRequest)
+	if response.Success {
+		if transaction.IsCursorPaginated(listRequest) {
+			setPaginationLinks(ctx, cursorPage(response.NextCursor), cursorPage(response.PrevCursor))
+		} else {
+			setPaginationLinks(ctx, nextOffsetPage(listRequest, len(response.Transactions)), prevOffsetPage(listRequest))
+		}
+	}
+	SendResponse(ctx, response)
+}
+
+// nextOffsetPage returns the parameters of the page after the offset page holding count
+// transactions, or nil when it is not full.
+func nextOffsetPage(listRequest *dto.ListTransactionRequest, count int) url.Values {
+	limit := listRequest.Limit
+	if limit == 0 {
+		limit = db.DefaultLimit
+	}
+	if uint32(count) < limit {
+		return nil
+	}
+	return offsetPage(listRequest.Offset + limit)
+}
+
+// prevOffsetPage returns the parameters of the page before the offset page, or nil on the
+// first page.
+func prevOffsetPage(listRequest *dto.ListTransactionRequest) url.Values {
+	if listRequest.Offset == 0 {
+		return nil
+	}
+	limit := listRequest.Limit
+	if limit == 0 {
+		limit = db.DefaultLimit
+	}
+	if listRequest.Offset < limit {
+		return offsetPage(0)
+	}
+	return offsetPage(listRequest.Offset - limit)
+}
+
+// Export downloads the statement of an account.
+// swagger:operation GET /accounts/{accountId}/transactions/export Export
+//
+// Streams the transactions of an account between two event dates as a statement with
+// signed amounts, opening and closing balances and, in CSV, running balances.
+// ---
+// produces:
+// - text/csv
+// - application/x-ofx
+// - application/xml
+// parameters:
+//   - name: accountId
+//     in: path
+//     description: The ID of the account.
+//     required: true
+//     type: string
+//   - name: format
+//     in: query
+//     description: The statement format, one of csv, ofx or camt053.
+//     required: true
+//     type: string
+//   - name: from
+//     in: query
+//     description: The earliest event date (Unix timestamp, inclusive). Defaults to the account creation.
+//     type: integer
+//   - name: to
+//     in: query
+//     description: The latest event date (Unix timestamp, inclusive). Defaults to now.
+//     type: integer
+//
+// responses:
+//
+//	'200':
+//	  description: The statement.
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Transactions) Export(ctx *gin.Context) {
+	var exportRequest dto.ExportTransactionRequest
+
+	if err := ctx.ShouldBindQuery(&exportRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	exportRequest.AccountId = ctx.Param("accountId")
+	w := &statementResponseWriter{ctx: ctx, format: exportRequest.Format, writeTimeout: a.exportWriteTimeout}
+	response := a.server.Export(ctx, &exportRequest, w)
+	if w.started {
+		// The status has been sent with the first bytes of the statement, so a failure
+		// can only cut the statement short.
+		if !response.Success {
+			_ = ctx.Error(errors.New(response.Error.Message))
+			ctx.Abort()
+		}
+		return
+	}
+	SendResponse(ctx, response)
+}
+
+// statementResponseWriter sends the statement headers with the first write, so an
+// export failing before writing anything still responds with a JSON error. The statement
+// takes as long as the account has transactions, so each write pushes the write deadline
+// of the connection back by writeTimeout, in place of the write timeout of the server.
+type statementResponseWriter struct {
+	ctx          *gin.Context
+	format       string
+	writeTimeout time.Duration
+	started      bool
+}
+
+func (s *statementResponseWriter) Write(p []byte) (int, error) {
+	err := http.NewResponseController(s.ctx.Writer).SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return 0, err
+	}
+	if !s.started {
+		s.started = true
+		filename := fmt.Sprintf("statement-%s.%s", s.ctx.Param("accountId"), statement.FileExtension(s.format))
+		s.ctx.Header("Content-Type", statement.ContentType(s.format))
+		s.ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		s.ctx.Status(http.StatusOK)
+	}
+	return s.ctx.Writer.Write(p)
+}
@@ -0,0 +1,206 @@
+package routes_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/routes"
+	"transaction-server/internal/transaction"
+)
+
+// transactionsServer serves the listings with list and the exports with export, failing
+// the test on any other call.
+type transactionsServer struct {
+	transaction.IServer
+	list   func(req *dto.ListTransactionRequest) *dto.ListTransactionResponse
+	export func(w io.Writer) *dto.ExportTransactionResponse
+}
+
+func (s *transactionsServer) List(_ *gin.Context, req *dto.ListTransactionRequest) *dto.ListTransactionResponse {
+	return s.list(req)
+}
+
+func (s *transactionsServer) Export(_ *gin.Context, _ *dto.ExportTransactionRequest, w io.Writer) *dto.ExportTransactionResponse {
+	return s.export(w)
+}
+
+// serveList sends the request to the transaction listing routes and returns the response
+// and the listing request bound from it, if any.
+func serveList(t *testing.T, target string, response *dto.ListTransactionResponse) (*httptest.ResponseRecorder, *dto.ListTransactionRequest) {
+	gin.SetMode(gin.TestMode)
+	var bound *dto.ListTransactionRequest
+	route := routes.NewTransactionsRoute(&transactionsServer{list: func(req *dto.ListTransactionRequest) *dto.ListTransactionResponse {
+		bound = req
+		return response
+	}}, time.Minute)
+	router := gin.New()
+	router.GET("/transactions", route.Search)
+	router.GET("/accounts/:accountId/transactions", route.ListByAccount)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder, bound
+}
+
+// links returns the targets of the Link header by relation.
+func links(t *testing.T, header string) map[string]*url.URL {
+	result := make(map[string]*url.URL)
+	if header == "" {
+		return result
+	}
+	for _, link := range strings.Split(header, ", ") {
+		parts := strings.SplitN(link, "; ", 2)
+		require.Len(t, parts, 2, link)
+		target, err := url.Parse(strings.Trim(parts[0], "<>"))
+		require.NoError(t, err)
+		result[strings.TrimSuffix(strings.TrimPrefix(parts[1], `rel="`), `"`)] = target
+	}
+	return result
+}
+
+func success() *dto.ListTransactionResponse {
+	return &dto.ListTransactionResponse{Base: &dto.Base{Success: true}}
+}
+
+// page returns a successful response holding count transactions.
+func page(count int) *dto.ListTransactionResponse {
+	response := success()
+	for i := 0; i < count; i++ {
+		response.Transactions = append(response.Transactions, &dto.Transaction{})
+	}
+	return response
+}
+
+func TestTransactions_Search_Binds_Query(t *testing.T) {
+	recorder, bound := serveList(t, "/transactions?account_id=a0000000000001&operation_types=Withdraw&operation_types=Normal_Purchase"+
+		"&currency=USD&event_date_from=1700000000&min_amount=-10.5&only_open_balance=true&sort_by=amount&sort_order=asc&limit=5&offset=10", success())
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.NotNil(t, bound)
+	assert.Equal(t, "a0000000000001", bound.AccountId)
+	assert.Equal(t, []string{"Withdraw", "Normal_Purchase"}, bound.OperationTypes)
+	assert.Equal(t, "USD", bound.Currency)
+	assert.Equal(t, int64(1700000000), bound.EventDateFrom)
+	require.NotNil(t, bound.MinAmount)
+	assert.Equal(t, -10.5, *bound.MinAmount)
+	assert.Nil(t, bound.MaxAmount)
+	assert.True(t, bound.OnlyOpenBalance)
+	assert.Equal(t, "amount", bound.SortBy)
+	assert.Equal(t, dto.SortOrderAsc, bound.SortOrder)
+	assert.Equal(t, uint32(5), bound.Limit)
+	assert.Equal(t, uint32(10), bound.Offset)
+}
+
+func TestTransactions_ListByAccount_Takes_The_Account_From_The_Path(t *testing.T) {
+	recorder, bound := serveList(t, "/accounts/a0000000000001/transactions?account_id=a0000000000002", success())
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.NotNil(t, bound)
+	assert.Equal(t, "a0000000000001", bound.AccountId)
+}
+
+func TestTransactions_Search_Rejects_Invalid_Query(t *testing.T) {
+	for name, query := range map[string]string{
+		"limit":             "limit=ten",
+		"negative offset":   "offset=-1",
+		"min amount":        "min_amount=lots",
+		"event date":        "event_date_from=yesterday",
+		"only open balance": "only_open_balance=maybe",
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder, bound := serveList(t, "/transactions?"+query, success())
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Nil(t, bound)
+		})
+	}
+}
+
+func TestTransactions_Search_Links_Cursor_Pages(t *testing.T) {
+	response := page(2)
+	response.NextCursor, response.PrevCursor = "next-cursor", "prev-cursor"
+	recorder, _ := serveList(t, "/transactions?account_id=a0000000000001&operation_types=Withdraw&operation_types=Normal_Purchase"+
+		"&limit=2&offset=0&cursor=current-cursor", response)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	pageLinks := links(t, recorder.Header().Get("Link"))
+	require.Len(t, pageLinks, 2)
+	for rel, cursor := range map[string]string{"next": "next-cursor", "prev": "prev-cursor"} {
+		link := pageLinks[rel]
+		require.NotNil(t, link, rel)
+		assert.Equal(t, "/transactions", link.Path)
+		query := link.Query()
+		assert.Equal(t, []string{cursor}, query["cursor"])
+		assert.NotContains(t, query, "offset")
+		assert.Equal(t, []string{"a0000000000001"}, query["account_id"])
+		assert.Equal(t, []string{"Withdraw", "Normal_Purchase"}, query["operation_types"])
+		assert.Equal(t, []string{"2"}, query["limit"])
+	}
+}
+
+func TestTransactions_Search_Links_Offset_Pages(t *testing.T) {
+	recorder, _ := serveList(t, "/accounts/a0000000000001/transactions?sort_by=amount&operation_types=Withdraw&limit=5&offset=7", page(5))
+
+	pageLinks := links(t, recorder.Header().Get("Link"))
+	require.Len(t, pageLinks, 2)
+	assert.Equal(t, "/accounts/a0000000000001/transactions", pageLinks["next"].Path)
+	assert.Equal(t, url.Values{"sort_by": {"amount"}, "operation_types": {"Withdraw"}, "limit": {"5"}, "offset": {"12"}}, pageLinks["next"].Query())
+	assert.Equal(t, url.Values{"sort_by": {"amount"}, "operation_types": {"Withdraw"}, "limit": {"5"}, "offset": {"2"}}, pageLinks["prev"].Query())
+
+	// The last page, not full, links back only; the first of the pages links forward only.
+	recorder, _ = serveList(t, "/transactions?sort_by=amount&limit=5&offset=3", page(4))
+	pageLinks = links(t, recorder.Header().Get("Link"))
+	require.Len(t, pageLinks, 1)
+	assert.Equal(t, url.Values{"sort_by": {"amount"}, "limit": {"5"}, "offset": {"0"}}, pageLinks["prev"].Query())
+
+	recorder, _ = serveList(t, "/transactions?sort_by=balance", page(10))
+	pageLinks = links(t, recorder.Header().Get("Link"))
+	require.Len(t, pageLinks, 1)
+	assert.Equal(t, url.Values{"sort_by": {"balance"}, "offset": {"10"}}, pageLinks["next"].Query())
+}
+
+func TestTransactions_Search_Does_Not_Link_Failed_Pages(t *testing.T) {
+	recorder, _ := serveList(t, "/transactions?sort_by=amount&limit=5&offset=5", &dto.ListTransactionResponse{
+		Base: dto.GetErrorResponse(common.ErrValidationFailed, "invalid"),
+	})
+
+	assert.Empty(t, recorder.Header().Get("Link"))
+}
+
+func TestTransactions_Export_Outlasts_The_Write_Timeout_While_Writing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	route := routes.NewTransactionsRoute(&transactionsServer{export: func(w io.Writer) *dto.ExportTransactionResponse {
+		for i := 0; i < 5; i++ {
+			time.Sleep(40 * time.Millisecond)
+			if _, err := w.Write([]byte("entry\n")); err != nil {
+				return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+			}
+		}
+		return &dto.ExportTransactionResponse{Base: &dto.Base{Success: true}}
+	}}, time.Second)
+	router := gin.New()
+	router.GET("/accounts/:accountId/transactions/export", route.Export)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/accounts/a0000000000001/transactions/export?format=csv")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, strings.Repeat("entry\n", 5), string(body))
+}
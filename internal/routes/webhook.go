@@ -0,0 +1,338 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/webhook"
+)
+
+// Webhooks represents the route handler for webhook endpoints.
+type Webhooks struct {
+	server webhook.IServer
+}
+
+// NewWebhooksRoute creates a new Webhooks route handler.
+func NewWebhooksRoute(server webhook.IServer) *Webhooks {
+	return &Webhooks{
+		server: server,
+	}
+}
+
+// CreateSubscription handles the creation of a new webhook subscription.
+// swagger:operation POST /webhooks/subscriptions CreateWebhookSubscription
+//
+// Creates a subscription notified of the listed event types. The response holds the
+// signing secret, which is not returned again.
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+//   - in: body
+//     name: body
+//     description: The subscription to be created.
+//     required: true
+//     schema:
+//     "$ref": "#/definitions/CreateWebhookSubscriptionRequest"
+//
+// responses:
+//
+//	'200':
+//	  description: Subscription created successfully.
+//	  schema:
+//	    "$ref": "#/definitions/WebhookSubscriptionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) CreateSubscription(ctx *gin.Context) {
+	var createRequest dto.CreateWebhookSubscriptionRequest
+
+	if err := ctx.BindJSON(&createRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid request payload"))
+		return
+	}
+	response := a.server.CreateSubscription(ctx, &createRequest)
+	SendResponse(ctx, response)
+}
+
+// ListSubscriptions retrieves a list of webhook subscriptions.
+// swagger:operation GET /webhooks/subscriptions ListWebhookSubscriptions
+//
+// Retrieves a list of webhook subscriptions.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: limit
+//     in: query
+//     type: integer
+//   - name: offset
+//     in: query
+//     type: integer
+//
+// responses:
+//
+//	'200':
+//	  description: Subscriptions retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListWebhookSubscriptionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) ListSubscriptions(ctx *gin.Context) {
+	var listRequest dto.ListWebhookSubscriptionRequest
+
+	if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	response := a.server.ListSubscriptions(ctx, &listRequest)
+	SendResponse(ctx, response)
+}
+
+// GetSubscription retrieves a webhook subscription by its ID.
+// swagger:operation GET /webhooks/subscriptions/{subscriptionId} GetWebhookSubscription
+//
+// Retrieves a webhook subscription by its ID, without its secret.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: subscriptionId
+//     in: path
+//     description: The ID of the subscription.
+//     required: true
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: Subscription retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/WebhookSubscriptionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) GetSubscription(ctx *gin.Context) {
+	response := a.server.GetSubscription(ctx, ctx.Param("subscriptionId"))
+	SendResponse(ctx, response)
+}
+
+// UpdateSubscription replaces a webhook subscription.
+// swagger:operation PUT /webhooks/subscriptions/{subscriptionId} UpdateWebhookSubscription
+//
+// Replaces a webhook subscription. A secret in the body rotates the signing secret;
+// without one the current secret is kept.
+// ---
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+//   - name: subscriptionId
+//     in: path
+//     description: The ID of the subscription.
+//     required: true
+//     type: string
+//   - in: body
+//     name: body
+//     description: The new state of the subscription.
+//     required: true
+//     schema:
+//     "$ref": "#/definitions/UpdateWebhookSubscriptionRequest"
+//
+// responses:
+//
+//	'200':
+//	  description: Subscription updated successfully.
+//	  schema:
+//	    "$ref": "#/definitions/WebhookSubscriptionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) UpdateSubscription(ctx *gin.Context) {
+	var updateRequest dto.UpdateWebhookSubscriptionRequest
+
+	if err := ctx.BindJSON(&updateRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid request payload"))
+		return
+	}
+	updateRequest.ID = ctx.Param("subscriptionId")
+	response := a.server.UpdateSubscription(ctx, &updateRequest)
+	SendResponse(ctx, response)
+}
+
+// DeleteSubscription deletes a webhook subscription.
+// swagger:operation DELETE /webhooks/subscriptions/{subscriptionId} DeleteWebhookSubscription
+//
+// Deletes a webhook subscription. Its pending deliveries are dead-lettered.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: subscriptionId
+//     in: path
+//     description: The ID of the subscription.
+//     required: true
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: Subscription deleted successfully.
+//	  schema:
+//	    "$ref": "#/definitions/DeleteWebhookSubscriptionResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) DeleteSubscription(ctx *gin.Context) {
+	response := a.server.DeleteSubscription(ctx, ctx.Param("subscriptionId"))
+	SendResponse(ctx, response)
+}
+
+// ListDeliveries retrieves the deliveries of a webhook subscription.
+// swagger:operation GET /webhooks/subscriptions/{subscriptionId}/deliveries ListWebhookDeliveries
+//
+// Retrieves the deliveries of a webhook subscription, newest first.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: subscriptionId
+//     in: path
+//     description: The ID of the subscription.
+//     required: true
+//     type: string
+//   - name: status
+//     in: query
+//     description: Only deliveries in this state, pending, succeeded or dead.
+//     type: string
+//   - name: limit
+//     in: query
+//     type: integer
+//   - name: offset
+//     in: query
+//     type: integer
+//
+// responses:
+//
+//	'200':
+//	  description: Deliveries retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListWebhookDeliveryResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) ListDeliveries(ctx *gin.Context) {
+	var listRequest dto.ListWebhookDeliveryRequest
+
+	if err := ctx.ShouldBindQuery(&listRequest); err != nil {
+		// Handle client error
+		ctx.JSON(http.StatusBadRequest, dto.GetErrorResponse("BadRequest", "Invalid query parameters"))
+		return
+	}
+	listRequest.SubscriptionID = ctx.Param("subscriptionId")
+	response := a.server.ListDeliveries(ctx, &listRequest)
+	SendResponse(ctx, response)
+}
+
+// ListAttempts retrieves the attempts of a webhook delivery.
+// swagger:operation GET /webhooks/deliveries/{deliveryId}/attempts ListWebhookAttempts
+//
+// Retrieves the attempts of a webhook delivery, oldest first.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: deliveryId
+//     in: path
+//     description: The ID of the delivery.
+//     required: true
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: Attempts retrieved successfully.
+//	  schema:
+//	    "$ref": "#/definitions/ListWebhookAttemptResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) ListAttempts(ctx *gin.Context) {
+	response := a.server.ListAttempts(ctx, ctx.Param("deliveryId"))
+	SendResponse(ctx, response)
+}
+
+// Redeliver queues a dead-lettered webhook delivery again.
+// swagger:operation POST /webhooks/deliveries/{deliveryId}/redeliver RedeliverWebhook
+//
+// Queues a dead-lettered delivery for immediate delivery with a fresh set of attempts.
+// ---
+// produces:
+// - application/json
+// parameters:
+//   - name: deliveryId
+//     in: path
+//     description: The ID of the delivery.
+//     required: true
+//     type: string
+//
+// responses:
+//
+//	'200':
+//	  description: Delivery queued successfully.
+//	  schema:
+//	    "$ref": "#/definitions/WebhookDeliveryResponse"
+//	'4xx':
+//	  description: Client Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+//	'5xx':
+//	  description: Server Error
+//	  schema:
+//	    "$ref": "#/definitions/ErrorResponse"
+func (a *Webhooks) Redeliver(ctx *gin.Context) {
+	response := a.server.Redeliver(ctx, ctx.Param("deliveryId"))
+	SendResponse(ctx, response)
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/account"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/rpc/pb"
+)
+
+// AccountService implements pb.AccountServiceServer over account.IServer.
+type AccountService struct {
+	pb.UnimplementedAccountServiceServer
+	server account.IServer
+}
+
+func NewAccountService(server account.IServer) *AccountService {
+	return &AccountService{server: server}
+}
+
+func (s *AccountService) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	createRequest := &dto.CreateAccountRequest{Account: &dto.Account{}}
+	if req.GetAccount() != nil {
+		createRequest.Account = accountFromProto(req.GetAccount())
+	}
+	response := serve(ctx, func(c *gin.Context) *dto.CreateAccountResponse { return s.server.Create(c, createRequest) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	return &pb.CreateAccountResponse{Account: accountToProto(response.Account)}, nil
+}
+
+func (s *AccountService) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	response := serve(ctx, func(c *gin.Context) *dto.GetAccountResponse { return s.server.Get(c, req.GetId()) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	return &pb.GetAccountResponse{Account: accountToProto(response.Account)}, nil
+}
+
+func (s *AccountService) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	listRequest := &dto.ListAccountRequest{
+		Limit:          req.GetLimit(),
+		Offset:         req.GetOffset(),
+		DocumentNumber: req.GetDocumentNumber(),
+	}
+	response := serve(ctx, func(c *gin.Context) *dto.ListAccountResponse { return s.server.List(c, listRequest) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	accounts := make([]*pb.Account, 0, len(response.Accounts))
+	for _, a := range response.Accounts {
+		accounts = append(accounts, accountToProto(a))
+	}
+	return &pb.ListAccountsResponse{Accounts: accounts}, nil
+}
+
+// StreamAccounts sends the accounts page by page until the last page or the client cancels.
+func (s *AccountService) StreamAccounts(req *pb.StreamAccountsRequest, stream pb.AccountService_StreamAccountsServer) error {
+	listRequest := &dto.ListAccountRequest{Limit: streamPageSize, DocumentNumber: req.GetDocumentNumber()}
+	for {
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		response := serve(stream.Context(), func(c *gin.Context) *dto.ListAccountResponse { return s.server.List(c, listRequest) })
+		if err := statusError(response.Base); err != nil {
+			return err
+		}
+		for _, a := range response.Accounts {
+			if err := stream.Send(accountToProto(a)); err != nil {
+				return err
+			}
+		}
+		if len(response.Accounts) < streamPageSize {
+			return nil
+		}
+		listRequest.Offset += streamPageSize
+	}
+}
+
+func accountToProto(a *dto.Account) *pb.Account {
+	return &pb.Account{
+		Id:             a.ID,
+		Name:           a.Name,
+		DocumentNumber: a.DocumentNumber,
+		Currency:       a.Currency,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+func accountFromProto(a *pb.Account) *dto.Account {
+	return &dto.Account{
+		Name:           a.GetName(),
+		DocumentNumber: a.GetDocumentNumber(),
+		Currency:       a.GetCurrency(),
+	}
+}
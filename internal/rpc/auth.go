@@ -0,0 +1,169 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"transaction-server/internal/auth"
+)
+
+// Calls are signed as HTTP requests would be, with the full method as the path and the
+// deterministic encoding of the request message as the body. The credentials travel in the
+// authorization and content digest metadata.
+var marshalOptions = proto.MarshalOptions{Deterministic: true}
+
+var (
+	metadataAuthorization = "authorization"
+	metadataContentDigest = strings.ToLower(auth.HeaderContentDigest)
+)
+
+// UnaryAuthInterceptor authenticates each unary call, failing it with Unauthenticated when
+// its signature is missing or invalid, and puts the identity of the client on its context.
+func UnaryAuthInterceptor(core auth.ICore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, core, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates each streaming call by its request message, which
+// is signed like the request of a unary call.
+func StreamAuthInterceptor(core auth.ICore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ss.Context(), core: core, method: info.FullMethod})
+	}
+}
+
+// UnarySystemInterceptor serves each unary call as the system, in place of
+// UnaryAuthInterceptor when authentication is disabled. It comes last, for the interceptors
+// telling the clients apart, such as rate limiting, to key the calls by peer address.
+func UnarySystemInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(auth.WithSystemIdentity(ctx), req)
+	}
+}
+
+// StreamSystemInterceptor serves each streaming call as the system, in place of
+// StreamAuthInterceptor when authentication is disabled.
+func StreamSystemInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &systemStream{ServerStream: ss})
+	}
+}
+
+// systemStream serves the call as the system. Its context is derived on each read, as the
+// context of the stream it wraps is only complete once the request message is received.
+type systemStream struct {
+	grpc.ServerStream
+}
+
+func (s *systemStream) Context() context.Context {
+	return auth.WithSystemIdentity(s.ServerStream.Context())
+}
+
+// authenticatedStream authenticates the call when its request message is received, before
+// the handler reads the context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx           context.Context
+	core          auth.ICore
+	method        string
+	authenticated bool
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authenticatedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authenticated {
+		return nil
+	}
+	ctx, err := authenticate(s.ctx, s.core, s.method, m)
+	if err != nil {
+		return err
+	}
+	s.ctx, s.authenticated = ctx, true
+	return nil
+}
+
+func authenticate(ctx context.Context, core auth.ICore, method string, req interface{}) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	credentials, err := auth.ParseAuthorization(first(md, metadataAuthorization))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	digest, err := messageDigest(req)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !hmac.Equal([]byte(first(md, metadataContentDigest)), []byte(digest)) {
+		return nil, status.Error(codes.Unauthenticated, auth.ErrBodyDigestMismatch.Error())
+	}
+	credentials.Method = http.MethodPost
+	credentials.Path = method
+	credentials.BodyDigest = digest
+	identity, err := core.Authenticate(ctx, credentials)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return auth.WithIdentity(ctx, identity), nil
+}
+
+// SignContext returns a copy of ctx with the outgoing metadata signing a call of the
+// method with the request message, for clients of the API.
+func SignContext(ctx context.Context, apiKey string, method string, req proto.Message, now time.Time) (context.Context, error) {
+	keyID, secret, err := auth.ParseAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := messageDigest(req)
+	if err != nil {
+		return nil, err
+	}
+	credentials := &auth.Credentials{
+		KeyID:      keyID,
+		Timestamp:  now.Unix(),
+		Method:     http.MethodPost,
+		Path:       method,
+		BodyDigest: digest,
+	}
+	credentials.Signature = credentials.Sign(auth.SigningKey(secret))
+	return metadata.AppendToOutgoingContext(ctx,
+		metadataAuthorization, credentials.Authorization(),
+		metadataContentDigest, digest,
+	), nil
+}
+
+func messageDigest(req interface{}) (string, error) {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return auth.Digest(nil), nil
+	}
+	data, err := marshalOptions.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	return auth.Digest(data), nil
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/account"
+	"transaction-server/internal/auth"
+	authmock "transaction-server/internal/auth/mock"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+)
+
+const apiKey = "0a000000000000.secret"
+
+var identity = &auth.Identity{ClientID: "0c000000000000", ClientName: "partner", KeyID: "0a000000000000", Role: "admin"}
+
+func setupAuthTest(t *testing.T) (*testDependencies, *authmock.MockICore) {
+	authCore := authmock.NewMockICore(gomock.NewController(t))
+	td := setupServer(t,
+		grpc.UnaryInterceptor(rpc.UnaryAuthInterceptor(authCore)),
+		grpc.StreamInterceptor(rpc.StreamAuthInterceptor(authCore)),
+	)
+	return td, authCore
+}
+
+// expectSigned expects a call of the method signed with apiKey and authenticates it.
+func expectSigned(t *testing.T, authCore *authmock.MockICore, method string) {
+	authCore.EXPECT().Authenticate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, credentials *auth.Credentials) (*auth.Identity, error) {
+			assert.Equal(t, method, credentials.Path)
+			assert.Equal(t, credentials.Sign(auth.SigningKey("secret")), credentials.Signature)
+			return identity, nil
+		})
+}
+
+func TestUnaryAuthInterceptor_Authenticates_Signed_Call(t *testing.T) {
+	td, authCore := setupAuthTest(t)
+	expectSigned(t, authCore, pb.AccountService_GetAccount_FullMethodName)
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, a *account.Account, id string) error {
+			got, ok := auth.IdentityFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, identity, got)
+			a.ID = id
+			return nil
+		})
+
+	req := &pb.GetAccountRequest{Id: "0b0e0000000000"}
+	ctx, err := rpc.SignContext(context.Background(), apiKey, pb.AccountService_GetAccount_FullMethodName, req, time.Now())
+	require.NoError(t, err)
+	resp, err := td.accounts.GetAccount(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "0b0e0000000000", resp.Account.Id)
+}
+
+func TestUnaryAuthInterceptor_Rejects(t *testing.T) {
+	td, _ := setupAuthTest(t)
+
+	_, err := td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The signature covers the request message.
+	ctx, err := rpc.SignContext(context.Background(), apiKey, pb.AccountService_GetAccount_FullMethodName,
+		&pb.GetAccountRequest{Id: "0b0e0000000000"}, time.Now())
+	require.NoError(t, err)
+	_, err = td.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000001"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, auth.ErrBodyDigestMismatch.Error(), status.Convert(err).Message())
+}
+
+func TestStreamAuthInterceptor_Authenticates_Signed_Call(t *testing.T) {
+	td, authCore := setupAuthTest(t)
+	expectSigned(t, authCore, pb.AccountService_StreamAccounts_FullMethodName)
+	td.accountCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ account.IListRequest) (*[]account.Account, error) {
+			got, _ := auth.IdentityFromContext(ctx)
+			assert.Equal(t, identity, got)
+			return &[]account.Account{}, nil
+		})
+
+	req := &pb.StreamAccountsRequest{DocumentNumber: "123456789"}
+	ctx, err := rpc.SignContext(context.Background(), apiKey, pb.AccountService_StreamAccounts_FullMethodName, req, time.Now())
+	require.NoError(t, err)
+	stream, err := td.accounts.StreamAccounts(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = td.accounts.StreamAccounts(context.Background(), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
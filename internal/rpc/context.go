@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// engine serves the gRPC calls to the servers as HTTP requests, for them to get a
+// *gin.Context as complete as over HTTP. Its contexts fall back to the call context, so
+// cancellation and deadlines reach the database queries.
+var engine = newEngine()
+
+// handlerKey is the context key of the function serve runs with the *gin.Context.
+type handlerKey struct{}
+
+func newEngine() *gin.Engine {
+	e := gin.New()
+	e.ContextWithFallback = true
+	e.POST("/*method", handleCall)
+	return e
+}
+
+// handleCall runs the function serve passed in the request context.
+func handleCall(ctx *gin.Context) {
+	ctx.Request.Context().Value(handlerKey{}).(func(*gin.Context))(ctx)
+}
+
+// serve calls handle with the *gin.Context of the gRPC call and returns its response. The
+// call is made an HTTP request: a POST to its full method, with its metadata as headers and
+// its peer as remote address. The servers return their responses, so whatever is written
+// to the HTTP response is discarded.
+func serve[T any](ctx context.Context, handle func(*gin.Context) T) T {
+	var response T
+	ctx = context.WithValue(ctx, handlerKey{}, func(ginCtx *gin.Context) {
+		response = handle(ginCtx)
+	})
+	engine.ServeHTTP(&discardResponseWriter{header: http.Header{}}, newRequest(ctx))
+	return response
+}
+
+// newRequest returns the HTTP request of the gRPC call of ctx.
+func newRequest(ctx context.Context) *http.Request {
+	method, _ := grpc.Method(ctx)
+	if method == "" {
+		method = "/"
+	}
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		// Pseudo-headers such as :authority are not headers of the request.
+		if !strings.HasPrefix(key, ":") {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	request := &http.Request{
+		Method:     http.MethodPost,
+		URL:        &url.URL{Path: method},
+		RequestURI: method,
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     header,
+		Body:       http.NoBody,
+		Host:       first(md, ":authority"),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		request.RemoteAddr = p.Addr.String()
+	}
+	return request.WithContext(ctx)
+}
+
+// discardResponseWriter is the http.ResponseWriter of the gRPC calls, whose responses are
+// returned by the servers rather than written.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
@@ -0,0 +1,77 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+)
+
+// requestReadingServer is an account server reading the request of its context and
+// writing to its response, as the servers over HTTP may.
+type requestReadingServer struct {
+	requests []*http.Request
+	headers  []string
+	values   []interface{}
+}
+
+type contextKey struct{}
+
+func (s *requestReadingServer) Create(ctx *gin.Context, req *dto.CreateAccountRequest) *dto.CreateAccountResponse {
+	return &dto.CreateAccountResponse{}
+}
+
+func (s *requestReadingServer) Get(ctx *gin.Context, id string) *dto.GetAccountResponse {
+	s.requests = append(s.requests, ctx.Request)
+	s.headers = append(s.headers, ctx.GetHeader("X-Custom"))
+	s.values = append(s.values, ctx.Value(contextKey{}))
+	ctx.Header("X-Served-By", "test")
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+	return &dto.GetAccountResponse{Account: &dto.Account{ID: id}}
+}
+
+func (s *requestReadingServer) List(ctx *gin.Context, req *dto.ListAccountRequest) *dto.ListAccountResponse {
+	return &dto.ListAccountResponse{}
+}
+
+func TestAccountService_Serves_Calls_As_HTTP_Requests(t *testing.T) {
+	server := &requestReadingServer{}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(context.WithValue(ctx, contextKey{}, "from interceptor"), req)
+		}))
+	pb.RegisterAccountServiceServer(grpcServer, rpc.NewAccountService(server))
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-custom", "value")
+	resp, err := pb.NewAccountServiceClient(conn).GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	require.NoError(t, err)
+	assert.Equal(t, "0b0e0000000000", resp.Account.Id)
+
+	require.Len(t, server.requests, 1)
+	request := server.requests[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, pb.AccountService_GetAccount_FullMethodName, request.URL.Path)
+	assert.NotEmpty(t, request.RemoteAddr)
+	assert.Equal(t, "value", server.headers[0])
+	assert.Equal(t, "from interceptor", server.values[0])
+}
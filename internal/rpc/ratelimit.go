@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/ratelimit"
+)
+
+// UnaryRateLimitInterceptor limits the unary calls of each client to each method, keyed like
+// the HTTP requests. Chained after UnaryAuthInterceptor, calls are keyed by client.
+func UnaryRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := takeRateLimit(ctx, limiter, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimitInterceptor limits the streaming calls of each client to each method. The
+// call is counted once its request message is received, after it is authenticated.
+func StreamRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &rateLimitedStream{ServerStream: ss, limiter: limiter, method: info.FullMethod})
+	}
+}
+
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limiter *ratelimit.Limiter
+	method  string
+	taken   bool
+}
+
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.taken {
+		return nil
+	}
+	s.taken = true
+	return takeRateLimit(s.Context(), s.limiter, s.method)
+}
+
+// takeRateLimit takes the call from the bucket of its client, sending the rate limit
+// headers, and fails with ResourceExhausted once the limit is reached.
+func takeRateLimit(ctx context.Context, limiter *ratelimit.Limiter, method string) error {
+	result, limited := limiter.Take(ctx, clientKey(ctx), method)
+	if !limited {
+		return nil
+	}
+	header := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(result.Limit),
+		"ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"ratelimit-reset", strconv.Itoa(ratelimit.Seconds(result.Reset)),
+	)
+	if !result.Allowed {
+		header.Set("retry-after", strconv.Itoa(ratelimit.Seconds(result.RetryAfter)))
+	}
+	_ = grpc.SetHeader(ctx, header)
+	if !result.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %d seconds", ratelimit.Seconds(result.RetryAfter))
+	}
+	return nil
+}
+
+// clientKey returns the key of the client of the call in the buckets.
+func clientKey(ctx context.Context) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		return "client:" + identity.ClientID
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:"
+}
@@ -0,0 +1,34 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/ratelimit"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+)
+
+func TestUnaryRateLimitInterceptor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Routes: map[string]ratelimit.Limit{pb.AccountService_GetAccount_FullMethodName: {Requests: 1}},
+	}, ratelimit.NewMemoryStore())
+	td := setupTest(t, grpc.UnaryInterceptor(rpc.UnaryRateLimitInterceptor(limiter)))
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").Return(nil)
+
+	var header metadata.MD
+	_, err := td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0"}, header.Get("ratelimit-remaining"))
+
+	_, err = td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1"}, header.Get("retry-after"))
+}
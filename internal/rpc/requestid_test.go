@@ -0,0 +1,38 @@
+package rpc_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"transaction-server/internal/account"
+	"transaction-server/internal/common/logger"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+)
+
+func TestUnaryRequestIDInterceptor(t *testing.T) {
+	out := &bytes.Buffer{}
+	td := setupTest(t, grpc.UnaryInterceptor(rpc.UnaryRequestIDInterceptor(logger.New(logger.Config{}, out))))
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, a *account.Account, id string) error {
+			requestID, _ := logger.RequestIDFromContext(ctx)
+			assert.Equal(t, "req-42", requestID)
+			a.ID = id
+			return nil
+		})
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-42")
+	_, err := td.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000000"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-42"}, header.Get("x-request-id"))
+	assert.Contains(t, out.String(), `"msg":"call served"`)
+	assert.Contains(t, out.String(), `"request_id":"req-42"`)
+	assert.Contains(t, out.String(), `"code":"OK"`)
+}
@@ -0,0 +1,23 @@
+// Package rpc serves the account and transaction APIs over gRPC. The services are thin
+// adapters over the same account.IServer and transaction.IServer as the HTTP routes, so
+// both APIs share validation and business rules.
+package rpc
+
+import (
+	"google.golang.org/grpc"
+	"transaction-server/internal/account"
+	"transaction-server/internal/rpc/pb"
+	"transaction-server/internal/transaction"
+)
+
+// streamPageSize is the number of entities read at a time by streaming lists,
+// the largest page the list validators accept.
+const streamPageSize = 20
+
+// NewServer returns a gRPC server with the account and transaction services registered.
+func NewServer(accounts account.IServer, transactions transaction.IServer, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	pb.RegisterAccountServiceServer(server, NewAccountService(accounts))
+	pb.RegisterTransactionServiceServer(server, NewTransactionService(transactions))
+	return server
+}
@@ -0,0 +1,235 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"transaction-server/internal/account"
+	accountmock "transaction-server/internal/account/mock"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+	"transaction-server/internal/transaction"
+	transactionmock "transaction-server/internal/transaction/mock"
+)
+
+type testDependencies struct {
+	accountCore     *accountmock.MockICore
+	transactionCore *transactionmock.MockICore
+	accounts        pb.AccountServiceClient
+	transactions    pb.TransactionServiceClient
+}
+
+// setupTest serves the gRPC API as with authentication disabled, the calls being served as
+// the system.
+func setupTest(t *testing.T, opts ...grpc.ServerOption) *testDependencies {
+	return setupServer(t, append(opts,
+		grpc.ChainUnaryInterceptor(rpc.UnarySystemInterceptor()),
+		grpc.ChainStreamInterceptor(rpc.StreamSystemInterceptor()),
+	)...)
+}
+
+// setupServer serves the gRPC API over an in-memory connection, backed by the real servers
+// over mocked cores.
+func setupServer(t *testing.T, opts ...grpc.ServerOption) *testDependencies {
+	ctrl := gomock.NewController(t)
+	accountCore := accountmock.NewMockICore(ctrl)
+	transactionCore := transactionmock.NewMockICore(ctrl)
+	accessPolicy := policy.NewPolicy(nil, policy.Config{})
+	server := rpc.NewServer(
+		account.NewServer(accountCore, accessPolicy),
+		transaction.NewServer(transactionCore, accessPolicy, transaction.Config{BatchMaxSize: 3}),
+		opts...,
+	)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testDependencies{
+		accountCore:     accountCore,
+		transactionCore: transactionCore,
+		accounts:        pb.NewAccountServiceClient(conn),
+		transactions:    pb.NewTransactionServiceClient(conn),
+	}
+}
+
+func TestAccountService_CreateAccount(t *testing.T) {
+	td := setupTest(t)
+	td.accountCore.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, a *account.Account) error {
+			a.ID = "0b0e0000000000"
+			a.Currency = "USD"
+			return nil
+		})
+
+	resp, err := td.accounts.CreateAccount(context.Background(), &pb.CreateAccountRequest{
+		Account: &pb.Account{Name: "John Doe", DocumentNumber: "123456789"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0b0e0000000000", resp.Account.Id)
+	assert.Equal(t, "John Doe", resp.Account.Name)
+	assert.Equal(t, "USD", resp.Account.Currency)
+}
+
+func TestAccountService_GetAccount_Unauthenticated_Without_An_Identity(t *testing.T) {
+	td := setupServer(t)
+
+	_, err := td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAccountService_CreateAccount_InvalidArgument(t *testing.T) {
+	td := setupTest(t)
+
+	_, err := td.accounts.CreateAccount(context.Background(), &pb.CreateAccountRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAccountService_GetAccount_Internal(t *testing.T) {
+	td := setupTest(t)
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").Return(errors.New("DB error"))
+
+	_, err := td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "DB error", status.Convert(err).Message())
+}
+
+func TestAccountService_StreamAccounts_Reads_Every_Page(t *testing.T) {
+	td := setupTest(t)
+	page := func(n int, offset int) *[]account.Account {
+		accounts := make([]account.Account, n)
+		for i := range accounts {
+			accounts[i].ID = fmt.Sprintf("a%013d", offset+i)
+		}
+		return &accounts
+	}
+	gomock.InOrder(
+		td.accountCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request account.IListRequest) (*[]account.Account, error) {
+				assert.Equal(t, uint32(0), request.GetOffset())
+				assert.Equal(t, "123456789", request.GetDocumentNumber())
+				return page(20, 0), nil
+			}),
+		td.accountCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request account.IListRequest) (*[]account.Account, error) {
+				assert.Equal(t, uint32(20), request.GetOffset())
+				return page(1, 20), nil
+			}),
+	)
+
+	stream, err := td.accounts.StreamAccounts(context.Background(), &pb.StreamAccountsRequest{DocumentNumber: "123456789"})
+	require.NoError(t, err)
+	received := 0
+	for {
+		a, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("a%013d", received), a.Id)
+		received++
+	}
+	assert.Equal(t, 21, received)
+}
+
+func TestTransactionService_CreateTransaction(t *testing.T) {
+	td := setupTest(t)
+	td.transactionCore.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, model *transaction.Transaction) error {
+			model.ID = "t0000000000001"
+			return nil
+		})
+
+	resp, err := td.transactions.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		Transaction: &pb.Transaction{OperationType: "Normal_Purchase", AccountId: "0b0e0000000000", Amount: 100},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "t0000000000001", resp.Transaction.Id)
+	assert.Equal(t, "0b0e0000000000", resp.Transaction.AccountId)
+}
+
+func TestTransactionService_CreateTransaction_InvalidArgument(t *testing.T) {
+	td := setupTest(t)
+
+	_, err := td.transactions.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestTransactionService_ListTransactions(t *testing.T) {
+	td := setupTest(t)
+	minAmount := -50.0
+	td.transactionCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request transaction.IListRequest) (*[]transaction.Transaction, *db.Page, error) {
+			assert.Equal(t, "0b0e0000000000", request.GetAccountId())
+			assert.Equal(t, &minAmount, request.GetMinAmount())
+			return &[]transaction.Transaction{{AccountId: "0b0e0000000000"}}, &db.Page{NextCursor: "next"}, nil
+		})
+
+	resp, err := td.transactions.ListTransactions(context.Background(), &pb.ListTransactionsRequest{
+		AccountId: "0b0e0000000000",
+		MinAmount: &minAmount,
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Transactions, 1)
+	assert.Equal(t, "next", resp.NextCursor)
+}
+
+func TestTransactionService_StreamTransactions_Follows_Cursor(t *testing.T) {
+	td := setupTest(t)
+	next := db.Cursor{CreatedAt: 1, ID: "t0000000000002"}.Encode()
+	gomock.InOrder(
+		td.transactionCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request transaction.IListRequest) (*[]transaction.Transaction, *db.Page, error) {
+				assert.Empty(t, request.GetCursor())
+				assert.Equal(t, uint32(2), request.GetLimit())
+				return &[]transaction.Transaction{{}, {}}, &db.Page{NextCursor: next}, nil
+			}),
+		td.transactionCore.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, request transaction.IListRequest) (*[]transaction.Transaction, *db.Page, error) {
+				assert.Equal(t, next, request.GetCursor())
+				return &[]transaction.Transaction{{}}, &db.Page{}, nil
+			}),
+	)
+
+	stream, err := td.transactions.StreamTransactions(context.Background(), &pb.ListTransactionsRequest{Limit: 2})
+	require.NoError(t, err)
+	received := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received++
+	}
+	assert.Equal(t, 3, received)
+}
+
+func TestTransactionService_StreamTransactions_InvalidArgument(t *testing.T) {
+	td := setupTest(t)
+
+	stream, err := td.transactions.StreamTransactions(context.Background(), &pb.ListTransactionsRequest{Limit: 21})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
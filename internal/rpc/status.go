@@ -0,0 +1,35 @@
+package rpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+)
+
+// statusError returns the gRPC status of a failed response, or nil when it succeeded.
+// Error codes map to status codes as they map to HTTP statuses in routes.
+func statusError(base *dto.Base) error {
+	if base == nil || base.Success || base.Error == nil {
+		return nil
+	}
+	return status.Error(statusCode(base.Error.Code), base.Error.Message)
+}
+
+func statusCode(errorCode string) codes.Code {
+	switch errorCode {
+	case common.ErrValidationFailed:
+		return codes.InvalidArgument
+	case common.ErrNotFoundFailed:
+		return codes.NotFound
+	case common.ErrUnauthorized:
+		return codes.Unauthenticated
+	case common.ErrForbidden:
+		return codes.PermissionDenied
+	case common.ErrRateLimited:
+		return codes.ResourceExhausted
+	case common.ErrTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/tenant"
+)
+
+// UnaryTenantInterceptor scopes each unary call to its tenant, named by the tenant header
+// metadata. Chained after UnaryAuthInterceptor, the tenant of the client wins.
+func UnaryTenantInterceptor(resolver *tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := resolveTenant(ctx, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamTenantInterceptor scopes each streaming call to its tenant. The tenant is resolved
+// once the request message is received, after the call is authenticated.
+func StreamTenantInterceptor(resolver *tenant.Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &tenantStream{ServerStream: ss, resolver: resolver})
+	}
+}
+
+type tenantStream struct {
+	grpc.ServerStream
+	resolver *tenant.Resolver
+	ctx      context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return s.ServerStream.Context()
+}
+
+func (s *tenantStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.ctx != nil {
+		return nil
+	}
+	ctx, err := resolveTenant(s.ServerStream.Context(), s.resolver)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	return nil
+}
+
+func resolveTenant(ctx context.Context, resolver *tenant.Resolver) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	identity, _ := auth.IdentityFromContext(ctx)
+	tenantID, err := resolver.Resolve(identity, first(md, strings.ToLower(resolver.Header())))
+	if err != nil {
+		if tenant.ErrorCode(err) == common.ErrValidationFailed {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return db.WithTenant(ctx, tenantID), nil
+}
@@ -0,0 +1,56 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/account"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+	"transaction-server/internal/tenant"
+)
+
+func setupTenantTest(t *testing.T) *testDependencies {
+	resolver := tenant.NewResolver(tenant.Config{Tenants: []string{"acme"}})
+	return setupTest(t,
+		grpc.UnaryInterceptor(rpc.UnaryTenantInterceptor(resolver)),
+		grpc.StreamInterceptor(rpc.StreamTenantInterceptor(resolver)),
+	)
+}
+
+func TestUnaryTenantInterceptor_Scopes_Call(t *testing.T) {
+	td := setupTenantTest(t)
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, a *account.Account, id string) error {
+			tenantID, ok := db.TenantFromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "acme", tenantID)
+			a.ID = id
+			return nil
+		})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "acme")
+	resp, err := td.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	require.NoError(t, err)
+	assert.Equal(t, "0b0e0000000000", resp.Account.Id)
+}
+
+func TestUnaryTenantInterceptor_Rejects(t *testing.T) {
+	td := setupTenantTest(t)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "globex")
+	_, err := td.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-tenant-id", "Acme Corp")
+	_, err = td.accounts.GetAccount(ctx, &pb.GetAccountRequest{Id: "0b0e0000000000"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
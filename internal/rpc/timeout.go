@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/timeout"
+)
+
+// UnaryTimeoutInterceptor sets the deadline of the method, keyed by its full name, on the
+// context of each unary call, within the deadline of the client if any. A call failing once
+// its context is done fails with DeadlineExceeded, or Canceled when the client cancelled it.
+func UnaryTimeoutInterceptor(config timeout.Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if d := config.For(info.FullMethod); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		resp, err := handler(ctx, req)
+		if err != nil && status.Code(err) == codes.Internal && ctx.Err() != nil {
+			code := codes.DeadlineExceeded
+			if errors.Is(ctx.Err(), context.Canceled) {
+				code = codes.Canceled
+			}
+			return nil, status.Error(code, status.Convert(err).Message())
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,34 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"transaction-server/internal/account"
+	"transaction-server/internal/rpc"
+	"transaction-server/internal/rpc/pb"
+	"transaction-server/internal/timeout"
+)
+
+func TestUnaryTimeoutInterceptor(t *testing.T) {
+	td := setupTest(t, grpc.UnaryInterceptor(rpc.UnaryTimeoutInterceptor(timeout.Config{
+		Default: time.Hour,
+		Routes:  map[string]time.Duration{pb.AccountService_GetAccount_FullMethodName: 10 * time.Millisecond},
+	})))
+	// The query runs until the deadline of the call cancels it.
+	td.accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, model *account.Account, id string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	_, err := td.accounts.GetAccount(context.Background(), &pb.GetAccountRequest{Id: "0b0e0000000000"})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
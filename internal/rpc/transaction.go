@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/rpc/pb"
+	"transaction-server/internal/transaction"
+)
+
+// TransactionService implements pb.TransactionServiceServer over transaction.IServer.
+type TransactionService struct {
+	pb.UnimplementedTransactionServiceServer
+	server transaction.IServer
+}
+
+func NewTransactionService(server transaction.IServer) *TransactionService {
+	return &TransactionService{server: server}
+}
+
+func (s *TransactionService) CreateTransaction(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.CreateTransactionResponse, error) {
+	createRequest := &dto.CreateTransactionRequest{}
+	if req.GetTransaction() != nil {
+		createRequest.Transaction = transactionFromProto(req.GetTransaction())
+	}
+	response := serve(ctx, func(c *gin.Context) *dto.CreateTransactionResponse { return s.server.Create(c, createRequest) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	return &pb.CreateTransactionResponse{Transaction: transactionToProto(response.Transaction)}, nil
+}
+
+func (s *TransactionService) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	response := serve(ctx, func(c *gin.Context) *dto.GetTransactionResponse { return s.server.Get(c, req.GetId()) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	return &pb.GetTransactionResponse{Transaction: transactionToProto(response.Transaction)}, nil
+}
+
+func (s *TransactionService) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	listRequest := listRequestFromProto(req)
+	response := serve(ctx, func(c *gin.Context) *dto.ListTransactionResponse { return s.server.List(c, listRequest) })
+	if err := statusError(response.Base); err != nil {
+		return nil, err
+	}
+	transactions := make([]*pb.Transaction, 0, len(response.Transactions))
+	for _, t := range response.Transactions {
+		transactions = append(transactions, transactionToProto(t))
+	}
+	return &pb.ListTransactionsResponse{
+		Transactions: transactions,
+		NextCursor:   response.NextCursor,
+		PrevCursor:   response.PrevCursor,
+	}, nil
+}
+
+// StreamTransactions sends the transactions page by page until the last page or the client
+// cancels. Pages follow the next cursor when the order supports cursors, and the offset
+// otherwise.
+func (s *TransactionService) StreamTransactions(req *pb.ListTransactionsRequest, stream pb.TransactionService_StreamTransactionsServer) error {
+	listRequest := listRequestFromProto(req)
+	if listRequest.Limit == 0 {
+		listRequest.Limit = streamPageSize
+	}
+	for {
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		response := serve(stream.Context(), func(c *gin.Context) *dto.ListTransactionResponse { return s.server.List(c, listRequest) })
+		if err := statusError(response.Base); err != nil {
+			return err
+		}
+		for _, t := range response.Transactions {
+			if err := stream.Send(transactionToProto(t)); err != nil {
+				return err
+			}
+		}
+		if transaction.IsCursorPaginated(listRequest) {
+			if response.NextCursor == "" {
+				return nil
+			}
+			listRequest.Cursor = response.NextCursor
+			continue
+		}
+		if uint32(len(response.Transactions)) < listRequest.Limit {
+			return nil
+		}
+		listRequest.Offset += listRequest.Limit
+	}
+}
+
+func listRequestFromProto(req *pb.ListTransactionsRequest) *dto.ListTransactionRequest {
+	return &dto.ListTransactionRequest{
+		Limit:           req.GetLimit(),
+		Offset:          req.GetOffset(),
+		AccountId:       req.GetAccountId(),
+		OperationType:   req.GetOperationType(),
+		OperationTypes:  req.GetOperationTypes(),
+		Currency:        req.GetCurrency(),
+		EventDateFrom:   req.GetEventDateFrom(),
+		EventDateTo:     req.GetEventDateTo(),
+		CreatedAtFrom:   req.GetCreatedAtFrom(),
+		CreatedAtTo:     req.GetCreatedAtTo(),
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		OnlyOpenBalance: req.GetOnlyOpenBalance(),
+		SortBy:          req.GetSortBy(),
+		SortOrder:       req.GetSortOrder(),
+		Cursor:          req.GetCursor(),
+	}
+}
+
+func transactionToProto(t *dto.Transaction) *pb.Transaction {
+	return &pb.Transaction{
+		Id:              t.ID,
+		AccountId:       t.AccountID,
+		OperationType:   t.OperationType,
+		Amount:          t.Amount,
+		Currency:        t.Currency,
+		SettledAmount:   t.SettledAmount,
+		SettledCurrency: t.SettledCurrency,
+		FxRate:          t.FxRate,
+		ConvertCurrency: t.ConvertCurrency,
+		Balance:         t.Balance,
+		EventDate:       t.EventDate,
+	}
+}
+
+func transactionFromProto(t *pb.Transaction) *dto.Transaction {
+	return &dto.Transaction{
+		AccountID:       t.GetAccountId(),
+		OperationType:   t.GetOperationType(),
+		Amount:          t.GetAmount(),
+		Currency:        t.GetCurrency(),
+		ConvertCurrency: t.GetConvertCurrency(),
+		EventDate:       t.GetEventDate(),
+	}
+}
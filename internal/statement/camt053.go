@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// camt053Namespace is the version of the ISO 20022 bank to customer statement written.
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// camt053Writer writes an ISO 20022 camt.053 statement. Amounts are unsigned and
+// carry a credit (CRDT) or debit (DBIT) indicator.
+type camt053Writer struct {
+	xml    *xmlWriter
+	header *Header
+}
+
+func newCamt053Writer(w io.Writer) *camt053Writer {
+	return &camt053Writer{xml: newXMLWriter(w)}
+}
+
+// isoDateTime formats t as an ISO 8601 datetime in UTC.
+func isoDateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// creditDebit returns the absolute amount and its credit/debit indicator.
+func creditDebit(amount float64) (float64, string) {
+	if amount < 0 {
+		return math.Abs(amount), "DBIT"
+	}
+	return amount, "CRDT"
+}
+
+func (c *camt053Writer) WriteHeader(header *Header) error {
+	c.header = header
+	x := c.xml
+	id := fmt.Sprintf("%s-%d-%d", header.AccountID, header.From.Unix(), header.To.Unix())
+	x.raw(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	x.open("Document", attr{"xmlns", camt053Namespace})
+	x.open("BkToCstmrStmt")
+	x.open("GrpHdr")
+	x.element("MsgId", fmt.Sprintf("%s-%d", id, header.GeneratedAt.Unix()))
+	x.element("CreDtTm", isoDateTime(header.GeneratedAt))
+	x.close("GrpHdr")
+	x.open("Stmt")
+	x.element("Id", id)
+	x.element("CreDtTm", isoDateTime(header.GeneratedAt))
+	x.open("FrToDt")
+	x.element("FrDtTm", isoDateTime(header.From))
+	x.element("ToDtTm", isoDateTime(header.To))
+	x.close("FrToDt")
+	x.open("Acct")
+	x.open("Id")
+	x.open("Othr")
+	x.element("Id", header.AccountID)
+	x.close("Othr")
+	x.close("Id")
+	x.element("Ccy", header.Currency)
+	x.close("Acct")
+	c.balance("OPBD", header.OpeningBalance, header.From)
+	c.balance("CLBD", header.ClosingBalance, header.To)
+	return x.err
+}
+
+func (c *camt053Writer) balance(code string, amount float64, at time.Time) {
+	x := c.xml
+	value, indicator := creditDebit(amount)
+	x.open("Bal")
+	x.open("Tp")
+	x.open("CdOrPrtry")
+	x.element("Cd", code)
+	x.close("CdOrPrtry")
+	x.close("Tp")
+	x.element("Amt", formatAmount(value, c.header.Currency), attr{"Ccy", c.header.Currency})
+	x.element("CdtDbtInd", indicator)
+	x.open("Dt")
+	x.element("DtTm", isoDateTime(at))
+	x.close("Dt")
+	x.close("Bal")
+}
+
+func (c *camt053Writer) WriteEntry(entry *Entry) error {
+	x := c.xml
+	value, indicator := creditDebit(entry.Amount)
+	x.open("Ntry")
+	x.element("NtryRef", entry.ID)
+	x.element("Amt", formatAmount(value, c.header.Currency), attr{"Ccy", c.header.Currency})
+	x.element("CdtDbtInd", indicator)
+	x.element("Sts", "BOOK")
+	x.open("BookgDt")
+	x.element("DtTm", isoDateTime(entry.EventDate))
+	x.close("BookgDt")
+	x.open("ValDt")
+	x.element("DtTm", isoDateTime(entry.EventDate))
+	x.close("ValDt")
+	x.open("BkTxCd")
+	x.open("Prtry")
+	x.element("Cd", entry.OperationType)
+	x.close("Prtry")
+	x.close("BkTxCd")
+	x.open("NtryDtls")
+	x.open("TxDtls")
+	x.open("Refs")
+	x.element("TxId", entry.ID)
+	x.close("Refs")
+	if entry.OriginalCurrency != "" && entry.OriginalCurrency != c.header.Currency {
+		original, _ := creditDebit(entry.OriginalAmount)
+		x.open("AmtDtls")
+		x.open("InstdAmt")
+		x.element("Amt", formatAmount(original, entry.OriginalCurrency), attr{"Ccy", entry.OriginalCurrency})
+		x.close("InstdAmt")
+		x.close("AmtDtls")
+	}
+	x.close("TxDtls")
+	x.close("NtryDtls")
+	x.close("Ntry")
+	return x.err
+}
+
+func (c *camt053Writer) Close() error {
+	x := c.xml
+	x.close("Stmt")
+	x.close("BkToCstmrStmt")
+	x.close("Document")
+	return x.flush()
+}
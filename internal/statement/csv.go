@@ -0,0 +1,43 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// csvWriter writes one row per entry with the signed amount and the running balance.
+type csvWriter struct {
+	writer   *csv.Writer
+	currency string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteHeader(header *Header) error {
+	c.currency = header.Currency
+	return c.writer.Write([]string{
+		"event_date", "transaction_id", "operation_type", "amount", "currency",
+		"original_amount", "original_currency", "balance",
+	})
+}
+
+func (c *csvWriter) WriteEntry(entry *Entry) error {
+	return c.writer.Write([]string{
+		entry.EventDate.UTC().Format(time.RFC3339),
+		entry.ID,
+		entry.OperationType,
+		formatAmount(entry.Amount, c.currency),
+		c.currency,
+		formatAmount(entry.OriginalAmount, entry.OriginalCurrency),
+		entry.OriginalCurrency,
+		formatAmount(entry.Balance, c.currency),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
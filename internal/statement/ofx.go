@@ -0,0 +1,94 @@
+package statement
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// ofxWriter writes an OFX 2.2 credit card statement. Charges are negative and
+// credits positive, as are the ledger balances.
+type ofxWriter struct {
+	xml    *xmlWriter
+	header *Header
+}
+
+func newOFXWriter(w io.Writer) *ofxWriter {
+	return &ofxWriter{xml: newXMLWriter(w)}
+}
+
+// ofxTime formats t as an OFX datetime in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+func (o *ofxWriter) WriteHeader(header *Header) error {
+	o.header = header
+	x := o.xml
+	x.raw(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	x.raw(`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n")
+	x.open("OFX")
+	x.open("SIGNONMSGSRSV1")
+	x.open("SONRS")
+	o.status()
+	x.element("DTSERVER", ofxTime(header.GeneratedAt))
+	x.element("LANGUAGE", "ENG")
+	x.close("SONRS")
+	x.close("SIGNONMSGSRSV1")
+	x.open("CREDITCARDMSGSRSV1")
+	x.open("CCSTMTTRNRS")
+	x.element("TRNUID", "0")
+	o.status()
+	x.open("CCSTMTRS")
+	x.element("CURDEF", header.Currency)
+	x.open("CCACCTFROM")
+	x.element("ACCTID", header.AccountID)
+	x.close("CCACCTFROM")
+	x.open("BANKTRANLIST")
+	x.element("DTSTART", ofxTime(header.From))
+	x.element("DTEND", ofxTime(header.To))
+	return x.err
+}
+
+func (o *ofxWriter) status() {
+	o.xml.open("STATUS")
+	o.xml.element("CODE", "0")
+	o.xml.element("SEVERITY", "INFO")
+	o.xml.close("STATUS")
+}
+
+func (o *ofxWriter) WriteEntry(entry *Entry) error {
+	x := o.xml
+	trnType := "CREDIT"
+	if entry.Amount < 0 {
+		trnType = "DEBIT"
+	}
+	x.open("STMTTRN")
+	x.element("TRNTYPE", trnType)
+	x.element("DTPOSTED", ofxTime(entry.EventDate))
+	x.element("TRNAMT", formatAmount(entry.Amount, o.header.Currency))
+	x.element("FITID", entry.ID)
+	x.element("NAME", entry.OperationType)
+	if entry.OriginalCurrency != "" && entry.OriginalCurrency != o.header.Currency {
+		x.open("ORIGCURRENCY")
+		x.element("CURRATE", strconv.FormatFloat(entry.FxRate, 'f', -1, 64))
+		x.element("CURSYM", entry.OriginalCurrency)
+		x.close("ORIGCURRENCY")
+	}
+	x.close("STMTTRN")
+	return x.err
+}
+
+func (o *ofxWriter) Close() error {
+	x := o.xml
+	x.close("BANKTRANLIST")
+	x.open("LEDGERBAL")
+	x.element("BALAMT", formatAmount(o.header.ClosingBalance, o.header.Currency))
+	x.element("DTASOF", ofxTime(o.header.To))
+	x.close("LEDGERBAL")
+	x.close("CCSTMTRS")
+	x.close("CCSTMTTRNRS")
+	x.close("CREDITCARDMSGSRSV1")
+	x.close("OFX")
+	return x.flush()
+}
@@ -0,0 +1,98 @@
+// Package statement writes account statements in CSV, OFX and ISO 20022 camt.053 formats.
+//
+// Writers stream: the header is written first, then each entry as it is read, so
+// statements of any length are written in constant memory.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"transaction-server/internal/common/currency"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+// Formats lists the supported statement formats.
+var Formats = []string{FormatCSV, FormatOFX, FormatCamt053}
+
+// Header describes the statement. Balances are signed amounts in the account currency;
+// a negative balance is owed by the account holder.
+type Header struct {
+	AccountID      string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	GeneratedAt    time.Time
+}
+
+// Entry is a transaction of the statement.
+type Entry struct {
+	ID            string
+	OperationType string
+	EventDate     time.Time
+	// Amount is the signed amount in the account currency.
+	Amount float64
+	// OriginalAmount and OriginalCurrency are the amount the transaction was posted in.
+	OriginalAmount   float64
+	OriginalCurrency string
+	// FxRate is the rate the original amount was converted into the account currency at.
+	FxRate float64
+	// Balance is the running balance of the account after the entry.
+	Balance float64
+}
+
+// Writer writes a statement: WriteHeader once, WriteEntry for each entry in
+// booking order, then Close to write the trailer and flush.
+type Writer interface {
+	WriteHeader(header *Header) error
+	WriteEntry(entry *Entry) error
+	Close() error
+}
+
+// NewWriter returns a Writer for the format.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatOFX:
+		return newOFXWriter(w), nil
+	case FormatCamt053:
+		return newCamt053Writer(w), nil
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// ContentType returns the media type of the format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	}
+	return "application/xml"
+}
+
+// FileExtension returns the file extension of the format.
+func FileExtension(format string) string {
+	switch format {
+	case FormatCSV:
+		return "csv"
+	case FormatOFX:
+		return "ofx"
+	}
+	return "xml"
+}
+
+// formatAmount formats the amount with the minor units of the currency.
+func formatAmount(amount float64, code string) string {
+	return strconv.FormatFloat(currency.Round(amount, code), 'f', currency.MinorUnits(code), 64)
+}
@@ -0,0 +1,71 @@
+package statement_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"transaction-server/internal/statement"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func writeStatement(t *testing.T, format string) []byte {
+	buf := new(bytes.Buffer)
+	writer, err := statement.NewWriter(buf, format)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteHeader(&statement.Header{
+		AccountID:      "0b0e0000000000",
+		Currency:       "USD",
+		From:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC),
+		OpeningBalance: 25,
+		ClosingBalance: -49.5,
+		GeneratedAt:    time.Date(2024, 4, 1, 8, 30, 0, 0, time.UTC),
+	}))
+	entries := []*statement.Entry{
+		{
+			ID: "a1b2c3d4e5f6a7", OperationType: "Normal_Purchase", EventDate: time.Date(2024, 3, 2, 10, 15, 0, 0, time.UTC),
+			Amount: -100, OriginalAmount: -100, OriginalCurrency: "USD", FxRate: 1, Balance: -75,
+		},
+		{
+			ID: "b1b2c3d4e5f6a7", OperationType: "Credit_Voucher", EventDate: time.Date(2024, 3, 9, 16, 0, 0, 0, time.UTC),
+			Amount: 54.5, OriginalAmount: 50, OriginalCurrency: "EUR", FxRate: 1.09, Balance: -20.5,
+		},
+		{
+			ID: "c1b2c3d4e5f6a7", OperationType: "Withdraw", EventDate: time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+			Amount: -29, OriginalAmount: -29, OriginalCurrency: "USD", FxRate: 1, Balance: -49.5,
+		},
+	}
+	for _, entry := range entries {
+		require.NoError(t, writer.WriteEntry(entry))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestWriter_Golden(t *testing.T) {
+	for _, format := range statement.Formats {
+		t.Run(format, func(t *testing.T) {
+			got := writeStatement(t, format)
+			golden := filepath.Join("testdata", "statement."+format+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	_, err := statement.NewWriter(new(bytes.Buffer), "qif")
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xmlWriter writes indented XML elements one at a time. The first error is kept
+// and returned by flush.
+type xmlWriter struct {
+	writer *bufio.Writer
+	depth  int
+	err    error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	return &xmlWriter{writer: bufio.NewWriter(w)}
+}
+
+// raw writes s as is, for prologs and processing instructions.
+func (x *xmlWriter) raw(s string) {
+	if x.err == nil {
+		_, x.err = x.writer.WriteString(s)
+	}
+}
+
+// attr is an attribute name followed by its value.
+type attr [2]string
+
+func (x *xmlWriter) startTag(name string, attrs []attr) {
+	x.raw(strings.Repeat("  ", x.depth) + "<" + name)
+	for _, a := range attrs {
+		x.raw(" " + a[0] + `="`)
+		x.escape(a[1])
+		x.raw(`"`)
+	}
+	x.raw(">")
+}
+
+func (x *xmlWriter) escape(s string) {
+	if x.err == nil {
+		x.err = xml.EscapeText(x.writer, []byte(s))
+	}
+}
+
+// open starts an element holding other elements.
+func (x *xmlWriter) open(name string, attrs ...attr) {
+	x.startTag(name, attrs)
+	x.raw("\n")
+	x.depth++
+}
+
+// close ends the element started by open.
+func (x *xmlWriter) close(name string) {
+	x.depth--
+	x.raw(strings.Repeat("  ", x.depth) + "</" + name + ">\n")
+}
+
+// element writes an element holding text.
+func (x *xmlWriter) element(name string, value string, attrs ...attr) {
+	x.startTag(name, attrs)
+	x.escape(value)
+	x.raw("</" + name + ">\n")
+}
+
+func (x *xmlWriter) flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	return x.writer.Flush()
+}
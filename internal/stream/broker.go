@@ -0,0 +1,207 @@
+// Package stream pushes the transaction events of an account to live clients as
+// Server-Sent Events.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+	"transaction-server/internal/outbox"
+)
+
+// Event is a change to the transactions of an account sent to its streams.
+type Event struct {
+	// ID is the ID of the outbox event, sent as the SSE id for Last-Event-ID resume.
+	ID string
+	// Type is the outbox event type, sent as the SSE event name: transaction.created for
+	// new transactions and balance.discharged for balances updated by a later transaction.
+	Type      string
+	AccountID string
+	Data      json.RawMessage
+	at        time.Time
+}
+
+// Broker implements outbox.Publisher by fanning the transaction events relayed from the
+// outbox out to the subscribed streams of their account. It keeps the recent events of
+// each account in memory so a reconnecting client can resume where it stopped; the buffer
+// only holds events relayed by this process and is lost on restart.
+type Broker struct {
+	config Config
+	now    func() time.Time
+
+	mu          sync.Mutex
+	buffers     map[string][]*Event
+	subscribers map[string]map[*Subscription]struct{}
+	lastSweep   time.Time
+	closed      bool
+}
+
+func NewBroker(config Config) *Broker {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 100
+	}
+	if config.BufferTTL <= 0 {
+		config.BufferTTL = 5 * time.Minute
+	}
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = 15 * time.Second
+	}
+	if config.SubscriberBuffer <= 0 {
+		config.SubscriberBuffer = 64
+	}
+	return &Broker{
+		config:      config,
+		now:         time.Now,
+		buffers:     make(map[string][]*Event),
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Publish sends transaction events to the streams of their account. Other events are
+// ignored, as is a message published again by the relay.
+func (b *Broker) Publish(ctx context.Context, message *outbox.Message) error {
+	if message.Type != outbox.TypeTransactionCreated && message.Type != outbox.TypeBalanceDischarged {
+		return nil
+	}
+	var payload struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.Unmarshal(message.Payload, &payload); err != nil {
+		return err
+	}
+	event := &Event{ID: message.ID, Type: message.Type, AccountID: payload.AccountID, Data: message.Payload}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	event.at = b.now()
+	b.sweep(event.at)
+	buffer := b.buffers[event.AccountID]
+	for _, buffered := range buffer {
+		if buffered.ID == event.ID {
+			return nil
+		}
+	}
+	if len(buffer) == b.config.BufferSize {
+		buffer = buffer[1:]
+	}
+	b.buffers[event.AccountID] = append(buffer, event)
+	for subscription := range b.subscribers[event.AccountID] {
+		select {
+		case subscription.events <- event:
+		default:
+			// The client is not keeping up: end its stream so it resumes from the buffer.
+			b.remove(subscription)
+		}
+	}
+	return nil
+}
+
+// sweep drops the expired events of every account, at most once per BufferTTL.
+func (b *Broker) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.config.BufferTTL {
+		return
+	}
+	b.lastSweep = now
+	for accountID, buffer := range b.buffers {
+		if live := b.unexpired(buffer, now); len(live) > 0 {
+			b.buffers[accountID] = live
+		} else {
+			delete(b.buffers, accountID)
+		}
+	}
+}
+
+func (b *Broker) unexpired(buffer []*Event, now time.Time) []*Event {
+	for i, event := range buffer {
+		if now.Sub(event.at) < b.config.BufferTTL {
+			return buffer[i:]
+		}
+	}
+	return nil
+}
+
+// Subscription is a stream of the events of an account.
+type Subscription struct {
+	broker    *Broker
+	accountID string
+	events    chan *Event
+	// Replay holds the buffered events the client missed, oldest first.
+	Replay []*Event
+}
+
+// Subscribe opens a stream of the events of the account. With the ID of the last event
+// a client received, the events after it are replayed; when that event is no longer
+// buffered every buffered event is replayed, so clients should ignore IDs they have seen.
+func (b *Broker) Subscribe(accountID string, lastEventID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscription := &Subscription{
+		broker:    b,
+		accountID: accountID,
+		events:    make(chan *Event, b.config.SubscriberBuffer),
+	}
+	if b.closed {
+		close(subscription.events)
+		return subscription
+	}
+	if lastEventID != "" {
+		buffer := b.unexpired(b.buffers[accountID], b.now())
+		replay := buffer
+		for i, event := range buffer {
+			if event.ID == lastEventID {
+				replay = buffer[i+1:]
+				break
+			}
+		}
+		subscription.Replay = append([]*Event(nil), replay...)
+	}
+	if b.subscribers[accountID] == nil {
+		b.subscribers[accountID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[accountID][subscription] = struct{}{}
+	return subscription
+}
+
+// Close ends every stream, and those subscribed afterwards, for their clients to resume
+// from another instance when this one shuts down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subscribers := range b.subscribers {
+		for subscription := range subscribers {
+			b.remove(subscription)
+		}
+	}
+}
+
+// Events returns the live events of the stream. The channel is closed when the broker
+// drops a client that is not keeping up, or is closed.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Close ends the stream.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+func (b *Broker) remove(subscription *Subscription) {
+	subscribers := b.subscribers[subscription.accountID]
+	if _, ok := subscribers[subscription]; !ok {
+		return
+	}
+	delete(subscribers, subscription)
+	if len(subscribers) == 0 {
+		delete(b.subscribers, subscription.accountID)
+	}
+	close(subscription.events)
+}
+
+// Heartbeat returns how often an idle stream sends a heartbeat.
+func (b *Broker) Heartbeat() time.Duration {
+	return b.config.Heartbeat
+}
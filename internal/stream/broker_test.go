@@ -0,0 +1,103 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/stream"
+)
+
+func message(id int, eventType string, accountID string) *outbox.Message {
+	payload, _ := json.Marshal(map[string]string{"account_id": accountID})
+	return &outbox.Message{ID: fmt.Sprintf("e%013d", id), Type: eventType, Payload: payload}
+}
+
+func ids(events []*stream.Event) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
+
+func TestBroker_Sends_Transaction_Events_Of_The_Account(t *testing.T) {
+	broker := stream.NewBroker(stream.Config{})
+	subscription := broker.Subscribe("a0000000000001", "")
+	defer subscription.Close()
+
+	ctx := context.Background()
+	require.NoError(t, broker.Publish(ctx, message(1, outbox.TypeTransactionCreated, "a0000000000001")))
+	require.NoError(t, broker.Publish(ctx, message(2, outbox.TypeTransactionCreated, "a0000000000002")))
+	require.NoError(t, broker.Publish(ctx, message(3, outbox.TypeAccountCreated, "a0000000000001")))
+	require.NoError(t, broker.Publish(ctx, message(4, outbox.TypeBalanceDischarged, "a0000000000001")))
+	// The relay publishes a message again when a later publisher failed.
+	require.NoError(t, broker.Publish(ctx, message(4, outbox.TypeBalanceDischarged, "a0000000000001")))
+
+	received := make([]*stream.Event, 0)
+	for len(subscription.Events()) > 0 {
+		received = append(received, <-subscription.Events())
+	}
+	assert.Equal(t, []string{"e0000000000001", "e0000000000004"}, ids(received))
+	assert.Equal(t, outbox.TypeBalanceDischarged, received[1].Type)
+	assert.JSONEq(t, `{"account_id":"a0000000000001"}`, string(received[1].Data))
+}
+
+func TestBroker_Replays_Events_After_Last_Event_ID(t *testing.T) {
+	broker := stream.NewBroker(stream.Config{BufferSize: 3})
+	ctx := context.Background()
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, broker.Publish(ctx, message(i, outbox.TypeTransactionCreated, "a0000000000001")))
+	}
+
+	subscription := broker.Subscribe("a0000000000001", "e0000000000002")
+	defer subscription.Close()
+	assert.Equal(t, []string{"e0000000000003", "e0000000000004"}, ids(subscription.Replay))
+
+	// The first event has left the buffer, so everything buffered is replayed.
+	evicted := broker.Subscribe("a0000000000001", "e0000000000001")
+	defer evicted.Close()
+	assert.Equal(t, []string{"e0000000000002", "e0000000000003", "e0000000000004"}, ids(evicted.Replay))
+
+	fresh := broker.Subscribe("a0000000000001", "")
+	defer fresh.Close()
+	assert.Empty(t, fresh.Replay)
+}
+
+func TestBroker_Drops_Slow_Subscribers(t *testing.T) {
+	broker := stream.NewBroker(stream.Config{SubscriberBuffer: 1})
+	subscription := broker.Subscribe("a0000000000001", "")
+	ctx := context.Background()
+	require.NoError(t, broker.Publish(ctx, message(1, outbox.TypeTransactionCreated, "a0000000000001")))
+	require.NoError(t, broker.Publish(ctx, message(2, outbox.TypeTransactionCreated, "a0000000000001")))
+
+	event, ok := <-subscription.Events()
+	require.True(t, ok)
+	assert.Equal(t, "e0000000000001", event.ID)
+	_, ok = <-subscription.Events()
+	assert.False(t, ok)
+	subscription.Close()
+
+	resumed := broker.Subscribe("a0000000000001", event.ID)
+	defer resumed.Close()
+	assert.Equal(t, []string{"e0000000000002"}, ids(resumed.Replay))
+}
+
+func TestBroker_Close_Ends_Streams(t *testing.T) {
+	broker := stream.NewBroker(stream.Config{})
+	subscription := broker.Subscribe("a0000000000001", "")
+
+	broker.Close()
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	subscription.Close()
+
+	late := broker.Subscribe("a0000000000001", "")
+	defer late.Close()
+	_, open = <-late.Events()
+	assert.False(t, open)
+}
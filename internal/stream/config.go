@@ -0,0 +1,29 @@
+package stream
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// BufferSize is the number of recent events kept per account for Last-Event-ID resume.
+	BufferSize int
+	// BufferTTL is how long events are kept for resume.
+	BufferTTL time.Duration
+	// Heartbeat is how often an idle stream sends a comment to keep proxies from closing it.
+	Heartbeat time.Duration
+	// SubscriberBuffer is the number of events queued for a slow client before its
+	// stream is closed; the client resumes from the replay buffer when it reconnects.
+	SubscriberBuffer int
+}
+
+// Validate validates the heartbeat is set and the buffers are not negative.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.BufferSize, validation.Min(0)),
+		validation.Field(&c.BufferTTL, validation.Min(time.Duration(0))),
+		validation.Field(&c.Heartbeat, validation.Required, validation.Min(time.Duration(0))),
+		validation.Field(&c.SubscriberBuffer, validation.Min(0)),
+	)
+}
@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"time"
+	"transaction-server/internal/account"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/validator"
+)
+
+// EventWriter writes the events of a stream to the client.
+type EventWriter interface {
+	WriteEvent(event *Event) error
+	// WriteHeartbeat writes a comment keeping the connection open.
+	WriteHeartbeat() error
+}
+
+type IServer interface {
+	Stream(ctx *gin.Context, req *dto.StreamTransactionRequest, w EventWriter) *dto.StreamTransactionResponse
+}
+
+type Server struct {
+	accountCore account.ICore
+	policy      policy.IPolicy
+	broker      *Broker
+}
+
+func NewServer(accountCore account.ICore, policy policy.IPolicy, broker *Broker) IServer {
+	return &Server{accountCore: accountCore, policy: policy, broker: broker}
+}
+
+// Stream writes the events of the account until the client disconnects. A heartbeat is
+// written as soon as the stream opens, so a response is only returned without writing
+// anything when the stream could not be opened.
+func (s *Server) Stream(ctx *gin.Context, req *dto.StreamTransactionRequest, w EventWriter) *dto.StreamTransactionResponse {
+	if err := validator.NewValidAccount(req.AccountId, validator.GetAccountValidator); err != nil {
+		return &dto.StreamTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if err := s.policy.Authorize(ctx, policy.PermissionTransactionsRead); err != nil {
+		return &dto.StreamTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	acc := new(account.Account)
+	if err := s.accountCore.Get(ctx, acc, req.AccountId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &dto.StreamTransactionResponse{Base: dto.GetErrorResponse(common.ErrNotFoundFailed, err.Error())}
+		}
+		return &dto.StreamTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	if err := s.policy.AuthorizeOwner(ctx, policy.PermissionTransactionsRead, acc.OwnerID); err != nil {
+		return &dto.StreamTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+
+	subscription := s.broker.Subscribe(req.AccountId, req.LastEventID)
+	defer subscription.Close()
+	if err := w.WriteHeartbeat(); err != nil {
+		return &dto.StreamTransactionResponse{Base: &dto.Base{Success: true}}
+	}
+	for _, event := range subscription.Replay {
+		if err := w.WriteEvent(event); err != nil {
+			return &dto.StreamTransactionResponse{Base: &dto.Base{Success: true}}
+		}
+	}
+	heartbeat := time.NewTicker(s.broker.Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Request.Context().Done():
+			return &dto.StreamTransactionResponse{Base: &dto.Base{Success: true}}
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return &dto.StreamTransactionResponse{Base: &dto.Base{Success: true}}
+			}
+			err = w.WriteEvent(event)
+		case <-heartbeat.C:
+			err = w.WriteHeartbeat()
+		}
+		// A failed write means the client is gone.
+		if err != nil {
+			return &dto.StreamTransactionResponse{Base: &dto.Base{Success: true}}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"transaction-server/internal/account"
+	"transaction-server/internal/account/mock"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/stream"
+)
+
+// recorder is an EventWriter keeping what was written.
+type recorder struct {
+	mu         sync.Mutex
+	events     []*stream.Event
+	heartbeats int
+}
+
+func (r *recorder) WriteEvent(event *stream.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) WriteHeartbeat() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeats++
+	return nil
+}
+
+func (r *recorder) eventIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ids(r.events)
+}
+
+// newContext returns the context of a request of an admin.
+func newContext(ctx context.Context) *gin.Context {
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	ginCtx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000002", Role: policy.RoleAdmin})
+	return ginCtx
+}
+
+func TestServer_Stream_ValidationFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	server := stream.NewServer(mock.NewMockICore(ctrl), policy.NewPolicy(nil, policy.Config{}), stream.NewBroker(stream.Config{}))
+
+	w := &recorder{}
+	resp := server.Stream(newContext(context.Background()), &dto.StreamTransactionRequest{AccountId: "bad"}, w)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+	assert.Zero(t, w.heartbeats)
+}
+
+func TestServer_Stream_AccountNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	accountCore := mock.NewMockICore(ctrl)
+	server := stream.NewServer(accountCore, policy.NewPolicy(nil, policy.Config{}), stream.NewBroker(stream.Config{}))
+	accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "a0000000000001").Return(gorm.ErrRecordNotFound)
+
+	resp := server.Stream(newContext(context.Background()), &dto.StreamTransactionRequest{AccountId: "a0000000000001"}, &recorder{})
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrNotFoundFailed, resp.Error.Code)
+}
+
+func TestServer_Stream_Replays_Then_Sends_Live_Events(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	accountCore := mock.NewMockICore(ctrl)
+	broker := stream.NewBroker(stream.Config{Heartbeat: time.Hour})
+	server := stream.NewServer(accountCore, policy.NewPolicy(nil, policy.Config{}), broker)
+	accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "a0000000000001").Return(nil)
+
+	ctx := context.Background()
+	require.NoError(t, broker.Publish(ctx, message(1, outbox.TypeTransactionCreated, "a0000000000001")))
+	require.NoError(t, broker.Publish(ctx, message(2, outbox.TypeTransactionCreated, "a0000000000001")))
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &recorder{}
+	done := make(chan *dto.StreamTransactionResponse)
+	go func() {
+		done <- server.Stream(newContext(ctx), &dto.StreamTransactionRequest{AccountId: "a0000000000001", LastEventID: "e0000000000001"}, w)
+	}()
+	require.Eventually(t, func() bool { return len(w.eventIDs()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, broker.Publish(ctx, message(3, outbox.TypeBalanceDischarged, "a0000000000001")))
+	require.Eventually(t, func() bool { return len(w.eventIDs()) == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case resp := <-done:
+		assert.True(t, resp.Success)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end when the client disconnected")
+	}
+	assert.Equal(t, []string{"e0000000000002", "e0000000000003"}, w.eventIDs())
+	assert.Equal(t, 1, w.heartbeats)
+}
+
+func TestServer_Stream_QueryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	accountCore := mock.NewMockICore(ctrl)
+	server := stream.NewServer(accountCore, policy.NewPolicy(nil, policy.Config{}), stream.NewBroker(stream.Config{}))
+	accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "a0000000000001").Return(errors.New("db down"))
+
+	resp := server.Stream(newContext(context.Background()), &dto.StreamTransactionRequest{AccountId: "a0000000000001"}, &recorder{})
+	assert.Equal(t, common.ErrDBQueryError, resp.Error.Code)
+}
+
+func TestServer_Stream_Forbidden_For_Other_Owner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	accountCore := mock.NewMockICore(ctrl)
+	server := stream.NewServer(accountCore, policy.NewPolicy(nil, policy.Config{}), stream.NewBroker(stream.Config{}))
+	accountCore.EXPECT().Get(gomock.Any(), gomock.Any(), "a0000000000001").DoAndReturn(
+		func(ctx context.Context, a *account.Account, id string) error {
+			a.OwnerID = "0c000000000002"
+			return nil
+		})
+
+	ctx := newContext(context.Background())
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: policy.RolePartner})
+	w := &recorder{}
+	resp := server.Stream(ctx, &dto.StreamTransactionRequest{AccountId: "a0000000000001"}, w)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+	assert.Zero(t, w.heartbeats)
+}
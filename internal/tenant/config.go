@@ -0,0 +1,39 @@
+package tenant
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"transaction-server/internal/common/db"
+)
+
+type Config struct {
+	// Header is the request header naming the tenant of a request, "X-Tenant-ID" by default.
+	Header string
+	// Default is the tenant of requests naming none, db.DefaultTenant by default.
+	Default string
+	// Tenants are the known tenants. Requests for any other tenant are refused; any tenant
+	// is accepted when empty.
+	Tenants []string
+}
+
+// Validate validates the known tenants include the default one.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Tenants, validation.Each(validation.Required), validation.By(func(interface{}) error {
+			defaultTenant := c.Default
+			if defaultTenant == "" {
+				defaultTenant = db.DefaultTenant
+			}
+			for _, tenantID := range c.Tenants {
+				if tenantID == defaultTenant {
+					return nil
+				}
+			}
+			if len(c.Tenants) > 0 {
+				return errors.New("must include the default tenant")
+			}
+			return nil
+		})),
+	)
+}
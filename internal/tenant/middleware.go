@@ -0,0 +1,32 @@
+package tenant
+
+import (
+	"net/http"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware resolves the tenant of each request and scopes the request context to it,
+// aborting the request when it names a tenant it may not act in. It runs after the
+// authentication middleware, if any.
+func Middleware(resolver *Resolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity, _ := auth.IdentityFromContext(ctx)
+		tenantID, err := resolver.Resolve(identity, ctx.GetHeader(resolver.Header()))
+		if err != nil {
+			code := ErrorCode(err)
+			status := http.StatusForbidden
+			if code == common.ErrValidationFailed {
+				status = http.StatusBadRequest
+			}
+			ctx.AbortWithStatusJSON(status, dto.GetErrorResponse(code, err.Error()))
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(db.WithTenant(ctx.Request.Context(), tenantID))
+		ctx.Next()
+	}
+}
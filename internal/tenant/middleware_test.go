@@ -0,0 +1,62 @@
+package tenant_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/tenant"
+)
+
+func setupRouter(identity *auth.Identity) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		if identity != nil {
+			ctx.Set(auth.ContextKeyIdentity, identity)
+		}
+	})
+	router.Use(tenant.Middleware(tenant.NewResolver(tenant.Config{Tenants: []string{"acme"}})))
+	router.GET("/accounts", func(ctx *gin.Context) {
+		tenantID, _ := db.TenantFromContext(ctx.Request.Context())
+		ctx.JSON(http.StatusOK, gin.H{"tenant": tenantID})
+	})
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		header   string
+		status   int
+		body     string
+	}{
+		{"default tenant", nil, "", http.StatusOK, `{"tenant":"default"}`},
+		{"named tenant", nil, "acme", http.StatusOK, `{"tenant":"acme"}`},
+		{"unknown tenant", nil, "globex", http.StatusForbidden,
+			`{"success":false,"error":{"code":"ERR_FORBIDDEN_ERROR","message":"unknown tenant: globex"}}`},
+		{"invalid tenant", nil, "../acme", http.StatusBadRequest,
+			`{"success":false,"error":{"code":"ERR_VALIDATION_FAILED_ERROR","message":"invalid tenant: \"../acme\""}}`},
+		{"client tenant", &auth.Identity{TenantID: "acme"}, "", http.StatusOK, `{"tenant":"acme"}`},
+		{"client naming another tenant", &auth.Identity{TenantID: "acme"}, "default", http.StatusForbidden,
+			`{"success":false,"error":{"code":"ERR_FORBIDDEN_ERROR","message":"tenant does not match the credentials: default"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+			if tt.header != "" {
+				req.Header.Set(tenant.DefaultHeader, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			setupRouter(tt.identity).ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.JSONEq(t, tt.body, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package tenant resolves the tenant of API requests. The tenant is put on the request
+// context, where db.Repo scopes every query to it.
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
+)
+
+// DefaultHeader is the request header naming the tenant when none is configured.
+const DefaultHeader = "X-Tenant-ID"
+
+var (
+	ErrInvalidTenant  = errors.New("invalid tenant")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrTenantMismatch = errors.New("tenant does not match the credentials")
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type Resolver struct {
+	config Config
+	known  map[string]bool
+}
+
+func NewResolver(config Config) *Resolver {
+	if config.Header == "" {
+		config.Header = DefaultHeader
+	}
+	if config.Default == "" {
+		config.Default = db.DefaultTenant
+	}
+	known := make(map[string]bool, len(config.Tenants))
+	for _, tenantID := range config.Tenants {
+		known[tenantID] = true
+	}
+	return &Resolver{config: config, known: known}
+}
+
+// Header returns the request header naming the tenant.
+func (r *Resolver) Header() string {
+	return r.config.Header
+}
+
+// Resolve returns the tenant of a request from the client that sent it and the tenant it
+// names. An authenticated client acts in the tenant it belongs to, and may only name that
+// one; requests served without authentication act in the tenant they name or the default.
+func (r *Resolver) Resolve(identity *auth.Identity, requested string) (string, error) {
+	if identity != nil {
+		tenantID := identity.TenantID
+		if tenantID == "" {
+			tenantID = r.config.Default
+		}
+		if requested != "" && requested != tenantID {
+			return "", fmt.Errorf("%w: %s", ErrTenantMismatch, requested)
+		}
+		return tenantID, nil
+	}
+	if requested == "" {
+		return r.config.Default, nil
+	}
+	if !tenantPattern.MatchString(requested) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTenant, requested)
+	}
+	if len(r.known) > 0 && !r.known[requested] {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTenant, requested)
+	}
+	return requested, nil
+}
+
+// ErrorCode returns the error code of a failure to resolve the tenant.
+func ErrorCode(err error) string {
+	if errors.Is(err, ErrInvalidTenant) {
+		return common.ErrValidationFailed
+	}
+	return common.ErrForbidden
+}
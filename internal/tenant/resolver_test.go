@@ -0,0 +1,36 @@
+package tenant_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/tenant"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver := tenant.NewResolver(tenant.Config{Tenants: []string{"acme", "globex"}})
+	tests := []struct {
+		name      string
+		identity  *auth.Identity
+		requested string
+		want      string
+		err       error
+	}{
+		{"default tenant", nil, "", "default", nil},
+		{"named tenant", nil, "acme", "acme", nil},
+		{"unknown tenant", nil, "initech", "", tenant.ErrUnknownTenant},
+		{"invalid tenant", nil, "Acme Corp", "", tenant.ErrInvalidTenant},
+		{"client tenant", &auth.Identity{TenantID: "globex"}, "", "globex", nil},
+		{"client naming its tenant", &auth.Identity{TenantID: "globex"}, "globex", "globex", nil},
+		{"client naming another tenant", &auth.Identity{TenantID: "globex"}, "acme", "", tenant.ErrTenantMismatch},
+		{"client without tenant", &auth.Identity{}, "", "default", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(tt.identity, tt.requested)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
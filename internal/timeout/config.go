@@ -0,0 +1,35 @@
+package timeout
+
+import (
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Default is the timeout of the routes without one in Routes; 0 sets none.
+	Default time.Duration
+	// Routes are the timeouts of routes by "METHOD /path" as registered, e.g.
+	// "POST /transactions", or by gRPC full method name; 0 sets none, as streams need.
+	// Routes match case-insensitively.
+	Routes map[string]time.Duration
+}
+
+// For returns the timeout of the route, 0 when it has none.
+func (c Config) For(route string) time.Duration {
+	for name, timeout := range c.Routes {
+		if strings.EqualFold(name, route) {
+			return timeout
+		}
+	}
+	return c.Default
+}
+
+// Validate validates the timeouts are not negative.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Default, validation.Min(time.Duration(0))),
+		validation.Field(&c.Routes, validation.Each(validation.Min(time.Duration(0)))),
+	)
+}
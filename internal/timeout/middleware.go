@@ -0,0 +1,34 @@
+// Package timeout bounds the time spent serving a request: its context is given the deadline
+// of its route, which cancels the queries it runs once the deadline passes.
+package timeout
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"transaction-server/internal/common"
+)
+
+// Middleware sets the deadline of the route on the context of each request.
+func Middleware(config Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout := config.For(ctx.Request.Method + " " + ctx.FullPath())
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
+
+// ErrorCode returns common.ErrTimeout when ctx timed out or was cancelled, failing the
+// request, and code otherwise.
+func ErrorCode(ctx context.Context, code string) string {
+	if ctx.Err() != nil {
+		return common.ErrTimeout
+	}
+	return code
+}
@@ -0,0 +1,65 @@
+package timeout_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/common"
+	"transaction-server/internal/timeout"
+)
+
+// setupRouter returns a router responding with the time left before the deadline of the
+// request, or "none".
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(timeout.Middleware(timeout.Config{
+		Default: time.Minute,
+		Routes:  map[string]time.Duration{"post /transactions/batch": time.Hour, "GET /stream": 0},
+	}))
+	deadline := func(ctx *gin.Context) {
+		if deadline, ok := ctx.Request.Context().Deadline(); ok {
+			ctx.String(http.StatusOK, time.Until(deadline).Round(time.Minute).String())
+			return
+		}
+		ctx.String(http.StatusOK, "none")
+	}
+	router.POST("/transactions", deadline)
+	router.POST("/transactions/batch", deadline)
+	router.GET("/stream", deadline)
+	return router
+}
+
+func TestMiddleware_Sets_Route_Deadline(t *testing.T) {
+	router := setupRouter()
+
+	for _, tt := range []struct{ method, path, want string }{
+		{http.MethodPost, "/transactions", "1m0s"},
+		{http.MethodPost, "/transactions/batch", "1h0m0s"},
+		{http.MethodGet, "/stream", "none"},
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, tt.want, recorder.Body.String(), tt.path)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	assert.Equal(t, common.ErrDBQueryError, timeout.ErrorCode(context.Background(), common.ErrDBQueryError))
+	assert.Equal(t, common.ErrTimeout, timeout.ErrorCode(ctx, common.ErrDBQueryError))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, timeout.Config{Default: time.Second, Routes: map[string]time.Duration{"GET /stream": 0}}.Validate())
+	assert.Error(t, timeout.Config{Default: -time.Second}.Validate())
+	assert.Error(t, timeout.Config{Routes: map[string]time.Duration{"GET /stream": -time.Second}}.Validate())
+}
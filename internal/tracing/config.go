@@ -0,0 +1,30 @@
+package tracing
+
+import validation "github.com/go-ozzo/ozzo-validation/v4"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	// Exporter is where spans are sent: "none", "stdout", or "otlp" for a collector.
+	Exporter string
+	// Endpoint is the host:port of the OTLP/HTTP collector, e.g. "localhost:4318".
+	Endpoint string
+	// Insecure sends the spans to the collector over plain HTTP.
+	Insecure bool
+	// SampleRatio is the fraction of the traces started here that are sampled; traces
+	// continued from a caller follow its decision. 0 samples every trace.
+	SampleRatio float64
+}
+
+// Validate validates the exporter and its endpoint.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Exporter, validation.In(ExporterNone, ExporterStdout, ExporterOTLP)),
+		validation.Field(&c.Endpoint, validation.Required.When(c.Exporter == ExporterOTLP)),
+		validation.Field(&c.SampleRatio, validation.Min(0.0), validation.Max(1.0)),
+	)
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin traces each statement run by gorm as a child span of the span of its context.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", p.start("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", p.end),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", p.start("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", p.end),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", p.start("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", p.end),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", p.start("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", p.end),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", p.start("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", p.end),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", p.start("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", p.end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start starts the span of the statement. Statements outside of a trace are not traced.
+func (GormPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if !trace.SpanContextFromContext(db.Statement.Context).IsValid() {
+			return
+		}
+		_, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemKey.String(db.Dialector.Name()), semconv.DBOperation(operation)))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (GormPlugin) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+	span.SetAttributes(
+		semconv.DBSQLTable(db.Statement.Table),
+		semconv.DBStatement(db.Statement.SQL.String()),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
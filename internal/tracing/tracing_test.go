@@ -0,0 +1,87 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"transaction-server/internal/tracing"
+)
+
+type row struct {
+	ID   int
+	Name string
+}
+
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(trace.NewNoopTracerProvider()) })
+	return recorder
+}
+
+func TestMiddleware_Traces_Request_And_Queries(t *testing.T) {
+	recorder := setupTracing(t)
+	gDb, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gDb.Use(tracing.GormPlugin{}))
+	require.NoError(t, gDb.AutoMigrate(&row{}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(tracing.Middleware())
+	router.POST("/rows/:name", func(ctx *gin.Context) {
+		spanCtx, span := tracing.Start(ctx.Request.Context(), "row.Core.Create")
+		err := gDb.WithContext(spanCtx).Create(&row{Name: ctx.Param("name")}).Error
+		tracing.End(span, err)
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/rows/a", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	query, core, server := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "POST /rows/:name", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", 500))
+	assert.Equal(t, codes.Error, server.Status().Code)
+
+	assert.Equal(t, "row.Core.Create", core.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), core.Parent().SpanID())
+
+	assert.Equal(t, "gorm.create", query.Name())
+	assert.Equal(t, core.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Contains(t, query.Attributes(), attribute.String("db.sql.table", "rows"))
+	assert.Contains(t, query.Attributes(), attribute.String("db.system", "sqlite"))
+}
+
+func TestStart_Outside_Trace(t *testing.T) {
+	recorder := setupTracing(t)
+
+	ctx := context.Background()
+	spanCtx, span := tracing.Start(ctx, "row.Core.Create")
+	tracing.End(span, nil)
+
+	assert.Equal(t, ctx, spanCtx)
+	assert.Empty(t, recorder.Ended())
+}
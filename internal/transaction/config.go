@@ -0,0 +1,54 @@
+package transaction
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"transaction-server/internal/dto"
+)
+
+// Config holds configuration of the transaction APIs.
+type Config struct {
+	// BatchMaxSize is the maximum number of transactions accepted by a batch request.
+	BatchMaxSize int
+	// Tenants overrides the configuration for some tenants, by tenant ID.
+	Tenants map[string]TenantConfig
+}
+
+// TenantConfig holds the configuration of the transaction APIs for a tenant.
+type TenantConfig struct {
+	// OperationTypes are the operation types the tenant may post; all of them when empty.
+	OperationTypes []string
+	// MaxAmount is the largest amount of a transaction; unlimited when 0.
+	MaxAmount float64
+	// BatchMaxSize overrides Config.BatchMaxSize when set.
+	BatchMaxSize int
+}
+
+// Validate validates the batch size and the tenant overrides.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.BatchMaxSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.Tenants),
+	)
+}
+
+// Validate validates the operation types and limits of a tenant.
+func (c TenantConfig) Validate() error {
+	operationTypes := make([]interface{}, 0, len(dto.OperationTypes))
+	for _, operationType := range dto.OperationTypes {
+		operationTypes = append(operationTypes, operationType)
+	}
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.OperationTypes, validation.Each(validation.In(operationTypes...))),
+		validation.Field(&c.MaxAmount, validation.Min(0.0)),
+		validation.Field(&c.BatchMaxSize, validation.Min(0)),
+	)
+}
+
+// ForTenant returns the configuration of the tenant.
+func (c Config) ForTenant(tenantID string) TenantConfig {
+	tenantConfig := c.Tenants[tenantID]
+	if tenantConfig.BatchMaxSize == 0 {
+		tenantConfig.BatchMaxSize = c.BatchMaxSize
+	}
+	return tenantConfig
+}
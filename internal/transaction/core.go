@@ -2,41 +2,266 @@ package transaction
 
 import (
 	"context"
+	"errors"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/utils"
+	"log/slog"
 	"math"
+	"time"
+	"transaction-server/internal/account"
+	"transaction-server/internal/common/currency"
 	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/metrics"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/statement"
+	"transaction-server/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrBatchAborted is reported for the transactions of an atomic batch rolled back because of another item.
+var ErrBatchAborted = errors.New("batch rolled back")
+
 type ICore interface {
 	Create(ctx context.Context, model *Transaction) error
+	CreateBatch(ctx context.Context, models []*Transaction, atomic bool) []error
 	Get(ctx context.Context, model *Transaction, id string) error
-	List(ctx context.Context, request IListRequest) (*[]Transaction, error)
+	List(ctx context.Context, request IListRequest) (*[]Transaction, *db.Page, error)
+	Export(ctx context.Context, request IExportRequest, writer statement.Writer) error
 }
 
+// exportPageSize is the number of transactions read at a time while exporting a statement.
+const exportPageSize = 500
+
 type Core struct {
-	repo IRepo
+	repo   IRepo
+	fxCore fxrate.ICore
+	outbox outbox.ICore
 }
 
 func (c Core) Create(ctx context.Context, model *Transaction) error {
-	return c.repo.Transaction(ctx, func(ctx context.Context) error {
-		if !utils.Contains(dto.NegativeOperationTypesString, model.OperationType.String()) {
-			if err2 := c.checkAndUpdateExistingBalances(ctx, model); err2 != nil {
-				return err2
+	ctx, span := tracing.Start(ctx, "transaction.Core.Create", trace.WithAttributes(
+		attribute.String("account.id", model.AccountId), attribute.String("transaction.operation_type", model.OperationType.String())))
+	original := *model
+	err := c.repo.Transaction(ctx, func(ctx context.Context) error {
+		// A retried DB transaction starts over from the transaction as given.
+		*model = original
+		if err := c.prepare(ctx, model); err != nil {
+			return err
+		}
+		if err := c.repo.Create(ctx, model); err != nil {
+			return err
+		}
+		return c.recordCreated(ctx, model)
+	})
+	if err == nil {
+		slog.InfoContext(ctx, "transaction created", "transaction_id", model.ID, "account_id", model.AccountId,
+			"operation_type", model.OperationType.String(), "discharges", len(model.Discharges))
+		observeCreated(model)
+		span.SetAttributes(attribute.String("transaction.id", model.ID))
+	}
+	tracing.End(span, err)
+	return err
+}
+
+// observeCreated records the metrics of a committed transaction.
+func observeCreated(model *Transaction) {
+	metrics.ObserveTransactionCreated(model.OperationType.String())
+	for _, discharge := range model.Discharges {
+		metrics.ObserveDischarge(discharge.Currency, discharge.Amount)
+	}
+}
+
+// recordCreated records the TransactionCreated event of a created transaction and a
+// BalanceDischarged event for each debit it discharged.
+func (c Core) recordCreated(ctx context.Context, model *Transaction) error {
+	event, err := outbox.NewEvent(outbox.TypeTransactionCreated, outbox.AggregateTransaction, model.ID, model.ToDto())
+	if err != nil {
+		return err
+	}
+	events := []*outbox.Event{event}
+	for _, discharge := range model.Discharges {
+		discharge.DischargedBy = model.ID
+		event, err := outbox.NewEvent(outbox.TypeBalanceDischarged, outbox.AggregateTransaction, discharge.TransactionID, discharge)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+	return c.outbox.Record(ctx, events...)
+}
+
+// CreateBatch creates the transactions in the given order, so debts are discharged
+// deterministically, and returns the error of each transaction (nil on success).
+//
+// In atomic mode the whole batch runs in one DB transaction: the first failure rolls it
+// back and every other transaction reports ErrBatchAborted. Runs of consecutive debits,
+// which discharge nothing, are inserted together. Otherwise each transaction is
+// created on its own, as by Create.
+func (c Core) CreateBatch(ctx context.Context, models []*Transaction, atomic bool) []error {
+	errs := make([]error, len(models))
+	if !atomic {
+		for i, model := range models {
+			errs[i] = c.Create(ctx, model)
+		}
+		return errs
+	}
+
+	failed := false
+	originals := make([]Transaction, len(models))
+	for i, model := range models {
+		originals[i] = *model
+	}
+	err := c.repo.Transaction(ctx, func(ctx context.Context) error {
+		// A retried DB transaction starts over from the transactions as given.
+		for i, model := range models {
+			*model, errs[i] = originals[i], nil
+		}
+		failed = false
+		debits := make([]*Transaction, 0)
+		first := 0
+		flush := func() error {
+			if len(debits) == 0 {
+				return nil
+			}
+			if err := c.repo.CreateInBatches(ctx, debits, len(debits)); err != nil {
+				for i := range debits {
+					errs[first+i] = err
+				}
+				return err
+			}
+			for i, debit := range debits {
+				if err := c.recordCreated(ctx, debit); err != nil {
+					errs[first+i] = err
+					return err
+				}
+			}
+			debits = debits[:0]
+			return nil
+		}
+		for i, model := range models {
+			if !isDebit(model) {
+				if err := flush(); err != nil {
+					failed = true
+					return err
+				}
+			}
+			if err := c.prepare(ctx, model); err != nil {
+				errs[i], failed = err, true
+				return err
 			}
+			if isDebit(model) {
+				// Inserted in batches, the debits skip the checks of Create, so run them here.
+				if err := validate(model); err != nil {
+					errs[i], failed = err, true
+					return err
+				}
+				if len(debits) == 0 {
+					first = i
+				}
+				debits = append(debits, model)
+				continue
+			}
+			if err := c.repo.Create(ctx, model); err != nil {
+				errs[i], failed = err, true
+				return err
+			}
+			if err := c.recordCreated(ctx, model); err != nil {
+				errs[i], failed = err, true
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			failed = true
+			return err
 		}
-		return c.repo.Create(ctx, model)
+		return nil
 	})
+	if err != nil && !failed {
+		// the commit itself failed, so no transaction was created
+		for i := range errs {
+			errs[i] = err
+		}
+	} else if failed {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = ErrBatchAborted
+			}
+		}
+	} else {
+		for _, model := range models {
+			observeCreated(model)
+		}
+	}
+	return errs
+}
+
+// validate sets the defaults of the transaction and validates it, as Repo.Create does
+// before inserting a record.
+func validate(model *Transaction) error {
+	if err := model.SetDefaults(); err != nil {
+		return err
+	}
+	return model.Validate()
+}
+
+// prepare settles the transaction and, unless it is a debit, discharges open debits of the account with it.
+func (c Core) prepare(ctx context.Context, model *Transaction) error {
+	if err := c.settle(ctx, model); err != nil {
+		return err
+	}
+	if !isDebit(model) {
+		return c.checkAndUpdateExistingBalances(ctx, model)
+	}
+	return nil
+}
+
+// isDebit reports whether the transaction is of a negative operation type.
+func isDebit(model *Transaction) bool {
+	return utils.Contains(dto.NegativeOperationTypesString, model.OperationType.String())
+}
+
+// settle converts the transaction amount into the account currency using the
+// rate effective at the event date, keeping the original amount and currency.
+func (c Core) settle(ctx context.Context, model *Transaction) error {
+	acc := new(account.Account)
+	if err := c.repo.FindByID(ctx, acc, model.AccountId); err != nil {
+		return err
+	}
+	model.SettledCurrency = acc.Currency
+	if model.SettledCurrency == "" {
+		model.SettledCurrency = currency.Default
+	}
+	if model.Currency == "" {
+		model.Currency = model.SettledCurrency
+	}
+	rate := 1.0
+	if model.Currency != model.SettledCurrency {
+		var err error
+		if rate, err = c.fxCore.GetRate(ctx, model.Currency, model.SettledCurrency, model.EventDate); err != nil {
+			return err
+		}
+	}
+	model.FxRate = rate
+	model.SettledAmount = currency.Round(model.Amount*rate, model.SettledCurrency)
+	return nil
 }
 
 func (c Core) checkAndUpdateExistingBalances(ctx context.Context, model *Transaction) error {
 	listRequest := &dto.ListTransactionRequest{
-		AccountId:      model.AccountId,
-		OperationTypes: dto.NegativeOperationTypesString,
-		Limit:          100,
+		AccountId:       model.AccountId,
+		OperationTypes:  dto.NegativeOperationTypesString,
+		OnlyOpenBalance: true,
+		Limit:           100,
 	}
-	transactionResponseList, err := c.List(ctx, listRequest)
+	// Only entries in the same currency are offset unless conversion is explicitly requested.
+	if !model.ConvertCurrency {
+		listRequest.Currency = model.Currency
+	}
+	transactionResponseList, _, err := c.List(ctx, listRequest)
 	if err != nil {
 		return err
 	}
@@ -58,16 +283,37 @@ func (c Core) updateExistingBalances(ctx context.Context, model *Transaction, tr
 		if remainingBal == 0 {
 			break
 		}
-		if math.Abs(transaction.Balance) <= remainingBal {
-			remainingBal = remainingBal - math.Abs(transaction.Balance)
+		// Entries discharged already have nothing left to discharge.
+		if transaction.Balance == 0 {
+			continue
+		}
+		// rate converts the remaining balance into the currency of the entry being discharged.
+		rate := 1.0
+		if transaction.Currency != model.Currency {
+			if rate, err = c.fxCore.GetRate(ctx, model.Currency, transaction.Currency, model.EventDate); err != nil {
+				return 0, err
+			}
+		}
+		available := currency.Round(remainingBal*rate, transaction.Currency)
+		open := transaction.Balance
+		if math.Abs(transaction.Balance) <= available {
+			available = available - math.Abs(transaction.Balance)
 			transaction.Balance = 0
 		} else {
-			transaction.Balance = remainingBal - math.Abs(transaction.Balance)
-			remainingBal = 0
+			transaction.Balance = available - math.Abs(transaction.Balance)
+			available = 0
 		}
+		remainingBal = currency.Round(available/rate, model.Currency)
 		if err = c.repo.Update(ctx, &transaction, "balance"); err != nil {
 			return 0, err
 		}
+		model.Discharges = append(model.Discharges, outbox.BalanceDischarged{
+			TransactionID:    transaction.ID,
+			AccountID:        transaction.AccountId,
+			Amount:           currency.Round(math.Abs(open)-math.Abs(transaction.Balance), transaction.Currency),
+			Currency:         transaction.Currency,
+			RemainingBalance: transaction.Balance,
+		})
 	}
 	return remainingBal, nil
 }
@@ -76,7 +322,19 @@ func (c Core) Get(ctx context.Context, model *Transaction, id string) error {
 	return c.repo.FindByID(ctx, model, id)
 }
 
-func (c Core) List(ctx context.Context, request IListRequest) (*[]Transaction, error) {
+// List returns the transactions matching the request. Requests sorted by created_at without
+// an offset are paginated by cursor and return the cursors of the adjacent pages.
+func (c Core) List(ctx context.Context, request IListRequest) (*[]Transaction, *db.Page, error) {
+	ctx, span := tracing.Start(ctx, "transaction.Core.List")
+	transactions, page, err := c.list(ctx, request)
+	if err == nil {
+		span.SetAttributes(attribute.Int("transaction.count", len(*transactions)))
+	}
+	tracing.End(span, err)
+	return transactions, page, err
+}
+
+func (c Core) list(ctx context.Context, request IListRequest) (*[]Transaction, *db.Page, error) {
 	conditions := make([]clause.Expression, 0)
 	if request.GetAccountId() != "" {
 		conditions = append(conditions, clause.Eq{Column: "account_id", Value: request.GetAccountId()})
@@ -87,20 +345,206 @@ func (c Core) List(ctx context.Context, request IListRequest) (*[]Transaction, e
 	if len(request.GetOperationTypes()) > 0 {
 		conditions = append(conditions, clause.IN{Column: "operation_type", Values: OperationFromStrings(request.GetOperationTypes())})
 	}
+	if request.GetCurrency() != "" {
+		conditions = append(conditions, clause.Eq{Column: "currency", Value: request.GetCurrency()})
+	}
+	if request.GetEventDateFrom() != 0 {
+		conditions = append(conditions, clause.Gte{Column: "event_date", Value: request.GetEventDateFrom()})
+	}
+	if request.GetEventDateTo() != 0 {
+		conditions = append(conditions, clause.Lte{Column: "event_date", Value: request.GetEventDateTo()})
+	}
+	if request.GetCreatedAtFrom() != 0 {
+		conditions = append(conditions, clause.Gte{Column: "created_at", Value: request.GetCreatedAtFrom()})
+	}
+	if request.GetCreatedAtTo() != 0 {
+		conditions = append(conditions, clause.Lte{Column: "created_at", Value: request.GetCreatedAtTo()})
+	}
+	if request.GetMinAmount() != nil {
+		conditions = append(conditions, clause.Gte{Column: "amount", Value: *request.GetMinAmount()})
+	}
+	if request.GetMaxAmount() != nil {
+		conditions = append(conditions, clause.Lte{Column: "amount", Value: *request.GetMaxAmount()})
+	}
+	if request.GetOnlyOpenBalance() {
+		conditions = append(conditions, clause.Neq{Column: "balance", Value: 0})
+	}
+	listResponse := make([]Transaction, 0)
+	if IsCursorPaginated(request) {
+		repoRequest := &db.FindManyWithCursorRequest{
+			Limit:      request.GetLimit(),
+			Conditions: conditions,
+			Cursor:     request.GetCursor(),
+			Ascending:  request.GetSortOrder() == dto.SortOrderAsc,
+		}
+		page, err := c.repo.FindManyWithCursor(ctx, &listResponse, repoRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &listResponse, page, nil
+	}
 	repoRequest := &db.FindManyWithConditionsRequest{
 		FindManyRequest: db.FindManyRequest{
 			Limit:  request.GetLimit(),
 			Offset: request.GetOffset(),
 		},
 		Conditions: conditions,
+		OrderBy:    listOrderBy(request),
 	}
-	listResponse := make([]Transaction, 0)
 	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, nil, err
+	}
+	return &listResponse, &db.Page{}, nil
+}
+
+// Export writes the statement of an account for the event dates between from and to (inclusive)
+// to writer, reading the transactions page by page in the order they were posted. Amounts and
+// balances are settled amounts in the account currency. A missing from defaults to the account
+// creation and a missing to to now.
+//
+// The transactions are read in two passes, one for the opening and closing balances and one for
+// the entries, both bounded by the last transaction posted when the export starts, so they see
+// the same transactions without holding a DB transaction while the statement is written.
+func (c Core) Export(ctx context.Context, request IExportRequest, writer statement.Writer) error {
+	// Pages are read from the primary, where every transaction up to the bound is found.
+	ctx = db.WithPrimary(ctx)
+	acc := new(account.Account)
+	if err := c.repo.FindByID(ctx, acc, request.GetAccountId()); err != nil {
+		return err
+	}
+	from, to := request.GetFrom(), request.GetTo()
+	if from == 0 {
+		from = acc.CreatedAt
+	}
+	if to == 0 {
+		to = time.Now().Unix()
+	}
+	header := &statement.Header{
+		AccountID:   acc.ID,
+		Currency:    acc.Currency,
+		From:        time.Unix(from, 0).UTC(),
+		To:          time.Unix(to, 0).UTC(),
+		GeneratedAt: time.Now().UTC(),
+	}
+	if header.Currency == "" {
+		header.Currency = currency.Default
+	}
+
+	bound, err := c.lastPosted(ctx, acc.ID)
+	if err != nil {
+		return err
+	}
+	if bound != nil {
+		err = c.eachTransaction(ctx, acc.ID, bound, 0, to, func(model *Transaction) error {
+			if model.EventDate < from {
+				header.OpeningBalance += model.SettledAmount
+			}
+			header.ClosingBalance += model.SettledAmount
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	header.OpeningBalance = currency.Round(header.OpeningBalance, header.Currency)
+	header.ClosingBalance = currency.Round(header.ClosingBalance, header.Currency)
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+
+	if bound != nil {
+		balance := header.OpeningBalance
+		err = c.eachTransaction(ctx, acc.ID, bound, from, to, func(model *Transaction) error {
+			balance = currency.Round(balance+model.SettledAmount, header.Currency)
+			return writer.WriteEntry(&statement.Entry{
+				ID:               model.ID,
+				OperationType:    model.OperationType.String(),
+				EventDate:        time.Unix(model.EventDate, 0).UTC(),
+				Amount:           model.SettledAmount,
+				OriginalAmount:   model.Amount,
+				OriginalCurrency: model.Currency,
+				FxRate:           model.FxRate,
+				Balance:          balance,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// lastPosted returns the position of the last transaction posted to the account, nil if
+// there is none.
+func (c Core) lastPosted(ctx context.Context, accountID string) (*db.Cursor, error) {
+	last := make([]Transaction, 0, 1)
+	_, err := c.repo.FindManyWithCursor(ctx, &last, &db.FindManyWithCursorRequest{
+		Limit:      1,
+		Conditions: []clause.Expression{clause.Eq{Column: "account_id", Value: accountID}},
+	})
+	if err != nil || len(last) == 0 {
 		return nil, err
 	}
-	return &listResponse, nil
+	return &db.Cursor{CreatedAt: last[0].CreatedAt, ID: last[0].ID}, nil
+}
+
+// eachTransaction calls fn, in the order they were posted, for the transactions of the account
+// posted up to bound with an event date between from and to (inclusive, 0 for unbounded).
+func (c Core) eachTransaction(ctx context.Context, accountID string, bound *db.Cursor, from, to int64, fn func(model *Transaction) error) error {
+	conditions := []clause.Expression{
+		clause.Eq{Column: "account_id", Value: accountID},
+		clause.Or(
+			clause.Lt{Column: "created_at", Value: bound.CreatedAt},
+			clause.And(
+				clause.Eq{Column: "created_at", Value: bound.CreatedAt},
+				clause.Lte{Column: "id", Value: bound.ID},
+			),
+		),
+	}
+	if from != 0 {
+		conditions = append(conditions, clause.Gte{Column: "event_date", Value: from})
+	}
+	if to != 0 {
+		conditions = append(conditions, clause.Lte{Column: "event_date", Value: to})
+	}
+	request := &db.FindManyWithCursorRequest{Limit: exportPageSize, Conditions: conditions, Ascending: true}
+	for {
+		transactions := make([]Transaction, 0, exportPageSize)
+		page, err := c.repo.FindManyWithCursor(ctx, &transactions, request)
+		if err != nil {
+			return err
+		}
+		for i := range transactions {
+			if err := fn(&transactions[i]); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		request.Cursor = page.NextCursor
+	}
+}
+
+// IsCursorPaginated reports whether the request is served by keyset pagination on (created_at, id).
+func IsCursorPaginated(request IListRequest) bool {
+	return request.GetOffset() == 0 && (request.GetSortBy() == "" || request.GetSortBy() == "created_at")
+}
+
+// listOrderBy builds the ordering of a list request. Ties are broken by id so pages are stable.
+// Sort fields are expected to be validated against dto.TransactionSortFields.
+func listOrderBy(request IListRequest) clause.OrderBy {
+	sortBy := request.GetSortBy()
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	desc := request.GetSortOrder() != dto.SortOrderAsc
+	return clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: sortBy}, Desc: desc},
+		{Column: clause.Column{Name: "id"}, Desc: desc},
+	}}
 }
 
-func NewCore(repo IRepo) ICore {
-	return &Core{repo: repo}
+func NewCore(repo IRepo, fxCore fxrate.ICore, outbox outbox.ICore) ICore {
+	return &Core{repo: repo, fxCore: fxCore, outbox: outbox}
 }
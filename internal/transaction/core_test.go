@@ -1,12 +1,19 @@
 package transaction_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"gorm.io/gorm/clause"
 	"testing"
+	"transaction-server/internal/account"
 	db2 "transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	fxmock "transaction-server/internal/fxrate/mock"
+	"transaction-server/internal/outbox"
+	outboxmock "transaction-server/internal/outbox/mock"
+	"transaction-server/internal/statement"
 	"transaction-server/internal/transaction/mock"
 
 	"github.com/stretchr/testify/assert"
@@ -16,20 +23,41 @@ import (
 type testDependencies struct {
 	mockRepoCtrl *gomock.Controller
 	mockRepo     *mock.MockIRepo
+	mockFxCore   *fxmock.MockICore
+	mockOutbox   *outboxmock.MockICore
 	core         transaction.ICore
 }
 
 func setupTest(t *testing.T) *testDependencies {
 	mockRepoCtrl := gomock.NewController(t)
 	mockRepo := mock.NewMockIRepo(mockRepoCtrl)
-	core := transaction.NewCore(mockRepo)
+	mockFxCore := fxmock.NewMockICore(mockRepoCtrl)
+	mockOutbox := outboxmock.NewMockICore(mockRepoCtrl)
+	core := transaction.NewCore(mockRepo, mockFxCore, mockOutbox)
 	return &testDependencies{
 		mockRepoCtrl: mockRepoCtrl,
 		mockRepo:     mockRepo,
+		mockFxCore:   mockFxCore,
+		mockOutbox:   mockOutbox,
 		core:         core,
 	}
 }
 
+// expectEvents expects the events of a created transaction to be recorded.
+func expectEvents(td *testDependencies) {
+	td.mockOutbox.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil)
+}
+
+// expectAccount expects the account of the transaction to be loaded with the given currency.
+func expectAccount(td *testDependencies, currency string) {
+	td.mockRepo.EXPECT().FindByID(gomock.Any(), gomock.AssignableToTypeOf(&account.Account{}), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, receiver db2.IModel, id string) error {
+			receiver.(*account.Account).Currency = currency
+			return nil
+		},
+	)
+}
+
 func teardownTest(td *testDependencies) {
 	td.mockRepoCtrl.Finish()
 }
@@ -49,7 +77,9 @@ func TestCore_Create_Success_With_Negative_Balance(t *testing.T) {
 			return fc(ctx)
 		},
 	)
+	expectAccount(td, "USD")
 	td.mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	expectEvents(td)
 
 	err := td.core.Create(ctx, model)
 	assert.NoError(t, err)
@@ -71,8 +101,10 @@ func TestCore_Create_Success_With_Positive_Balance_And_NO_Existing_Balance(t *te
 			return fc(ctx)
 		},
 	)
-	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).Return(&db2.Page{}, nil)
 	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	expectEvents(td)
 
 	err := td.core.Create(ctx, model)
 	assert.NoError(t, err)
@@ -95,31 +127,49 @@ func TestCore_Create_Success_With_Positive_Balance_And_Existing_Negative_Balance
 			return fc(ctx)
 		},
 	)
-	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
-		func(ctx context.Context, models interface{}, req db2.FindManyWithFiltersRequester) error {
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
 			receiver := models.(*[]transaction.Transaction)
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -50,
 				Balance:       -50,
+				Currency:      "USD",
 			})
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -23.5,
 				Balance:       -23.5,
+				Currency:      "USD",
 			})
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -18.7,
 				Balance:       -18.7,
+				Currency:      "USD",
 			})
-			return nil
+			return &db2.Page{}, nil
 		})
 	td.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
-	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	td.mockRepo.EXPECT().Create(ctx, model).DoAndReturn(
+		func(ctx context.Context, receiver db2.IModel) error {
+			model.ID = "credit_id"
+			return nil
+		})
+	td.mockOutbox.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, events ...*outbox.Event) error {
+			assert.Len(t, events, 3)
+			assert.Equal(t, outbox.TypeTransactionCreated, events[0].Type)
+			assert.Equal(t, "credit_id", events[0].AggregateID)
+			assert.Equal(t, outbox.TypeBalanceDischarged, events[1].Type)
+			assert.JSONEq(t, `{"transaction_id":"","account_id":"some_id","discharged_by":"credit_id","amount":50,"currency":"USD","remaining_balance":0}`, events[1].Payload)
+			assert.JSONEq(t, `{"transaction_id":"","account_id":"some_id","discharged_by":"credit_id","amount":10,"currency":"USD","remaining_balance":-13.5}`, events[2].Payload)
+			return nil
+		})
 
 	err := td.core.Create(ctx, model)
 	assert.NoError(t, err)
@@ -143,31 +193,36 @@ func TestCore_Create_Success_With_Positive_Balance_And_Existing_Negative_Balance
 			return fc(ctx)
 		},
 	)
-	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
-		func(ctx context.Context, models interface{}, req db2.FindManyWithFiltersRequester) error {
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
 			receiver := models.(*[]transaction.Transaction)
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -50,
 				Balance:       -50,
+				Currency:      "USD",
 			})
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -23.5,
 				Balance:       -23.5,
+				Currency:      "USD",
 			})
 			*receiver = append(*receiver, transaction.Transaction{
 				AccountId:     "some_id",
 				OperationType: dto.OperationTypeWithdraw,
 				Amount:        -18.7,
 				Balance:       -18.7,
+				Currency:      "USD",
 			})
-			return nil
+			return &db2.Page{}, nil
 		})
 	td.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
 	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	expectEvents(td)
 
 	err := td.core.Create(ctx, model)
 	assert.NoError(t, err)
@@ -195,9 +250,9 @@ func TestCore_List_Success(t *testing.T) {
 	ctx := context.Background()
 	request := &dto.ListTransactionRequest{}
 
-	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).Return(&db2.Page{}, nil)
 
-	transactions, err := td.core.List(ctx, request)
+	transactions, _, err := td.core.List(ctx, request)
 	assert.NoError(t, err)
 	assert.NotNil(t, transactions)
 }
@@ -209,9 +264,457 @@ func TestCore_List_RepoError(t *testing.T) {
 	ctx := context.Background()
 	request := &dto.ListTransactionRequest{}
 
-	td.mockRepo.EXPECT().FindManyWithFilters(ctx, gomock.Any(), gomock.Any()).Return(errors.New("repo error"))
+	td.mockRepo.EXPECT().FindManyWithCursor(ctx, gomock.Any(), gomock.Any()).Return(nil, errors.New("repo error"))
 
-	transactions, err := td.core.List(ctx, request)
+	transactions, _, err := td.core.List(ctx, request)
 	assert.Error(t, err)
 	assert.Nil(t, transactions)
 }
+
+func TestCore_Create_Settles_Foreign_Currency_Into_Account_Currency(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	model := &transaction.Transaction{
+		AccountId:     "some_id",
+		OperationType: dto.OperationTypeWithdraw,
+		Amount:        -50,
+		Balance:       -50,
+		Currency:      "EUR",
+		EventDate:     1710000000,
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	td.mockFxCore.EXPECT().GetRate(gomock.Any(), "EUR", "USD", int64(1710000000)).Return(1.085, nil)
+	td.mockRepo.EXPECT().Create(gomock.Any(), model).Return(nil)
+	expectEvents(td)
+
+	err := td.core.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", model.Currency)
+	assert.Equal(t, -50.0, model.Amount)
+	assert.Equal(t, "USD", model.SettledCurrency)
+	assert.Equal(t, -54.25, model.SettledAmount)
+	assert.Equal(t, 1.085, model.FxRate)
+}
+
+func TestCore_Create_Discharges_Only_Same_Currency(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	model := &transaction.Transaction{
+		AccountId:     "some_id",
+		OperationType: dto.OperationTypeCreditVoucher,
+		Amount:        60,
+		Balance:       60,
+		Currency:      "USD",
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+			assert.Contains(t, req.GetConditions(), clause.Eq{Column: "currency", Value: "USD"})
+			return &db2.Page{}, nil
+		})
+	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	expectEvents(td)
+
+	err := td.core.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, model.Balance)
+}
+
+func TestCore_Create_Discharges_Only_Open_Balances(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	model := &transaction.Transaction{
+		AccountId:     "some_id",
+		OperationType: dto.OperationTypeCreditVoucher,
+		Amount:        60,
+		Balance:       60,
+		Currency:      "USD",
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+			assert.Contains(t, req.GetConditions(), clause.Neq{Column: "balance", Value: 0})
+			receiver := models.(*[]transaction.Transaction)
+			*receiver = append(*receiver,
+				transaction.Transaction{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Amount: -20, Balance: 0, Currency: "USD"},
+				transaction.Transaction{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Amount: -30, Balance: -30, Currency: "USD"},
+			)
+			return &db2.Page{}, nil
+		})
+	td.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	td.mockOutbox.EXPECT().Record(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, events ...*outbox.Event) error {
+			// No discharge of the entry discharged already is recorded.
+			assert.Len(t, events, 2)
+			assert.Equal(t, outbox.TypeBalanceDischarged, events[1].Type)
+			assert.JSONEq(t, `{"transaction_id":"","account_id":"some_id","discharged_by":"","amount":30,"currency":"USD","remaining_balance":0}`, events[1].Payload)
+			return nil
+		})
+
+	err := td.core.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, 30.0, model.Balance)
+	assert.Len(t, model.Discharges, 1)
+}
+
+func TestCore_Create_Discharges_Other_Currency_When_Conversion_Requested(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	model := &transaction.Transaction{
+		AccountId:       "some_id",
+		OperationType:   dto.OperationTypeCreditVoucher,
+		Amount:          100,
+		Balance:         100,
+		Currency:        "USD",
+		ConvertCurrency: true,
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+			assert.NotContains(t, req.GetConditions(), clause.Eq{Column: "currency", Value: "USD"})
+			receiver := models.(*[]transaction.Transaction)
+			*receiver = append(*receiver, transaction.Transaction{
+				AccountId:     "some_id",
+				OperationType: dto.OperationTypeWithdraw,
+				Amount:        -40,
+				Balance:       -40,
+				Currency:      "EUR",
+			})
+			return &db2.Page{}, nil
+		})
+	td.mockFxCore.EXPECT().GetRate(gomock.Any(), "USD", "EUR", gomock.Any()).Return(0.8, nil)
+	td.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any(), "balance").DoAndReturn(
+		func(ctx context.Context, receiver db2.IModel, selectiveList ...string) error {
+			assert.Equal(t, 0.0, receiver.(*transaction.Transaction).Balance)
+			return nil
+		})
+	td.mockRepo.EXPECT().Create(ctx, model).Return(nil)
+	expectEvents(td)
+
+	err := td.core.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, model.Balance)
+}
+
+func TestCore_Create_Starts_Over_When_Retried(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	model := &transaction.Transaction{
+		AccountId:     "some_id",
+		OperationType: dto.OperationTypeCreditVoucher,
+		Amount:        100,
+		Balance:       100,
+		Currency:      "USD",
+	}
+	errDeadlock := errors.New("deadlock")
+
+	// The DB transaction fails on the insert, then is run again.
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			assert.ErrorIs(t, fc(ctx), errDeadlock)
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+			receiver := models.(*[]transaction.Transaction)
+			*receiver = append(*receiver, transaction.Transaction{
+				AccountId:     "some_id",
+				OperationType: dto.OperationTypeWithdraw,
+				Amount:        -40,
+				Balance:       -40,
+				Currency:      "USD",
+			})
+			return &db2.Page{}, nil
+		}).Times(2)
+	td.mockRepo.EXPECT().Update(gomock.Any(), gomock.Any(), "balance").Return(nil).Times(2)
+	gomock.InOrder(
+		td.mockRepo.EXPECT().Create(gomock.Any(), model).Return(errDeadlock),
+		td.mockRepo.EXPECT().Create(gomock.Any(), model).Return(nil),
+	)
+	expectEvents(td)
+
+	err := td.core.Create(ctx, model)
+	assert.NoError(t, err)
+	assert.Equal(t, 60.0, model.Balance)
+	assert.Len(t, model.Discharges, 1)
+}
+
+func TestCore_List_Builds_Filters_And_Sorting(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	minAmount, maxAmount := -100.0, 50.0
+	request := &dto.ListTransactionRequest{
+		AccountId:       "some_id",
+		EventDateFrom:   1700000000,
+		EventDateTo:     1710000000,
+		CreatedAtFrom:   1700000001,
+		CreatedAtTo:     1710000001,
+		MinAmount:       &minAmount,
+		MaxAmount:       &maxAmount,
+		OnlyOpenBalance: true,
+		SortBy:          "amount",
+		SortOrder:       dto.SortOrderAsc,
+	}
+
+	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithFiltersRequester) error {
+			conditions := req.GetConditions()
+			assert.Contains(t, conditions, clause.Eq{Column: "account_id", Value: "some_id"})
+			assert.Contains(t, conditions, clause.Gte{Column: "event_date", Value: int64(1700000000)})
+			assert.Contains(t, conditions, clause.Lte{Column: "event_date", Value: int64(1710000000)})
+			assert.Contains(t, conditions, clause.Gte{Column: "created_at", Value: int64(1700000001)})
+			assert.Contains(t, conditions, clause.Lte{Column: "created_at", Value: int64(1710000001)})
+			assert.Contains(t, conditions, clause.Gte{Column: "amount", Value: -100.0})
+			assert.Contains(t, conditions, clause.Lte{Column: "amount", Value: 50.0})
+			assert.Contains(t, conditions, clause.Neq{Column: "balance", Value: 0})
+			assert.Equal(t, []clause.OrderByColumn{
+				{Column: clause.Column{Name: "amount"}},
+				{Column: clause.Column{Name: "id"}},
+			}, req.GetOrderBy().Columns)
+			return nil
+		})
+
+	_, _, err := td.core.List(ctx, request)
+	assert.NoError(t, err)
+}
+
+func TestCore_List_Defaults_To_Newest_First_By_Cursor(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+			assert.Empty(t, req.GetConditions())
+			assert.False(t, req.IsAscending())
+			assert.Equal(t, "cursor", req.GetCursor())
+			return &db2.Page{NextCursor: "next", PrevCursor: "prev"}, nil
+		})
+
+	_, page, err := td.core.List(context.Background(), &dto.ListTransactionRequest{Cursor: "cursor"})
+	assert.NoError(t, err)
+	assert.Equal(t, "next", page.NextCursor)
+	assert.Equal(t, "prev", page.PrevCursor)
+}
+
+func TestCore_List_Uses_Offset_When_Not_Sorted_By_Creation(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	td.mockRepo.EXPECT().FindManyWithFilters(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, models interface{}, req db2.FindManyWithFiltersRequester) error {
+			assert.Equal(t, uint32(5), req.GetOffset())
+			assert.Equal(t, []clause.OrderByColumn{
+				{Column: clause.Column{Name: "created_at"}, Desc: true},
+				{Column: clause.Column{Name: "id"}, Desc: true},
+			}, req.GetOrderBy().Columns)
+			return nil
+		})
+
+	_, page, err := td.core.List(context.Background(), &dto.ListTransactionRequest{Offset: 5})
+	assert.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestCore_CreateBatch_Atomic_Inserts_Debits_Together(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	models := []*transaction.Transaction{
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().CreateInBatches(gomock.Any(), models, 2).Return(nil)
+	expectEvents(td)
+	expectEvents(td)
+
+	errs := td.core.CreateBatch(ctx, models, true)
+	assert.Equal(t, []error{nil, nil}, errs)
+}
+
+func TestCore_CreateBatch_Atomic_Aborts_On_Failure(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	models := []*transaction.Transaction{
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+		{AccountId: "some_id", OperationType: dto.OperationTypeCreditVoucher, Amount: 10, Currency: "USD"},
+	}
+	failure := errors.New("db error")
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().CreateInBatches(gomock.Any(), gomock.Any(), 1).Return(failure)
+
+	errs := td.core.CreateBatch(ctx, models, true)
+	assert.Equal(t, failure, errs[0])
+	assert.ErrorIs(t, errs[1], transaction.ErrBatchAborted)
+}
+
+func TestCore_CreateBatch_Atomic_Rejects_Invalid_Debits(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	models := []*transaction.Transaction{
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+		{Model: db2.Model{ID: "not-an-id"}, AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+	}
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	)
+	expectAccount(td, "USD")
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().CreateInBatches(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	errs := td.core.CreateBatch(ctx, models, true)
+	assert.ErrorIs(t, errs[0], transaction.ErrBatchAborted)
+	assert.ErrorContains(t, errs[1], "id: not a valid input")
+}
+
+func TestCore_CreateBatch_BestEffort_Creates_Each_Transaction(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	models := []*transaction.Transaction{
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+		{AccountId: "some_id", OperationType: dto.OperationTypeWithdraw, Currency: "USD"},
+	}
+	failure := errors.New("db error")
+
+	td.mockRepo.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fc func(ctx context.Context) error) error {
+			return fc(ctx)
+		},
+	).Times(2)
+	expectAccount(td, "USD")
+	expectAccount(td, "USD")
+	td.mockRepo.EXPECT().Create(gomock.Any(), models[0]).Return(failure)
+	td.mockRepo.EXPECT().Create(gomock.Any(), models[1]).Return(nil)
+	expectEvents(td)
+
+	errs := td.core.CreateBatch(ctx, models, false)
+	assert.Equal(t, []error{failure, nil}, errs)
+}
+
+func TestCore_Export_Writes_Balances_And_Running_Balance(t *testing.T) {
+	td := setupTest(t)
+	defer teardownTest(td)
+
+	ctx := context.Background()
+	request := &dto.ExportTransactionRequest{AccountId: "0b0e0000000000", From: 1709251200, To: 1711929599}
+	before := transaction.Transaction{Model: db2.Model{ID: "t1"}, OperationType: dto.OperationTypeCreditVoucher, EventDate: 1709000000, SettledAmount: 25, Amount: 25, Currency: "USD"}
+	inPeriod := []transaction.Transaction{
+		{Model: db2.Model{ID: "t2"}, OperationType: dto.OperationTypeNormalPurchase, EventDate: 1709374500, SettledAmount: -100, Amount: -100, Currency: "USD"},
+		{Model: db2.Model{ID: "t3"}, OperationType: dto.OperationTypeCreditVoucher, EventDate: 1710000000, SettledAmount: 54.5, Amount: 50, Currency: "EUR", FxRate: 1.09},
+	}
+
+	td.mockRepo.EXPECT().FindByID(gomock.Any(), gomock.AssignableToTypeOf(&account.Account{}), "0b0e0000000000").DoAndReturn(
+		func(ctx context.Context, receiver db2.IModel, id string) error {
+			receiver.(*account.Account).ID = id
+			receiver.(*account.Account).Currency = "USD"
+			return nil
+		},
+	)
+	bound := clause.Or(
+		clause.Lt{Column: "created_at", Value: int64(1710000000)},
+		clause.And(clause.Eq{Column: "created_at", Value: int64(1710000000)}, clause.Lte{Column: "id", Value: "t3"}),
+	)
+	gomock.InOrder(
+		td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+				assert.False(t, req.IsAscending())
+				assert.Equal(t, uint32(1), req.GetLimit())
+				*models.(*[]transaction.Transaction) = []transaction.Transaction{{Model: db2.Model{ID: "t3", CreatedAt: 1710000000}}}
+				return &db2.Page{NextCursor: "older"}, nil
+			}),
+		td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+				assert.True(t, req.IsAscending())
+				assert.Empty(t, req.GetCursor())
+				assert.Contains(t, req.GetConditions(), bound)
+				assert.NotContains(t, req.GetConditions(), clause.Gte{Column: "event_date", Value: int64(1709251200)})
+				*models.(*[]transaction.Transaction) = []transaction.Transaction{before, inPeriod[0]}
+				return &db2.Page{NextCursor: "second"}, nil
+			}),
+		td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+				assert.Equal(t, "second", req.GetCursor())
+				*models.(*[]transaction.Transaction) = inPeriod[1:]
+				return &db2.Page{PrevCursor: "first"}, nil
+			}),
+		td.mockRepo.EXPECT().FindManyWithCursor(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error) {
+				assert.Contains(t, req.GetConditions(), bound)
+				assert.Contains(t, req.GetConditions(), clause.Gte{Column: "event_date", Value: int64(1709251200)})
+				*models.(*[]transaction.Transaction) = inPeriod
+				return &db2.Page{}, nil
+			}),
+	)
+
+	buf := new(bytes.Buffer)
+	writer, err := statement.NewWriter(buf, statement.FormatCSV)
+	assert.NoError(t, err)
+	err = td.core.Export(ctx, request, writer)
+	assert.NoError(t, err)
+	assert.Equal(t, "event_date,transaction_id,operation_type,amount,currency,original_amount,original_currency,balance\n"+
+		"2024-03-02T10:15:00Z,t2,Normal_Purchase,-100.00,USD,-100.00,USD,-75.00\n"+
+		"2024-03-09T16:00:00Z,t3,Credit_Voucher,54.50,USD,50.00,EUR,-20.50\n", buf.String())
+}
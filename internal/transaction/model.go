@@ -4,16 +4,23 @@ import (
 	"time"
 	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/outbox"
 )
 
 // Transaction represents a financial transaction entity.
 type Transaction struct {
-	db.Model                        // Embedding the common database model
-	AccountId     string            `json:"account_id"`     // ID of the account associated with the transaction
-	OperationType dto.OperationType `json:"operation_type"` // Type of operation (e.g., purchase, withdrawal)
-	Amount        float64           `json:"amount"`         // Amount of the transaction
-	Balance       float64           `json:"balance"`        // balance of the transaction
-	EventDate     int64             `json:"event_date"`     // Date and time when the transaction occurred (Unix timestamp)
+	db.Model                                   // Embedding the common database model
+	AccountId       string                     `json:"account_id"`       // ID of the account associated with the transaction
+	OperationType   dto.OperationType          `json:"operation_type"`   // Type of operation (e.g., purchase, withdrawal)
+	Amount          float64                    `json:"amount"`           // Amount of the transaction in Currency
+	Balance         float64                    `json:"balance"`          // balance of the transaction in Currency
+	EventDate       int64                      `json:"event_date"`       // Date and time when the transaction occurred (Unix timestamp)
+	Currency        string                     `json:"currency"`         // ISO 4217 currency the transaction was posted in
+	SettledAmount   float64                    `json:"settled_amount"`   // Amount converted into the account currency at posting time
+	SettledCurrency string                     `json:"settled_currency"` // ISO 4217 currency of the account the transaction settled into
+	FxRate          float64                    `json:"fx_rate"`          // Rate applied to convert Amount into SettledAmount
+	ConvertCurrency bool                       `json:"-" gorm:"-"`       // Allows discharging balances held in other currencies
+	Discharges      []outbox.BalanceDischarged `json:"-" gorm:"-"`       // Debits discharged by the transaction while creating it
 }
 
 // TableName returns the name of the database table for the Transaction entity.
@@ -34,12 +41,16 @@ func (e *Transaction) SetDefaults() error {
 // ToDto converts the Transaction entity to its DTO (data transfer object) representation.
 func (e *Transaction) ToDto() *dto.Transaction {
 	return &dto.Transaction{
-		ID:            e.ID,
-		AccountID:     e.AccountId,
-		OperationType: e.OperationType.String(),
-		Amount:        e.Amount,
-		Balance:       e.Balance,
-		EventDate:     time.Unix(e.EventDate, 0).String(),
+		ID:              e.ID,
+		AccountID:       e.AccountId,
+		OperationType:   e.OperationType.String(),
+		Amount:          e.Amount,
+		Currency:        e.Currency,
+		SettledAmount:   e.SettledAmount,
+		SettledCurrency: e.SettledCurrency,
+		FxRate:          e.FxRate,
+		Balance:         e.Balance,
+		EventDate:       time.Unix(e.EventDate, 0).String(),
 	}
 }
 
@@ -50,6 +61,8 @@ func (e *Transaction) ApplyDto(val *dto.Transaction) {
 	e.Amount = setAmountSign(e.OperationType, val.Amount)
 	e.Balance = setAmountSign(e.OperationType, val.Amount)
 	e.EventDate = time.Now().Unix()
+	e.Currency = val.Currency
+	e.ConvertCurrency = val.ConvertCurrency
 }
 
 // OperationFromString converts a string representation of an operation type to its corresponding OperationType.
@@ -90,4 +103,22 @@ type IListRequest interface {
 	GetAccountId() string
 	GetOperationType() string
 	GetOperationTypes() []string
+	GetCurrency() string
+	GetEventDateFrom() int64
+	GetEventDateTo() int64
+	GetCreatedAtFrom() int64
+	GetCreatedAtTo() int64
+	GetMinAmount() *float64
+	GetMaxAmount() *float64
+	GetOnlyOpenBalance() bool
+	GetSortBy() string
+	GetSortOrder() string
+	GetCursor() string
+}
+
+// IExportRequest is the request of a statement export.
+type IExportRequest interface {
+	GetAccountId() string
+	GetFrom() int64
+	GetTo() int64
 }
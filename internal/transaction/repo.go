@@ -8,7 +8,9 @@ import (
 type IRepo interface {
 	FindByID(ctx context.Context, receiver db2.IModel, id string) error
 	Create(ctx context.Context, receiver db2.IModel) error
+	CreateInBatches(ctx context.Context, receivers interface{}, batchSize int) error
 	Update(ctx context.Context, receiver db2.IModel, selectiveList ...string) error
 	FindManyWithFilters(ctx context.Context, models interface{}, req db2.FindManyWithFiltersRequester) error
+	FindManyWithCursor(ctx context.Context, models interface{}, req db2.FindManyWithCursorRequester) (*db2.Page, error)
 	Transaction(ctx context.Context, fc func(ctx context.Context) error) error
 }
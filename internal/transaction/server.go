@@ -1,46 +1,189 @@
 package transaction
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils"
+	"io"
+	"math"
+	"sync/atomic"
 	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/statement"
 	"transaction-server/internal/validator"
 )
 
 type IServer interface {
 	Create(ctx *gin.Context, req *dto.CreateTransactionRequest) *dto.CreateTransactionResponse
+	BatchCreate(ctx *gin.Context, req *dto.BatchCreateTransactionRequest) *dto.BatchCreateTransactionResponse
+	Export(ctx *gin.Context, req *dto.ExportTransactionRequest, w io.Writer) *dto.ExportTransactionResponse
 	Get(ctx *gin.Context, id string) *dto.GetTransactionResponse
 	List(ctx *gin.Context, req *dto.ListTransactionRequest) *dto.ListTransactionResponse
 }
 
 type Server struct {
-	core ICore
+	core   ICore
+	policy policy.IPolicy
+	config atomic.Pointer[Config]
 }
 
-func NewServer(core ICore) IServer {
-	return &Server{core: core}
+// NewServer returns the server as a *Server for its configuration to be replaced with
+// SetConfig while it serves.
+func NewServer(core ICore, policy policy.IPolicy, config Config) *Server {
+	server := &Server{core: core, policy: policy}
+	server.SetConfig(config)
+	return server
+}
+
+// SetConfig replaces the configuration of the server for the requests served from then on.
+func (s *Server) SetConfig(config Config) {
+	s.config.Store(&config)
 }
 
 func (s *Server) Create(ctx *gin.Context, req *dto.CreateTransactionRequest) *dto.CreateTransactionResponse {
 	if err := validator.NewValidTransaction(req, validator.CreateTransactionValidator); err != nil {
 		return &dto.CreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
 	}
+	if err := s.checkTenantLimits(ctx, req.Transaction); err != nil {
+		return &dto.CreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if err := s.policy.AuthorizeAccount(ctx, policy.PermissionTransactionsWrite, req.Transaction.AccountID); err != nil {
+		return &dto.CreateTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	transaction := new(Transaction)
 	transaction.ApplyDto(req.Transaction)
 	if err := s.core.Create(ctx, transaction); err != nil {
-		return &dto.CreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBPersistError, err.Error())}
+		return &dto.CreateTransactionResponse{Base: dto.GetErrorResponse(createErrorCode(err), err.Error())}
 	}
 	return &dto.CreateTransactionResponse{Transaction: transaction.ToDto(), Base: &dto.Base{Success: true}}
 }
 
+func (s *Server) BatchCreate(ctx *gin.Context, req *dto.BatchCreateTransactionRequest) *dto.BatchCreateTransactionResponse {
+	if err := validator.NewValidTransaction(req, validator.BatchTransactionValidator); err != nil {
+		return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if batchMaxSize := s.tenantConfig(ctx).BatchMaxSize; len(req.Transactions) > batchMaxSize {
+		message := fmt.Sprintf("transactions: the length must be no more than %d.", batchMaxSize)
+		return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, message)}
+	}
+	if err := s.policy.Authorize(ctx, policy.PermissionTransactionsWrite); err != nil {
+		return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	atomic := req.Mode != dto.BatchModeBestEffort
+
+	results := make([]*dto.BatchTransactionResult, len(req.Transactions))
+	transactions := make([]*Transaction, 0, len(req.Transactions))
+	indexes := make([]int, 0, len(req.Transactions))
+	for i, item := range req.Transactions {
+		err := validator.NewValidTransaction(&dto.CreateTransactionRequest{Transaction: item}, validator.CreateTransactionValidator)
+		if err == nil {
+			err = s.checkTenantLimits(ctx, item)
+		}
+		if err != nil {
+			if atomic {
+				message := fmt.Sprintf("transactions[%d]: %s", i, err.Error())
+				return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, message)}
+			}
+			results[i] = &dto.BatchTransactionResult{Index: i, Error: &dto.ErrorResponse{Code: common.ErrValidationFailed, Message: err.Error()}}
+			continue
+		}
+		if err := s.policy.AuthorizeAccount(ctx, policy.PermissionTransactionsWrite, item.AccountID); err != nil {
+			if atomic {
+				message := fmt.Sprintf("transactions[%d]: %s", i, err.Error())
+				return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), message)}
+			}
+			results[i] = &dto.BatchTransactionResult{Index: i, Error: &dto.ErrorResponse{Code: policy.ErrorCode(err), Message: err.Error()}}
+			continue
+		}
+		transaction := new(Transaction)
+		transaction.ApplyDto(item)
+		transactions = append(transactions, transaction)
+		indexes = append(indexes, i)
+	}
+
+	errs := s.core.CreateBatch(ctx, transactions, atomic)
+	for j, err := range errs {
+		i := indexes[j]
+		if err != nil {
+			if atomic && !errors.Is(err, ErrBatchAborted) {
+				message := fmt.Sprintf("transactions[%d]: %s", i, err.Error())
+				return &dto.BatchCreateTransactionResponse{Base: dto.GetErrorResponse(createErrorCode(err), message)}
+			}
+			results[i] = &dto.BatchTransactionResult{Index: i, Error: &dto.ErrorResponse{Code: createErrorCode(err), Message: err.Error()}}
+			continue
+		}
+		results[i] = &dto.BatchTransactionResult{Index: i, Success: true, Transaction: transactions[j].ToDto()}
+	}
+	return &dto.BatchCreateTransactionResponse{Results: results, Base: &dto.Base{Success: true}}
+}
+
+// Export writes the statement of the account to w. Nothing is written to w when the
+// request fails validation or the account cannot be loaded.
+func (s *Server) Export(ctx *gin.Context, req *dto.ExportTransactionRequest, w io.Writer) *dto.ExportTransactionResponse {
+	if err := validator.NewValidTransaction(req, validator.ExportTransactionValidator); err != nil {
+		return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if err := s.policy.AuthorizeAccount(ctx, policy.PermissionTransactionsRead, req.AccountId); err != nil {
+		return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	writer, err := statement.NewWriter(w, req.Format)
+	if err != nil {
+		return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	if err := s.core.Export(ctx, req, writer); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(common.ErrNotFoundFailed, err.Error())}
+		}
+		return &dto.ExportTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	return &dto.ExportTransactionResponse{Base: &dto.Base{Success: true}}
+}
+
+// tenantConfig returns the configuration of the tenant of the request.
+func (s *Server) tenantConfig(ctx *gin.Context) TenantConfig {
+	tenantID, _ := db.TenantFromContext(ctx)
+	return s.config.Load().ForTenant(tenantID)
+}
+
+// checkTenantLimits checks a valid transaction against the configuration of the tenant.
+func (s *Server) checkTenantLimits(ctx *gin.Context, transaction *dto.Transaction) error {
+	tenantConfig := s.tenantConfig(ctx)
+	if len(tenantConfig.OperationTypes) > 0 && !utils.Contains(tenantConfig.OperationTypes, transaction.OperationType) {
+		return fmt.Errorf("operation_type: %s is not enabled for the tenant.", transaction.OperationType)
+	}
+	if tenantConfig.MaxAmount > 0 && math.Abs(transaction.Amount) > tenantConfig.MaxAmount {
+		return fmt.Errorf("amount: must be no more than %v.", tenantConfig.MaxAmount)
+	}
+	return nil
+}
+
+// createErrorCode returns the error code of a failure to create a transaction.
+func createErrorCode(err error) string {
+	if errors.Is(err, fxrate.ErrRateNotFound) {
+		return common.ErrValidationFailed
+	}
+	return common.ErrDBPersistError
+}
+
 func (s *Server) Get(ctx *gin.Context, id string) *dto.GetTransactionResponse {
 	if err := validator.NewValidTransaction(id, validator.GetTransactionValidator); err != nil {
 		return &dto.GetTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
 	}
+	if err := s.policy.Authorize(ctx, policy.PermissionTransactionsRead); err != nil {
+		return &dto.GetTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	transaction := new(Transaction)
 	if err := s.core.Get(ctx, transaction, id); err != nil {
 		return &dto.GetTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
 	}
+	if err := s.policy.AuthorizeAccount(ctx, policy.PermissionTransactionsRead, transaction.AccountId); err != nil {
+		return &dto.GetTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
 	return &dto.GetTransactionResponse{Transaction: transaction.ToDto(), Base: &dto.Base{Success: true}}
 }
 
@@ -48,7 +191,10 @@ func (s *Server) List(ctx *gin.Context, req *dto.ListTransactionRequest) *dto.Li
 	if err := validator.NewValidTransaction(req, validator.ListTransactionValidator); err != nil {
 		return &dto.ListTransactionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
 	}
-	transactions, err := s.core.List(ctx, req)
+	if err := s.policy.AuthorizeAccount(ctx, policy.PermissionTransactionsRead, req.AccountId); err != nil {
+		return &dto.ListTransactionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	transactions, page, err := s.core.List(ctx, req)
 	if err != nil {
 		return &dto.ListTransactionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
 	}
@@ -56,5 +202,10 @@ func (s *Server) List(ctx *gin.Context, req *dto.ListTransactionRequest) *dto.Li
 	for _, transaction := range *transactions {
 		transactionsDto = append(transactionsDto, transaction.ToDto())
 	}
-	return &dto.ListTransactionResponse{Transactions: transactionsDto, Base: &dto.Base{Success: true}}
+	return &dto.ListTransactionResponse{
+		Transactions: transactionsDto,
+		NextCursor:   page.NextCursor,
+		PrevCursor:   page.PrevCursor,
+		Base:         &dto.Base{Success: true},
+	}
 }
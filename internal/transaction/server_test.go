@@ -1,31 +1,45 @@
 package transaction_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/golang/mock/gomock"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"transaction-server/internal/transaction/mock"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/common"
+	"transaction-server/internal/common/db"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/fxrate"
+	"transaction-server/internal/policy"
+	policymock "transaction-server/internal/policy/mock"
 	"transaction-server/internal/transaction"
 )
 
 type ServerTest struct {
 	mockCoreCtrl *gomock.Controller
 	core         *mock.MockICore
+	policyRepo   *policymock.MockIRepo
 	server       transaction.IServer
 }
 
 func setupServerTest(t *testing.T) *ServerTest {
 	mockCoreCtrl := gomock.NewController(t)
 	mockCore := mock.NewMockICore(mockCoreCtrl)
-	server := transaction.NewServer(mockCore)
+	policyRepo := policymock.NewMockIRepo(mockCoreCtrl)
+	server := transaction.NewServer(mockCore, policy.NewPolicy(policyRepo, policy.Config{}), transaction.Config{BatchMaxSize: 3})
 	return &ServerTest{
 		mockCoreCtrl: mockCoreCtrl,
 		core:         mockCore,
+		policyRepo:   policyRepo,
 		server:       server,
 	}
 }
@@ -38,7 +52,7 @@ func TestServer_Create_Success(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateTransactionRequest{
 		Transaction: &dto.Transaction{
 			OperationType: "Normal_Purchase",
@@ -57,7 +71,7 @@ func TestServer_Create_ValidationFailed(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateTransactionRequest{
 		Transaction: &dto.Transaction{},
 	}
@@ -71,7 +85,7 @@ func TestServer_Create_DBPersistError(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.CreateTransactionRequest{
 		Transaction: &dto.Transaction{
 			OperationType: "Normal_Purchase",
@@ -91,7 +105,7 @@ func TestServer_Get_Success(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "0b0e0000000000"
 
 	td.core.EXPECT().Get(ctx, gomock.Any(), id).Return(nil)
@@ -104,7 +118,7 @@ func TestServer_Get_ValidationFailed(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "some_invalid_id"
 
 	resp := td.server.Get(ctx, id)
@@ -116,7 +130,7 @@ func TestServer_Get_DBQueryError(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	id := "0b0e0000000000"
 
 	td.core.EXPECT().Get(ctx, gomock.Any(), id).Return(errors.New("DB error"))
@@ -130,11 +144,11 @@ func TestServer_List_Success(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.ListTransactionRequest{}
 
 	transactions := make([]transaction.Transaction, 0)
-	td.core.EXPECT().List(ctx, req).Return(&transactions, nil)
+	td.core.EXPECT().List(ctx, req).Return(&transactions, &db.Page{}, nil)
 
 	resp := td.server.List(ctx, req)
 	assert.True(t, resp.Success)
@@ -144,7 +158,7 @@ func TestServer_List_ValidationFailed(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.ListTransactionRequest{
 		Limit: 40,
 	}
@@ -158,12 +172,348 @@ func TestServer_List_DBQueryError(t *testing.T) {
 	td := setupServerTest(t)
 	defer teardownServerTest(td)
 
-	ctx := &gin.Context{}
+	ctx := asClient(policy.RoleAdmin)
 	req := &dto.ListTransactionRequest{}
 
-	td.core.EXPECT().List(ctx, req).Return(nil, errors.New("DB error"))
+	td.core.EXPECT().List(ctx, req).Return(nil, nil, errors.New("DB error"))
 
 	resp := td.server.List(ctx, req)
 	assert.False(t, resp.Success)
 	assert.Equal(t, common.ErrDBQueryError, resp.Error.Code)
 }
+
+func TestServer_Create_FxRateNotFound(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.CreateTransactionRequest{
+		Transaction: &dto.Transaction{
+			OperationType: "Normal_Purchase",
+			AccountID:     "0b0e0000000000",
+			Amount:        100,
+			Currency:      "EUR",
+		},
+	}
+
+	td.core.EXPECT().Create(ctx, gomock.Any()).Return(fmt.Errorf("%w: EUR/USD", fxrate.ErrRateNotFound))
+
+	resp := td.server.Create(ctx, req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_Create_InvalidCurrency(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.CreateTransactionRequest{
+		Transaction: &dto.Transaction{
+			OperationType: "Normal_Purchase",
+			AccountID:     "0b0e0000000000",
+			Amount:        100,
+			Currency:      "XYZ",
+		},
+	}
+
+	resp := td.server.Create(ctx, req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_List_InvalidFilters(t *testing.T) {
+	minAmount, maxAmount := 10.0, 5.0
+	requests := map[string]*dto.ListTransactionRequest{
+		"unknown sort field":   {SortBy: "account_id"},
+		"unknown sort order":   {SortOrder: "up"},
+		"inverted event dates": {EventDateFrom: 1710000000, EventDateTo: 1700000000},
+		"inverted created at":  {CreatedAtFrom: 1710000000, CreatedAtTo: 1700000000},
+		"inverted amounts":     {MinAmount: &minAmount, MaxAmount: &maxAmount},
+		"invalid timestamp":    {EventDateFrom: 17},
+		"invalid cursor":       {Cursor: "not-a-cursor"},
+		"cursor with offset":   {Cursor: db.Cursor{ID: "0b0e0000000000"}.Encode(), Offset: 10},
+		"cursor with sort":     {Cursor: db.Cursor{ID: "0b0e0000000000"}.Encode(), SortBy: "amount"},
+	}
+	for name, req := range requests {
+		t.Run(name, func(t *testing.T) {
+			td := setupServerTest(t)
+			defer teardownServerTest(td)
+
+			resp := td.server.List(asClient(policy.RoleAdmin), req)
+			assert.False(t, resp.Success)
+			assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+		})
+	}
+}
+
+func TestServer_List_ValidFilters(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	minAmount, maxAmount := -10.0, 5.0
+	req := &dto.ListTransactionRequest{
+		EventDateFrom:   1700000000,
+		EventDateTo:     1710000000,
+		MinAmount:       &minAmount,
+		MaxAmount:       &maxAmount,
+		OnlyOpenBalance: true,
+		SortBy:          "event_date",
+		SortOrder:       dto.SortOrderAsc,
+	}
+
+	transactions := make([]transaction.Transaction, 0)
+	td.core.EXPECT().List(ctx, req).Return(&transactions, &db.Page{}, nil)
+
+	resp := td.server.List(ctx, req)
+	assert.True(t, resp.Success)
+}
+
+func TestServer_List_Returns_Cursors(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.ListTransactionRequest{Cursor: db.Cursor{CreatedAt: 1700000000, ID: "0b0e0000000000"}.Encode()}
+
+	transactions := make([]transaction.Transaction, 0)
+	td.core.EXPECT().List(ctx, req).Return(&transactions, &db.Page{NextCursor: "next", PrevCursor: "prev"}, nil)
+
+	resp := td.server.List(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Equal(t, "prev", resp.PrevCursor)
+}
+
+func TestServer_BatchCreate_TooLarge(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	item := &dto.Transaction{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100}
+	req := &dto.BatchCreateTransactionRequest{
+		Mode:         dto.BatchModeAtomic,
+		Transactions: []*dto.Transaction{item, item, item, item},
+	}
+
+	resp := td.server.BatchCreate(asClient(policy.RoleAdmin), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_BatchCreate_InvalidMode(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	req := &dto.BatchCreateTransactionRequest{
+		Mode:         "partial",
+		Transactions: []*dto.Transaction{{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100}},
+	}
+
+	resp := td.server.BatchCreate(asClient(policy.RoleAdmin), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_BatchCreate_Atomic_InvalidItem(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	req := &dto.BatchCreateTransactionRequest{
+		Mode: dto.BatchModeAtomic,
+		Transactions: []*dto.Transaction{
+			{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100},
+			{OperationType: "Normal_Purchase", Amount: 100},
+		},
+	}
+
+	resp := td.server.BatchCreate(asClient(policy.RoleAdmin), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "transactions[1]")
+}
+
+func TestServer_BatchCreate_BestEffort_Reports_Each_Item(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.BatchCreateTransactionRequest{
+		Mode: dto.BatchModeBestEffort,
+		Transactions: []*dto.Transaction{
+			{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100},
+			{OperationType: "Normal_Purchase", Amount: 100},
+			{OperationType: "Credit_Voucher", AccountID: "0b0e0000000000", Amount: 50},
+		},
+	}
+
+	td.core.EXPECT().CreateBatch(ctx, gomock.Len(2), false).Return([]error{nil, errors.New("db error")})
+
+	resp := td.server.BatchCreate(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.Results, 3)
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Results[1].Error.Code)
+	assert.Equal(t, 2, resp.Results[2].Index)
+	assert.Equal(t, common.ErrDBPersistError, resp.Results[2].Error.Code)
+}
+
+func TestServer_Export_ValidationFailed_Writes_Nothing(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	buf := new(bytes.Buffer)
+	req := &dto.ExportTransactionRequest{AccountId: "0b0e0000000000", Format: "pdf"}
+
+	resp := td.server.Export(asClient(policy.RoleAdmin), req, buf)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+	assert.Zero(t, buf.Len())
+}
+
+func TestServer_Export_AccountNotFound(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.ExportTransactionRequest{AccountId: "0b0e0000000000", Format: "ofx", From: 1709251200, To: 1711929599}
+	td.core.EXPECT().Export(ctx, req, gomock.Any()).Return(gorm.ErrRecordNotFound)
+
+	resp := td.server.Export(ctx, req, new(bytes.Buffer))
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrNotFoundFailed, resp.Error.Code)
+}
+
+func TestServer_Export_Success(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.ExportTransactionRequest{AccountId: "0b0e0000000000", Format: "camt053"}
+	td.core.EXPECT().Export(ctx, req, gomock.Any()).Return(nil)
+
+	resp := td.server.Export(ctx, req, new(bytes.Buffer))
+	assert.True(t, resp.Success)
+}
+
+// asClient returns a context authenticated as a client with the role.
+func asClient(role string) *gin.Context {
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: role})
+	return ctx
+}
+
+// expectOwner expects the policy to look up the owner of the account.
+func expectOwner(td *ServerTest, accountID string, ownerID string) {
+	td.policyRepo.EXPECT().FindByID(gomock.Any(), gomock.Any(), accountID).DoAndReturn(
+		func(ctx context.Context, receiver db.IModel, id string) error {
+			reflect.ValueOf(receiver).Elem().FieldByName("OwnerID").SetString(ownerID)
+			return nil
+		})
+}
+
+func TestServer_Create_Forbidden(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	req := &dto.CreateTransactionRequest{
+		Transaction: &dto.Transaction{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100},
+	}
+	resp := td.server.Create(asClient(policy.RoleAgent), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+}
+
+func TestServer_Create_Partner_Owned_Account(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	req := &dto.CreateTransactionRequest{
+		Transaction: &dto.Transaction{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100},
+	}
+	expectOwner(td, "0b0e0000000000", "0c000000000001")
+	td.core.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	assert.True(t, td.server.Create(asClient(policy.RolePartner), req).Success)
+
+	expectOwner(td, "0b0e0000000000", "0c000000000002")
+	resp := td.server.Create(asClient(policy.RolePartner), req)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+}
+
+func TestServer_List_Partner_Requires_Owned_Account(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	resp := td.server.List(asClient(policy.RolePartner), &dto.ListTransactionRequest{Limit: 2})
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrForbidden, resp.Error.Code)
+}
+
+func TestServer_BatchCreate_BestEffort_Reports_Forbidden_Items(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	expectOwner(td, "0b0e0000000001", "0c000000000001")
+	expectOwner(td, "0b0e0000000002", "0c000000000002")
+	td.core.EXPECT().CreateBatch(gomock.Any(), gomock.Len(1), false).Return([]error{nil})
+
+	resp := td.server.BatchCreate(asClient(policy.RolePartner), &dto.BatchCreateTransactionRequest{
+		Mode: dto.BatchModeBestEffort,
+		Transactions: []*dto.Transaction{
+			{OperationType: "Normal_Purchase", AccountID: "0b0e0000000001", Amount: 100},
+			{OperationType: "Normal_Purchase", AccountID: "0b0e0000000002", Amount: 100},
+		},
+	})
+	assert.True(t, resp.Success)
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, common.ErrForbidden, resp.Results[1].Error.Code)
+}
+
+// asTenant returns the request context of an admin scoped to the tenant.
+func asTenant(tenantID string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.ContextWithFallback = true
+	ctx := gin.CreateTestContextOnly(httptest.NewRecorder(), engine)
+	ctx.Request = httptest.NewRequest("POST", "/transactions", nil)
+	ctx.Request = ctx.Request.WithContext(db.WithTenant(ctx.Request.Context(), tenantID))
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: policy.RoleAdmin})
+	return ctx
+}
+
+func TestServer_Create_Tenant_Limits(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+	td.server = transaction.NewServer(td.core, policy.NewPolicy(td.policyRepo, policy.Config{}), transaction.Config{
+		BatchMaxSize: 3,
+		Tenants: map[string]transaction.TenantConfig{
+			"acme": {OperationTypes: []string{"Normal_Purchase"}, MaxAmount: 500, BatchMaxSize: 1},
+		},
+	})
+
+	create := func(ctx *gin.Context, operationType string, amount float64) *dto.CreateTransactionResponse {
+		return td.server.Create(ctx, &dto.CreateTransactionRequest{
+			Transaction: &dto.Transaction{OperationType: operationType, AccountID: "0b0e0000000000", Amount: amount},
+		})
+	}
+	td.core.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	assert.True(t, create(asTenant("acme"), "Normal_Purchase", 500).Success)
+	assert.True(t, create(asTenant("globex"), "Withdraw", 1000).Success)
+
+	resp := create(asTenant("acme"), "Withdraw", 100)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+
+	resp = create(asTenant("acme"), "Normal_Purchase", 501)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+
+	item := &dto.Transaction{OperationType: "Normal_Purchase", AccountID: "0b0e0000000000", Amount: 100}
+	batchResp := td.server.BatchCreate(asTenant("acme"), &dto.BatchCreateTransactionRequest{
+		Mode:         dto.BatchModeAtomic,
+		Transactions: []*dto.Transaction{item, item},
+	})
+	assert.False(t, batchResp.Success)
+	assert.Equal(t, common.ErrValidationFailed, batchResp.Error.Code)
+}
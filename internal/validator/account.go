@@ -14,6 +14,7 @@ type (
 const (
 	CreateAccountValidator = "Create"
 	GetAccountValidator    = "Get"
+	ListAccountValidator   = "List"
 )
 
 // NewValidAccount validates Account APIs and return error or nil
@@ -24,6 +25,8 @@ func NewValidAccount(ev interface{}, validator AccountValidator) error {
 		ve = &ValidCreateAccount{ev.(*dto.CreateAccountRequest)}
 	case GetAccountValidator:
 		ve = &ValidGetAccount{ev.(string)}
+	case ListAccountValidator:
+		ve = &ValidListAccount{ev.(*dto.ListAccountRequest)}
 	}
 	err := ve.Validate()
 	if err != nil {
@@ -48,6 +51,10 @@ func (v *ValidCreateAccount) Validate() error {
 			&v.Account.DocumentNumber,
 			validation.Required,
 		),
+		validation.Field(
+			&v.Account.Currency,
+			validation.By(datatype.IsCurrencyCode),
+		),
 	)
 }
 
@@ -66,3 +73,18 @@ func (v *ValidGetAccount) Validate() error {
 		),
 	)
 }
+
+// ValidListAccount wraps List Account struct
+type ValidListAccount struct {
+	*dto.ListAccountRequest
+}
+
+func (v *ValidListAccount) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.Limit,
+			validation.Max(uint32(20)),
+		),
+	)
+}
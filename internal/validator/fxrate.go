@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"transaction-server/internal/common/db/datatype"
+	"transaction-server/internal/dto"
+)
+
+type (
+	FxRateValidator string
+)
+
+const (
+	CreateFxRateValidator = "Create"
+	ListFxRateValidator   = "List"
+)
+
+// NewValidFxRate validates FxRate APIs and return error or nil
+func NewValidFxRate(ev interface{}, validator FxRateValidator) error {
+	var ve validation.Validatable
+	switch validator {
+	case CreateFxRateValidator:
+		ve = &ValidCreateFxRate{ev.(*dto.CreateFxRateRequest)}
+	case ListFxRateValidator:
+		ve = &ValidListFxRate{ev.(*dto.ListFxRateRequest)}
+	}
+	err := ve.Validate()
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+// ValidCreateFxRate wraps Create FxRate struct
+type ValidCreateFxRate struct {
+	*dto.CreateFxRateRequest
+}
+
+func (v *ValidCreateFxRate) Validate() error {
+	if v.FxRate == nil {
+		return errors.New("fx_rate: cannot be blank.")
+	}
+	return validation.ValidateStruct(
+		v.FxRate,
+		validation.Field(
+			&v.FxRate.BaseCurrency,
+			validation.Required,
+			validation.By(datatype.IsCurrencyCode),
+		),
+		validation.Field(
+			&v.FxRate.QuoteCurrency,
+			validation.Required,
+			validation.By(datatype.IsCurrencyCode),
+			validation.NotIn(v.FxRate.BaseCurrency).Error("must differ from base_currency"),
+		),
+		validation.Field(
+			&v.FxRate.Rate,
+			validation.Required,
+			validation.Min(float64(0)).Exclusive(),
+		),
+		validation.Field(
+			&v.FxRate.EffectiveFrom,
+			validation.By(datatype.IsTimestamp),
+		),
+	)
+}
+
+// ValidListFxRate wraps List FxRate struct
+type ValidListFxRate struct {
+	*dto.ListFxRateRequest
+}
+
+func (v *ValidListFxRate) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.Limit,
+			validation.Max(uint32(20)),
+		),
+		validation.Field(
+			&v.BaseCurrency,
+			validation.By(datatype.IsCurrencyCode),
+		),
+		validation.Field(
+			&v.QuoteCurrency,
+			validation.By(datatype.IsCurrencyCode),
+		),
+	)
+}
@@ -3,8 +3,10 @@ package validator
 import (
 	"errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"transaction-server/internal/common/db"
 	"transaction-server/internal/common/db/datatype"
 	"transaction-server/internal/dto"
+	"transaction-server/internal/statement"
 )
 
 type (
@@ -15,6 +17,8 @@ const (
 	CreateTransactionValidator = "Create"
 	GetTransactionValidator    = "Get"
 	ListTransactionValidator   = "List"
+	BatchTransactionValidator  = "Batch"
+	ExportTransactionValidator = "Export"
 )
 
 // NewValidTransaction validates Transaction APIs and return error or nil
@@ -27,6 +31,10 @@ func NewValidTransaction(ev interface{}, validator TransactionValidator) error {
 		ve = &ValidGetTransaction{ev.(string)}
 	case ListTransactionValidator:
 		ve = &ValidListTransaction{ev.(*dto.ListTransactionRequest)}
+	case BatchTransactionValidator:
+		ve = &ValidBatchTransaction{ev.(*dto.BatchCreateTransactionRequest)}
+	case ExportTransactionValidator:
+		ve = &ValidExportTransaction{ev.(*dto.ExportTransactionRequest)}
 	}
 	err := ve.Validate()
 	if err != nil {
@@ -41,6 +49,9 @@ type ValidCreateTransaction struct {
 }
 
 func (v *ValidCreateTransaction) Validate() error {
+	if v.Transaction == nil {
+		return errors.New("transaction: cannot be blank.")
+	}
 	return validation.ValidateStruct(
 		v.Transaction,
 		validation.Field(
@@ -62,6 +73,60 @@ func (v *ValidCreateTransaction) Validate() error {
 			&v.Transaction.Amount,
 			validation.Required,
 		),
+		validation.Field(
+			&v.Transaction.Currency,
+			validation.By(datatype.IsCurrencyCode),
+		),
+	)
+}
+
+// ValidBatchTransaction wraps Batch Create Transaction struct.
+// Items are validated one by one with ValidCreateTransaction.
+type ValidBatchTransaction struct {
+	*dto.BatchCreateTransactionRequest
+}
+
+func (v *ValidBatchTransaction) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.Mode,
+			validation.In(dto.BatchModeAtomic, dto.BatchModeBestEffort),
+		),
+		validation.Field(
+			&v.Transactions,
+			validation.Required,
+		),
+	)
+}
+
+// ValidExportTransaction wraps Export Transaction struct
+type ValidExportTransaction struct {
+	*dto.ExportTransactionRequest
+}
+
+func (v *ValidExportTransaction) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.AccountId,
+			validation.Required,
+			validation.By(datatype.IsUUID),
+		),
+		validation.Field(
+			&v.Format,
+			validation.Required,
+			validation.In(toInterfaces(statement.Formats)...),
+		),
+		validation.Field(
+			&v.From,
+			validation.Min(int64(0)),
+		),
+		validation.Field(
+			&v.To,
+			validation.Min(int64(0)),
+			validation.By(isNotBefore(v.From)),
+		),
 	)
 }
 
@@ -93,5 +158,82 @@ func (v *ValidListTransaction) Validate() error {
 			&v.Limit,
 			validation.Max(uint32(20)),
 		),
+		validation.Field(
+			&v.Currency,
+			validation.By(datatype.IsCurrencyCode),
+		),
+		validation.Field(
+			&v.EventDateFrom,
+			validation.By(datatype.IsTimestamp),
+		),
+		validation.Field(
+			&v.EventDateTo,
+			validation.By(datatype.IsTimestamp),
+			validation.By(isNotBefore(v.EventDateFrom)),
+		),
+		validation.Field(
+			&v.CreatedAtFrom,
+			validation.By(datatype.IsTimestamp),
+		),
+		validation.Field(
+			&v.CreatedAtTo,
+			validation.By(datatype.IsTimestamp),
+			validation.By(isNotBefore(v.CreatedAtFrom)),
+		),
+		validation.Field(
+			&v.MaxAmount,
+			validation.By(isNotBelowAmount(v.MinAmount)),
+		),
+		validation.Field(
+			&v.SortBy,
+			validation.In(toInterfaces(dto.TransactionSortFields)...),
+		),
+		validation.Field(
+			&v.SortOrder,
+			validation.In(dto.SortOrderAsc, dto.SortOrderDesc),
+		),
+		validation.Field(
+			&v.Cursor,
+			validation.By(isCursor),
+			validation.When(v.Cursor != "" && v.Offset != 0, validation.Empty.Error("cannot be combined with offset")),
+			validation.When(v.Cursor != "" && v.SortBy != "" && v.SortBy != "created_at", validation.Empty.Error("requires sorting by created_at")),
+		),
 	)
 }
+
+// isCursor checks that the value is a cursor issued by a previous list response.
+func isCursor(value interface{}) error {
+	_, err := db.DecodeCursor(value.(string))
+	return err
+}
+
+// isNotBefore checks that a timestamp is not before the given lower bound when both are set.
+func isNotBefore(from int64) validation.RuleFunc {
+	return func(value interface{}) error {
+		to := value.(int64)
+		if from != 0 && to != 0 && to < from {
+			return errors.New("must not be before the corresponding from value")
+		}
+		return nil
+	}
+}
+
+// isNotBelowAmount checks that an amount is not below the given lower bound when both are set.
+func isNotBelowAmount(min *float64) validation.RuleFunc {
+	return func(value interface{}) error {
+		max := value.(*float64)
+		if min != nil && max != nil && *max < *min {
+			return errors.New("must not be less than min_amount")
+		}
+		return nil
+	}
+}
+
+// toInterfaces converts strings into values accepted by validation.In.
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
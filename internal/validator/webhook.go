@@ -0,0 +1,169 @@
+package validator
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"net"
+	"net/url"
+	"strings"
+	"transaction-server/internal/common/db/datatype"
+	"transaction-server/internal/common/network"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/outbox"
+)
+
+type (
+	WebhookValidator string
+)
+
+const (
+	CreateWebhookSubscriptionValidator = "Create"
+	UpdateWebhookSubscriptionValidator = "Update"
+	GetWebhookValidator                = "Get"
+	ListWebhookSubscriptionValidator   = "List"
+	ListWebhookDeliveryValidator       = "ListDeliveries"
+)
+
+// webhookEventTypes are the event types a subscription can list.
+var webhookEventTypes = append([]interface{}{"*"}, toInterfaces(outbox.Types)...)
+
+// webhookDeliveryStatuses are the delivery states deliveries can be filtered by.
+var webhookDeliveryStatuses = []interface{}{"pending", "succeeded", "dead"}
+
+// NewValidWebhook validates Webhook APIs and return error or nil. Subscriptions must target
+// https URLs of public hosts, unless allowInsecureURLs, for development.
+func NewValidWebhook(ev interface{}, validator WebhookValidator, allowInsecureURLs bool) error {
+	var ve validation.Validatable
+	switch validator {
+	case CreateWebhookSubscriptionValidator:
+		ve = &ValidWebhookSubscription{ev.(*dto.CreateWebhookSubscriptionRequest).Subscription, allowInsecureURLs}
+	case UpdateWebhookSubscriptionValidator:
+		req := ev.(*dto.UpdateWebhookSubscriptionRequest)
+		if err := (&ValidGetWebhook{req.ID}).Validate(); err != nil {
+			return errors.New(err.Error())
+		}
+		ve = &ValidWebhookSubscription{req.Subscription, allowInsecureURLs}
+	case GetWebhookValidator:
+		ve = &ValidGetWebhook{ev.(string)}
+	case ListWebhookSubscriptionValidator:
+		ve = &ValidListWebhookSubscription{ev.(*dto.ListWebhookSubscriptionRequest)}
+	case ListWebhookDeliveryValidator:
+		ve = &ValidListWebhookDelivery{ev.(*dto.ListWebhookDeliveryRequest)}
+	}
+	err := ve.Validate()
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+// ValidWebhookSubscription wraps the subscription of Create and Update WebhookSubscription
+type ValidWebhookSubscription struct {
+	*dto.WebhookSubscription
+	// AllowInsecureURLs accepts http URLs and internal hosts.
+	AllowInsecureURLs bool
+}
+
+func (v *ValidWebhookSubscription) Validate() error {
+	if v.WebhookSubscription == nil {
+		return errors.New("subscription: cannot be blank.")
+	}
+	return validation.ValidateStruct(
+		v.WebhookSubscription,
+		validation.Field(
+			&v.URL,
+			validation.Required,
+			validation.By(isWebhookURL(v.AllowInsecureURLs)),
+		),
+		validation.Field(
+			&v.EventTypes,
+			validation.Required,
+			validation.Each(validation.In(webhookEventTypes...)),
+		),
+		validation.Field(
+			&v.Secret,
+			validation.Length(16, 128),
+		),
+		validation.Field(
+			&v.Description,
+			validation.Length(0, 255),
+		),
+	)
+}
+
+// ValidGetWebhook wraps the ID of a webhook subscription or delivery
+type ValidGetWebhook struct {
+	id string
+}
+
+func (v *ValidGetWebhook) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.id,
+			validation.Required,
+			validation.By(datatype.IsUUID),
+		),
+	)
+}
+
+// ValidListWebhookSubscription wraps List WebhookSubscription struct
+type ValidListWebhookSubscription struct {
+	*dto.ListWebhookSubscriptionRequest
+}
+
+func (v *ValidListWebhookSubscription) Validate() error {
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.Limit,
+			validation.Max(uint32(20)),
+		),
+	)
+}
+
+// ValidListWebhookDelivery wraps List WebhookDelivery struct
+type ValidListWebhookDelivery struct {
+	*dto.ListWebhookDeliveryRequest
+}
+
+func (v *ValidListWebhookDelivery) Validate() error {
+	if err := (&ValidGetWebhook{v.SubscriptionID}).Validate(); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(
+		v,
+		validation.Field(
+			&v.Limit,
+			validation.Max(uint32(20)),
+		),
+		validation.Field(
+			&v.Status,
+			validation.In(webhookDeliveryStatuses...),
+		),
+	)
+}
+
+// isWebhookURL accepts absolute https URLs of hosts which are not loopback, link-local,
+// private or multicast addresses, or any absolute http or https URL when allowInsecure.
+// The hostnames are checked again on the addresses they resolve to when delivering.
+func isWebhookURL(allowInsecure bool) validation.RuleFunc {
+	return func(value interface{}) error {
+		s, _ := value.(string)
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("must be an absolute http or https URL")
+		}
+		if allowInsecure {
+			return nil
+		}
+		if u.Scheme != "https" {
+			return errors.New("must be an https URL")
+		}
+		host := strings.ToLower(u.Hostname())
+		if ip := net.ParseIP(host); (ip != nil && !network.IsPublic(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return errors.New("must not target a loopback, link-local, private or multicast address")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/validator"
+)
+
+func createSubscription(url string) *dto.CreateWebhookSubscriptionRequest {
+	return &dto.CreateWebhookSubscriptionRequest{Subscription: &dto.WebhookSubscription{URL: url, EventTypes: []string{"*"}}}
+}
+
+func TestNewValidWebhook_Accepts_Public_Https_URLs(t *testing.T) {
+	for _, url := range []string{
+		"https://example.com/hooks",
+		"https://hooks.example.com:8443/v1?tenant=a",
+		"https://93.184.216.34/hooks",
+		"https://[2606:2800::1]/hooks",
+	} {
+		assert.NoError(t, validator.NewValidWebhook(createSubscription(url), validator.CreateWebhookSubscriptionValidator, false), url)
+	}
+}
+
+func TestNewValidWebhook_Rejects_Insecure_URLs(t *testing.T) {
+	for url, message := range map[string]string{
+		"http://example.com/hooks":         "must be an https URL",
+		"https://localhost/hooks":          "must not target",
+		"https://api.localhost/hooks":      "must not target",
+		"https://127.0.0.1/hooks":          "must not target",
+		"https://[::1]/hooks":              "must not target",
+		"https://169.254.169.254/latest":   "must not target",
+		"https://10.0.0.5/hooks":           "must not target",
+		"https://172.16.4.2/hooks":         "must not target",
+		"https://192.168.1.10:8080/hooks":  "must not target",
+		"https://[fd12::1]/hooks":          "must not target",
+		"https://224.0.0.1/hooks":          "must not target",
+		"https://0.0.0.0/hooks":            "must not target",
+		"https://[::ffff:127.0.0.1]/hooks": "must not target",
+		"ftp://example.com/hooks":          "must be an absolute http or https URL",
+		"/hooks":                           "must be an absolute http or https URL",
+	} {
+		err := validator.NewValidWebhook(createSubscription(url), validator.CreateWebhookSubscriptionValidator, false)
+		assert.ErrorContains(t, err, message, url)
+
+		update := &dto.UpdateWebhookSubscriptionRequest{ID: "abcdefghijklmn", Subscription: createSubscription(url).Subscription}
+		assert.ErrorContains(t, validator.NewValidWebhook(update, validator.UpdateWebhookSubscriptionValidator, false), message, url)
+	}
+}
+
+func TestNewValidWebhook_Allows_Insecure_URLs_For_Development(t *testing.T) {
+	for _, url := range []string{"http://localhost:8080/hooks", "http://10.0.0.5/hooks", "https://127.0.0.1/hooks"} {
+		assert.NoError(t, validator.NewValidWebhook(createSubscription(url), validator.CreateWebhookSubscriptionValidator, true), url)
+	}
+	assert.Error(t, validator.NewValidWebhook(createSubscription("ftp://localhost/hooks"), validator.CreateWebhookSubscriptionValidator, true))
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type Config struct {
+	// Enabled turns on queueing deliveries for outbox events and sending them.
+	Enabled bool
+	// MaxAttempts is the number of attempts after which a delivery is dead-lettered.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt; each later delay doubles.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+	// Timeout is the time allowed for an endpoint to respond.
+	Timeout time.Duration
+	// PollInterval is how often the dispatcher checks for due deliveries.
+	PollInterval time.Duration
+	// BatchSize is the number of due deliveries sent at a time.
+	BatchSize int
+	// AllowInsecureURLs lets subscriptions target http URLs and loopback, link-local, private
+	// and multicast addresses, which are otherwise refused, for development only.
+	AllowInsecureURLs bool
+}
+
+// Validate validates the deliveries get at least an attempt and the delays are not negative.
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.InitialBackoff, validation.Min(time.Duration(0))),
+		validation.Field(&c.MaxBackoff, validation.Min(c.InitialBackoff)),
+		validation.Field(&c.Timeout, validation.Min(time.Duration(0))),
+		validation.Field(&c.PollInterval, validation.Min(time.Duration(0))),
+		validation.Field(&c.BatchSize, validation.Min(0)),
+	)
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm/clause"
+	"time"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/outbox"
+)
+
+// ErrNotDead is returned when redelivering a delivery that is not dead-lettered.
+var ErrNotDead = errors.New("only dead deliveries can be redelivered")
+
+// subscriptionPageSize is the number of subscriptions read at a time when queueing deliveries.
+const subscriptionPageSize = 100
+
+type ICore interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	GetSubscription(ctx context.Context, subscription *Subscription, id string) error
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, subscription *Subscription) error
+	ListSubscriptions(ctx context.Context, request IListRequest) (*[]Subscription, error)
+	ListDeliveries(ctx context.Context, request IListDeliveryRequest) (*[]Delivery, error)
+	ListAttempts(ctx context.Context, deliveryID string) (*[]Attempt, error)
+	Redeliver(ctx context.Context, delivery *Delivery, id string) error
+	// Publish implements outbox.Publisher by queueing a delivery of the message to each
+	// active subscription of its type. Publishing a message again queues nothing new.
+	Publish(ctx context.Context, message *outbox.Message) error
+}
+
+type Core struct {
+	repo IRepo
+}
+
+func NewCore(repo IRepo) *Core {
+	return &Core{repo: repo}
+}
+
+func (c *Core) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	return c.repo.Create(ctx, subscription)
+}
+
+func (c *Core) GetSubscription(ctx context.Context, subscription *Subscription, id string) error {
+	return c.repo.FindByID(ctx, subscription, id)
+}
+
+func (c *Core) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	return c.repo.Update(ctx, subscription, "url", "event_types", "secret", "active", "description")
+}
+
+// DeleteSubscription deletes the subscription. Its pending deliveries are dead-lettered
+// by the dispatcher.
+func (c *Core) DeleteSubscription(ctx context.Context, subscription *Subscription) error {
+	return c.repo.Delete(ctx, subscription)
+}
+
+func (c *Core) ListSubscriptions(ctx context.Context, request IListRequest) (*[]Subscription, error) {
+	repoRequest := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{
+			Limit:  request.GetLimit(),
+			Offset: request.GetOffset(),
+		},
+	}
+	listResponse := make([]Subscription, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
+
+func (c *Core) ListDeliveries(ctx context.Context, request IListDeliveryRequest) (*[]Delivery, error) {
+	conditions := []clause.Expression{clause.Eq{Column: "subscription_id", Value: request.GetSubscriptionID()}}
+	if request.GetStatus() != "" {
+		conditions = append(conditions, clause.Eq{Column: "status", Value: request.GetStatus()})
+	}
+	repoRequest := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{
+			Limit:  request.GetLimit(),
+			Offset: request.GetOffset(),
+		},
+		Conditions: conditions,
+	}
+	listResponse := make([]Delivery, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
+
+func (c *Core) ListAttempts(ctx context.Context, deliveryID string) (*[]Attempt, error) {
+	repoRequest := &db.FindManyWithConditionsRequest{
+		// Attempts are bounded by Config.MaxAttempts, redeliveries aside.
+		FindManyRequest: db.FindManyRequest{Limit: 100},
+		Conditions:      []clause.Expression{clause.Eq{Column: "delivery_id", Value: deliveryID}},
+		OrderBy:         clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "number"}}}},
+	}
+	listResponse := make([]Attempt, 0)
+	if err := c.repo.FindManyWithFilters(ctx, &listResponse, repoRequest); err != nil {
+		return nil, err
+	}
+	return &listResponse, nil
+}
+
+// Redeliver queues a dead delivery again for immediate delivery, with a fresh set of attempts.
+func (c *Core) Redeliver(ctx context.Context, delivery *Delivery, id string) error {
+	if err := c.repo.FindByID(ctx, delivery, id); err != nil {
+		return err
+	}
+	if delivery.Status != StatusDead {
+		return ErrNotDead
+	}
+	delivery.Status = StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().Unix()
+	return c.repo.Update(ctx, delivery, "status", "attempts", "next_attempt_at")
+}
+
+func (c *Core) Publish(ctx context.Context, message *outbox.Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	// Only the subscriptions of the tenant the event happened in receive it.
+	if message.TenantID != "" {
+		ctx = db.WithTenant(ctx, message.TenantID)
+	}
+	return c.repo.Transaction(ctx, func(ctx context.Context) error {
+		request := &db.FindManyWithConditionsRequest{
+			FindManyRequest: db.FindManyRequest{Limit: subscriptionPageSize},
+			Conditions:      []clause.Expression{clause.Eq{Column: "active", Value: true}},
+			OrderBy:         clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "id"}}}},
+		}
+		for {
+			subscriptions := make([]Subscription, 0)
+			if err := c.repo.FindManyWithFilters(ctx, &subscriptions, request); err != nil {
+				return err
+			}
+			for i := range subscriptions {
+				if !subscriptions[i].Matches(message.Type) {
+					continue
+				}
+				if err := c.queue(ctx, &subscriptions[i], message, string(payload)); err != nil {
+					return err
+				}
+			}
+			if len(subscriptions) < subscriptionPageSize {
+				return nil
+			}
+			request.Offset += subscriptionPageSize
+		}
+	})
+}
+
+// queue creates the delivery of the message to the subscription unless it exists already,
+// as when the message is published again or by relays racing.
+func (c *Core) queue(ctx context.Context, subscription *Subscription, message *outbox.Message, payload string) error {
+	return c.repo.CreateIfAbsent(ctx, &Delivery{
+		SubscriptionID: subscription.ID,
+		EventID:        message.ID,
+		EventType:      message.Type,
+		Payload:        payload,
+	})
+}
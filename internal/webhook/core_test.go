@@ -0,0 +1,50 @@
+package webhook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/webhook"
+)
+
+func TestCore_Publish_Queues_Matching_Active_Subscriptions(t *testing.T) {
+	repo := setupRepo(t)
+	accounts := subscribe(t, repo, "https://example.com/accounts", outbox.TypeAccountCreated)
+	all := subscribe(t, repo, "https://example.com/all", webhook.AllEvents)
+	subscribe(t, repo, "https://example.com/transactions", outbox.TypeTransactionCreated+","+outbox.TypeBalanceDischarged)
+	inactive := subscribe(t, repo, "https://example.com/inactive", webhook.AllEvents)
+	inactive.Active = false
+	require.NoError(t, webhook.NewCore(repo).UpdateSubscription(context.Background(), inactive))
+
+	message := publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	subscriptionIDs := make([]string, 0)
+	for _, delivery := range deliveries(t, repo) {
+		assert.Equal(t, message.ID, delivery.EventID)
+		assert.Equal(t, webhook.StatusPending, delivery.Status)
+		subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+	}
+	assert.ElementsMatch(t, []string{accounts.ID, all.ID}, subscriptionIDs)
+}
+
+func TestCore_Publish_Is_Idempotent(t *testing.T) {
+	repo := setupRepo(t)
+	subscribe(t, repo, "https://example.com/all", webhook.AllEvents)
+	message := publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	// The relay publishes a message again when a later publisher failed.
+	require.NoError(t, webhook.NewCore(repo).Publish(context.Background(), message))
+	assert.Len(t, deliveries(t, repo), 1)
+}
+
+func TestCore_Redeliver_Rejects_Deliveries_That_Are_Not_Dead(t *testing.T) {
+	repo := setupRepo(t)
+	subscribe(t, repo, "https://example.com/all", webhook.AllEvents)
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	err := webhook.NewCore(repo).Redeliver(context.Background(), new(webhook.Delivery), deliveries(t, repo)[0].ID)
+	assert.ErrorIs(t, err, webhook.ErrNotDead)
+}
@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/common/network"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxErrorLength bounds the error recorded for an attempt, which may quote a response body.
+const maxErrorLength = 500
+
+// claimMargin is how long a claim on a delivery outlasts the timeout of its attempt, for
+// the outcome to be recorded.
+const claimMargin = time.Minute
+
+// Dispatcher sends due deliveries to their subscriptions, retrying failures with
+// exponential backoff until the delivery succeeds or runs out of attempts.
+// Dispatchers may run in every instance: each delivery is claimed by one before it is attempted.
+type Dispatcher struct {
+	repo   IRepo
+	client *http.Client
+	config Config
+	now    func() time.Time
+}
+
+func NewDispatcher(repo IRepo, client *http.Client, config Config) *Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 10 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if client == nil {
+		client = newClient(config)
+	}
+	return &Dispatcher{repo: repo, client: client, config: config, now: time.Now}
+}
+
+// newClient returns the client deliveries are sent with. It does not follow redirects, for
+// the deliveries to reach the URL validated only, and, unless insecure URLs are allowed,
+// refuses to connect to addresses which are not public, checked once the host is resolved.
+func newClient(config Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !config.AllowInsecureURLs {
+		// Connecting through a proxy would check the address of the proxy instead.
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   network.DialControl,
+		}).DialContext
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run sends deliveries until ctx is done, polling for due deliveries once none are left.
+// The deliveries being attempted when ctx is done are finished first, for their attempts
+// to be recorded.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		sent, err := d.DeliverDue(context.WithoutCancel(ctx))
+		if err != nil {
+			slog.ErrorContext(ctx, "webhook dispatcher failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		// A full batch means more deliveries are likely due.
+		if err == nil && sent == d.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts the pending deliveries that are due and returns how many were attempted.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	// Deliveries are read from the primary, not to attempt those just recorded again.
+	ctx = db.WithPrimary(ctx)
+	deliveries := make([]*Delivery, 0)
+	request := &db.FindManyWithConditionsRequest{
+		FindManyRequest: db.FindManyRequest{Limit: uint32(d.config.BatchSize)},
+		Conditions: []clause.Expression{
+			clause.Eq{Column: "status", Value: StatusPending},
+			clause.Lte{Column: "next_attempt_at", Value: d.now().Unix()},
+		},
+		OrderBy: clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Name: "next_attempt_at"}},
+			{Column: clause.Column{Name: "id"}},
+		}},
+	}
+	if err := d.repo.FindManyWithFilters(ctx, &deliveries, request); err != nil {
+		return 0, err
+	}
+	for i, delivery := range deliveries {
+		claimed, err := d.claim(ctx, delivery)
+		if err != nil {
+			return i, err
+		}
+		if !claimed {
+			continue
+		}
+		if err := d.deliver(ctx, delivery); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// claim leases the delivery to the dispatcher for as long as an attempt may take, by pushing
+// its next attempt back, for the dispatchers of other instances to skip it. It reports
+// whether the delivery was claimed, which fails when another dispatcher claimed it first.
+// A claim left by a dispatcher stopped mid-attempt expires as the next attempt comes due.
+func (d *Dispatcher) claim(ctx context.Context, delivery *Delivery) (bool, error) {
+	claimedUntil := d.now().Add(d.config.Timeout + claimMargin).Unix()
+	q := d.repo.DBInstance(ctx).Model(&Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, StatusPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", claimedUntil)
+	if q.Error != nil || q.RowsAffected == 0 {
+		return false, q.Error
+	}
+	delivery.NextAttemptAt = claimedUntil
+	return true, nil
+}
+
+// deliver makes one attempt at the delivery and records its outcome. The returned error
+// is a failure to record the outcome; a failed attempt is not an error.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) error {
+	ctx = db.WithTenant(ctx, delivery.TenantID)
+	subscription := new(Subscription)
+	err := d.repo.FindByID(ctx, subscription, delivery.SubscriptionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !subscription.Active) {
+		delivery.Status = StatusDead
+		delivery.LastError = "subscription deleted or inactive"
+		return d.repo.Update(ctx, delivery, "status", "last_error")
+	}
+	if err != nil {
+		return err
+	}
+
+	start := d.now()
+	attempt := &Attempt{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		Number:         delivery.Attempts + 1,
+	}
+	attempt.StatusCode, err = d.send(ctx, subscription, delivery, start)
+	attempt.DurationMs = d.now().Sub(start).Milliseconds()
+	if err != nil {
+		attempt.Error = truncate(err.Error())
+	}
+
+	return d.repo.Transaction(ctx, func(ctx context.Context) error {
+		if err := d.repo.Create(ctx, attempt); err != nil {
+			return err
+		}
+		delivery.Attempts = attempt.Number
+		delivery.LastError = attempt.Error
+		switch {
+		case attempt.Error == "":
+			delivery.Status = StatusSucceeded
+			delivery.DeliveredAt = d.now().Unix()
+		case delivery.Attempts >= d.config.MaxAttempts:
+			delivery.Status = StatusDead
+		default:
+			delivery.NextAttemptAt = start.Add(d.Backoff(delivery.Attempts)).Unix()
+		}
+		return d.repo.Update(ctx, delivery, "status", "attempts", "next_attempt_at", "last_error", "delivered_at")
+	})
+}
+
+// send posts the signed payload to the subscription and returns the response status.
+// Any status outside 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, subscription *Subscription, delivery *Delivery, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderDeliveryID, delivery.ID)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, now.Unix(), body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d: %s", resp.StatusCode, snippet)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// Backoff returns the delay after the given number of failed attempts:
+// InitialBackoff doubled for each attempt after the first, capped at MaxBackoff.
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	backoff := d.config.InitialBackoff
+	for i := 1; i < attempts && backoff < d.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.config.MaxBackoff {
+		backoff = d.config.MaxBackoff
+	}
+	return backoff
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		return s[:maxErrorLength]
+	}
+	return s
+}
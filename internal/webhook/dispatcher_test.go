@@ -0,0 +1,268 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/outbox"
+	"transaction-server/internal/webhook"
+)
+
+// setupRepo returns a repo over a fresh in-memory database holding the webhook tables.
+func setupRepo(t *testing.T) *db.Repo {
+	gDb, err := db.NewDb(&db.Config{
+		ConnectionPoolConfig: db.ConnectionPoolConfig{MaxOpenConnections: 1, MaxIdleConnections: 1},
+	}, db.Dialector(sqlite.Open("file::memory:")))
+	require.NoError(t, err)
+	require.NoError(t, gDb.Instance(context.Background()).AutoMigrate(
+		&webhook.Subscription{}, &webhook.Delivery{}, &webhook.Attempt{},
+	))
+	return &db.Repo{Db: gDb}
+}
+
+// receiver is an endpoint answering deliveries with the queued status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func subscribe(t *testing.T, repo *db.Repo, url string, eventTypes string) *webhook.Subscription {
+	subscription := &webhook.Subscription{URL: url, EventTypes: eventTypes, Active: true}
+	require.NoError(t, webhook.NewCore(repo).CreateSubscription(context.Background(), subscription))
+	return subscription
+}
+
+func publish(t *testing.T, repo *db.Repo, eventType string, aggregateID string) *outbox.Message {
+	event, err := outbox.NewEvent(eventType, outbox.AggregateAccount, aggregateID, map[string]string{"id": aggregateID})
+	require.NoError(t, err)
+	event.ID = "e" + aggregateID[1:]
+	message := event.ToMessage()
+	require.NoError(t, webhook.NewCore(repo).Publish(context.Background(), message))
+	return message
+}
+
+func deliveries(t *testing.T, repo *db.Repo) []webhook.Delivery {
+	result := make([]webhook.Delivery, 0)
+	require.NoError(t, repo.Db.Instance(context.Background()).Order("id").Find(&result).Error)
+	return result
+}
+
+// makeDue moves the next attempt of every pending delivery to now.
+func makeDue(t *testing.T, repo *db.Repo) {
+	require.NoError(t, repo.Db.Instance(context.Background()).
+		Model(&webhook.Delivery{}).Where("status = ?", webhook.StatusPending).
+		Update("next_attempt_at", 0).Error)
+}
+
+var testConfig = webhook.Config{
+	MaxAttempts:    3,
+	InitialBackoff: time.Minute,
+	MaxBackoff:     90 * time.Second,
+	Timeout:        time.Second,
+	BatchSize:      10,
+}
+
+func TestDispatcher_Delivers_Signed_Payload(t *testing.T) {
+	repo := setupRepo(t)
+	endpoint := &receiver{}
+	srv := httptest.NewServer(endpoint)
+	defer srv.Close()
+	subscription := subscribe(t, repo, srv.URL, outbox.TypeAccountCreated)
+	message := publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	dispatcher := webhook.NewDispatcher(repo, srv.Client(), testConfig)
+	sent, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	require.Len(t, endpoint.requests, 1)
+	req, body := endpoint.requests[0], endpoint.bodies[0]
+	assert.Equal(t, outbox.TypeAccountCreated, req.Header.Get(webhook.HeaderEventType))
+	assert.NoError(t, webhook.Verify(subscription.Secret, req.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()))
+	assert.ErrorIs(t, webhook.Verify("whsec_other", req.Header.Get(webhook.HeaderSignature), body, time.Minute, time.Now()), webhook.ErrInvalidSignature)
+	var received outbox.Message
+	require.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, message.ID, received.ID)
+
+	stored := deliveries(t, repo)
+	require.Len(t, stored, 1)
+	assert.Equal(t, webhook.StatusSucceeded, stored[0].Status)
+	assert.Equal(t, stored[0].ID, req.Header.Get(webhook.HeaderDeliveryID))
+	assert.NotZero(t, stored[0].DeliveredAt)
+
+	sent, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+}
+
+func TestDispatcher_Skips_Deliveries_Claimed_By_Another(t *testing.T) {
+	repo := setupRepo(t)
+	endpoint := &receiver{}
+	var other *webhook.Dispatcher
+	var polled atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The dispatcher of another instance polls while the first delivery is attempted.
+		if polled.CompareAndSwap(false, true) {
+			sent, err := other.DeliverDue(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 2, sent)
+		}
+		endpoint.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+	subscribe(t, repo, srv.URL, webhook.AllEvents)
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000002")
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000003")
+	other = webhook.NewDispatcher(repo, srv.Client(), testConfig)
+
+	_, err := webhook.NewDispatcher(repo, srv.Client(), testConfig).DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	deliveryIDs := make([]string, 0)
+	for _, req := range endpoint.requests {
+		deliveryIDs = append(deliveryIDs, req.Header.Get(webhook.HeaderDeliveryID))
+	}
+	assert.Len(t, deliveryIDs, 3)
+	for _, delivery := range deliveries(t, repo) {
+		assert.Equal(t, webhook.StatusSucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Contains(t, deliveryIDs, delivery.ID)
+	}
+}
+
+func TestDispatcher_Retries_With_Backoff_Then_Dead_Letters(t *testing.T) {
+	repo := setupRepo(t)
+	endpoint := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusBadGateway}}
+	srv := httptest.NewServer(endpoint)
+	defer srv.Close()
+	subscribe(t, repo, srv.URL, webhook.AllEvents)
+	publish(t, repo, outbox.TypeTransactionCreated, "t0000000000001")
+	dispatcher := webhook.NewDispatcher(repo, srv.Client(), testConfig)
+
+	before := time.Now().Unix()
+	sent, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	delivery := deliveries(t, repo)[0]
+	assert.Equal(t, webhook.StatusPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.GreaterOrEqual(t, delivery.NextAttemptAt, before+60)
+	assert.Contains(t, delivery.LastError, "500")
+
+	// The delivery is not due until its backoff has passed.
+	sent, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	for i := 0; i < 2; i++ {
+		makeDue(t, repo)
+		_, err = dispatcher.DeliverDue(context.Background())
+		require.NoError(t, err)
+	}
+	delivery = deliveries(t, repo)[0]
+	assert.Equal(t, webhook.StatusDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+
+	attempts, err := webhook.NewCore(repo).ListAttempts(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, *attempts, 3)
+	for i, attempt := range *attempts {
+		assert.Equal(t, i+1, attempt.Number)
+		assert.NotEmpty(t, attempt.Error)
+	}
+	assert.Equal(t, []int{500, 503, 502}, []int{(*attempts)[0].StatusCode, (*attempts)[1].StatusCode, (*attempts)[2].StatusCode})
+
+	// A redelivered dead delivery gets a fresh set of attempts.
+	require.NoError(t, webhook.NewCore(repo).Redeliver(context.Background(), new(webhook.Delivery), delivery.ID))
+	sent, err = dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	delivery = deliveries(t, repo)[0]
+	assert.Equal(t, webhook.StatusSucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+}
+
+func TestDispatcher_Dead_Letters_Deliveries_Of_Deleted_Subscriptions(t *testing.T) {
+	repo := setupRepo(t)
+	endpoint := &receiver{}
+	srv := httptest.NewServer(endpoint)
+	defer srv.Close()
+	subscription := subscribe(t, repo, srv.URL, webhook.AllEvents)
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+	require.NoError(t, webhook.NewCore(repo).DeleteSubscription(context.Background(), subscription))
+
+	_, err := webhook.NewDispatcher(repo, srv.Client(), testConfig).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, endpoint.requests)
+	assert.Equal(t, webhook.StatusDead, deliveries(t, repo)[0].Status)
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := webhook.NewDispatcher(nil, nil, testConfig)
+	assert.Equal(t, time.Minute, dispatcher.Backoff(1))
+	assert.Equal(t, 90*time.Second, dispatcher.Backoff(2))
+	assert.Equal(t, 90*time.Second, dispatcher.Backoff(10))
+}
+
+func TestDispatcher_Refuses_Addresses_Which_Are_Not_Public(t *testing.T) {
+	repo := setupRepo(t)
+	endpoint := &receiver{}
+	srv := httptest.NewServer(endpoint)
+	defer srv.Close()
+	subscribe(t, repo, srv.URL, webhook.AllEvents)
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	_, err := webhook.NewDispatcher(repo, nil, testConfig).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, endpoint.requests)
+	delivery := deliveries(t, repo)[0]
+	assert.Equal(t, webhook.StatusPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, "not a public address")
+}
+
+func TestDispatcher_Does_Not_Follow_Redirects(t *testing.T) {
+	repo := setupRepo(t)
+	target := &receiver{}
+	targetSrv := httptest.NewServer(target)
+	defer targetSrv.Close()
+	srv := httptest.NewServer(http.RedirectHandler(targetSrv.URL, http.StatusFound))
+	defer srv.Close()
+	subscribe(t, repo, srv.URL, webhook.AllEvents)
+	publish(t, repo, outbox.TypeAccountCreated, "a0000000000001")
+
+	config := testConfig
+	config.AllowInsecureURLs = true
+	_, err := webhook.NewDispatcher(repo, nil, config).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, target.requests)
+	delivery := deliveries(t, repo)[0]
+	assert.Equal(t, webhook.StatusPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, "302")
+}
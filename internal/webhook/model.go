@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"transaction-server/internal/common/db"
+	"transaction-server/internal/dto"
+)
+
+// AllEvents subscribes to every event type.
+const AllEvents = "*"
+
+// Delivery states.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// DeliveryStatuses lists the delivery states.
+var DeliveryStatuses = []string{StatusPending, StatusSucceeded, StatusDead}
+
+// Subscription represents an endpoint notified of domain events.
+type Subscription struct {
+	db.Model           // Embedding the common database model
+	URL         string `json:"url"`         // URL deliveries are posted to
+	EventTypes  string `json:"event_types"` // Comma-separated event types delivered, or * for all
+	Secret      string `json:"secret"`      // Secret deliveries are signed with
+	Active      bool   `json:"active"`      // Whether deliveries are made
+	Description string `json:"description"` // Description of the subscription
+}
+
+// TableName returns the name of the database table for the Subscription entity.
+func (e *Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EntityName returns the name of the entity.
+func (e *Subscription) EntityName() string {
+	return "webhook_subscription"
+}
+
+// SetDefaults generates a secret when none is set.
+func (e *Subscription) SetDefaults() error {
+	if e.Secret == "" {
+		secret, err := NewSecret()
+		if err != nil {
+			return err
+		}
+		e.Secret = secret
+	}
+	return nil
+}
+
+// Matches reports whether events of the type are delivered to the subscription.
+func (e *Subscription) Matches(eventType string) bool {
+	for _, t := range strings.Split(e.EventTypes, ",") {
+		if t == AllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ToDto converts the Subscription entity to its DTO representation. The secret is
+// only included when withSecret is set.
+func (e *Subscription) ToDto(withSecret bool) *dto.WebhookSubscription {
+	active := e.Active
+	subscription := &dto.WebhookSubscription{
+		ID:          e.ID,
+		URL:         e.URL,
+		EventTypes:  strings.Split(e.EventTypes, ","),
+		Active:      &active,
+		Description: e.Description,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+	if withSecret {
+		subscription.Secret = e.Secret
+	}
+	return subscription
+}
+
+// ApplyDto updates the Subscription entity fields based on the values provided in the DTO.
+// An empty secret keeps the current one and a missing active flag means active.
+func (e *Subscription) ApplyDto(val *dto.WebhookSubscription) {
+	e.URL = val.URL
+	e.EventTypes = strings.Join(val.EventTypes, ",")
+	if val.Secret != "" {
+		e.Secret = val.Secret
+	}
+	e.Active = val.Active == nil || *val.Active
+	e.Description = val.Description
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Delivery is an event queued for, or delivered to, a subscription.
+type Delivery struct {
+	db.Model              // Embedding the common database model
+	SubscriptionID string `json:"subscription_id" gorm:"uniqueIndex:idx_webhook_deliveries_subscription_event"` // ID of the subscription
+	EventID        string `json:"event_id" gorm:"uniqueIndex:idx_webhook_deliveries_subscription_event"`        // ID of the outbox event delivered, once per subscription
+	EventType      string `json:"event_type"`                                                                   // Type of the event delivered
+	Payload        string `json:"payload"`                                                                      // JSON body posted to the subscription
+	Status         string `json:"status"`                                                                       // State of the delivery: pending, succeeded or dead
+	Attempts       int    `json:"attempts"`                                                                     // Number of attempts made
+	NextAttemptAt  int64  `json:"next_attempt_at"`                                                              // Time of the next attempt of a pending delivery (Unix timestamp)
+	LastError      string `json:"last_error"`                                                                   // Error of the last failed attempt
+	DeliveredAt    int64  `json:"delivered_at"`                                                                 // Time the delivery succeeded (Unix timestamp)
+}
+
+// TableName returns the name of the database table for the Delivery entity.
+func (e *Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// EntityName returns the name of the entity.
+func (e *Delivery) EntityName() string {
+	return "webhook_delivery"
+}
+
+// SetDefaults sets default values for the Delivery entity.
+func (e *Delivery) SetDefaults() error {
+	if e.Status == "" {
+		e.Status = StatusPending
+	}
+	if e.NextAttemptAt == 0 {
+		e.NextAttemptAt = e.CreatedAt
+	}
+	return nil
+}
+
+// ToDto converts the Delivery entity to its DTO representation.
+func (e *Delivery) ToDto() *dto.WebhookDelivery {
+	return &dto.WebhookDelivery{
+		ID:             e.ID,
+		SubscriptionID: e.SubscriptionID,
+		EventID:        e.EventID,
+		EventType:      e.EventType,
+		Status:         e.Status,
+		Attempts:       e.Attempts,
+		NextAttemptAt:  e.NextAttemptAt,
+		LastError:      e.LastError,
+		DeliveredAt:    e.DeliveredAt,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+// Attempt is a logged attempt at a delivery.
+type Attempt struct {
+	db.Model              // Embedding the common database model
+	DeliveryID     string `json:"delivery_id"`     // ID of the delivery
+	SubscriptionID string `json:"subscription_id"` // ID of the subscription
+	Number         int    `json:"number"`          // 1-based number of the attempt
+	StatusCode     int    `json:"status_code"`     // HTTP status returned, 0 when no response was received
+	Error          string `json:"error"`           // Error of a failed attempt
+	DurationMs     int64  `json:"duration_ms"`     // Duration of the attempt in milliseconds
+}
+
+// TableName returns the name of the database table for the Attempt entity.
+func (e *Attempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+// EntityName returns the name of the entity.
+func (e *Attempt) EntityName() string {
+	return "webhook_delivery_attempt"
+}
+
+// SetDefaults sets default values for the Attempt entity.
+func (e *Attempt) SetDefaults() error {
+	return nil
+}
+
+// ToDto converts the Attempt entity to its DTO representation.
+func (e *Attempt) ToDto() *dto.WebhookAttempt {
+	return &dto.WebhookAttempt{
+		ID:         e.ID,
+		DeliveryID: e.DeliveryID,
+		Number:     e.Number,
+		StatusCode: e.StatusCode,
+		Error:      e.Error,
+		DurationMs: e.DurationMs,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// IListRequest is the interface that wraps basic request attribute getters for list requests.
+type IListRequest interface {
+	GetLimit() uint32
+	GetOffset() uint32
+}
+
+// IListDeliveryRequest is the interface that wraps the attribute getters of delivery list requests.
+type IListDeliveryRequest interface {
+	IListRequest
+	GetSubscriptionID() string
+	GetStatus() string
+}
@@ -0,0 +1,20 @@
+package webhook
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"transaction-server/internal/common/db"
+)
+
+type IRepo interface {
+	FindByID(ctx context.Context, receiver db.IModel, id string) error
+	FindByConditions(ctx context.Context, model interface{}, conditions []clause.Expression) error
+	FindManyWithFilters(ctx context.Context, models interface{}, req db.FindManyWithFiltersRequester) error
+	Create(ctx context.Context, receiver db.IModel) error
+	CreateIfAbsent(ctx context.Context, receiver db.IModel) error
+	Update(ctx context.Context, receiver db.IModel, selectiveList ...string) error
+	Delete(ctx context.Context, receiver db.IModel) error
+	Transaction(ctx context.Context, fc func(ctx context.Context) error) error
+	DBInstance(ctx context.Context) *gorm.DB
+}
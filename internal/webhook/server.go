@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/validator"
+)
+
+type IServer interface {
+	CreateSubscription(ctx *gin.Context, req *dto.CreateWebhookSubscriptionRequest) *dto.WebhookSubscriptionResponse
+	GetSubscription(ctx *gin.Context, id string) *dto.WebhookSubscriptionResponse
+	UpdateSubscription(ctx *gin.Context, req *dto.UpdateWebhookSubscriptionRequest) *dto.WebhookSubscriptionResponse
+	DeleteSubscription(ctx *gin.Context, id string) *dto.DeleteWebhookSubscriptionResponse
+	ListSubscriptions(ctx *gin.Context, req *dto.ListWebhookSubscriptionRequest) *dto.ListWebhookSubscriptionResponse
+	ListDeliveries(ctx *gin.Context, req *dto.ListWebhookDeliveryRequest) *dto.ListWebhookDeliveryResponse
+	ListAttempts(ctx *gin.Context, deliveryID string) *dto.ListWebhookAttemptResponse
+	Redeliver(ctx *gin.Context, deliveryID string) *dto.WebhookDeliveryResponse
+}
+
+type Server struct {
+	core   ICore
+	policy policy.IPolicy
+	config Config
+}
+
+func NewServer(core ICore, policy policy.IPolicy, config Config) IServer {
+	return &Server{core: core, policy: policy, config: config}
+}
+
+func (s *Server) CreateSubscription(ctx *gin.Context, req *dto.CreateWebhookSubscriptionRequest) *dto.WebhookSubscriptionResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksWrite); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(req, validator.CreateWebhookSubscriptionValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	subscription := new(Subscription)
+	subscription.ApplyDto(req.Subscription)
+	if err := s.core.CreateSubscription(ctx, subscription); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrDBPersistError, err.Error())}
+	}
+	return &dto.WebhookSubscriptionResponse{Subscription: subscription.ToDto(true), Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) GetSubscription(ctx *gin.Context, id string) *dto.WebhookSubscriptionResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksRead); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(id, validator.GetWebhookValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	subscription := new(Subscription)
+	if err := s.core.GetSubscription(ctx, subscription, id); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(queryErrorCode(err), err.Error())}
+	}
+	return &dto.WebhookSubscriptionResponse{Subscription: subscription.ToDto(false), Base: &dto.Base{Success: true}}
+}
+
+// UpdateSubscription replaces the subscription. The secret is only returned when it is rotated.
+func (s *Server) UpdateSubscription(ctx *gin.Context, req *dto.UpdateWebhookSubscriptionRequest) *dto.WebhookSubscriptionResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksWrite); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(req, validator.UpdateWebhookSubscriptionValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	subscription := new(Subscription)
+	if err := s.core.GetSubscription(ctx, subscription, req.ID); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(queryErrorCode(err), err.Error())}
+	}
+	subscription.ApplyDto(req.Subscription)
+	if err := s.core.UpdateSubscription(ctx, subscription); err != nil {
+		return &dto.WebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrDBPersistError, err.Error())}
+	}
+	return &dto.WebhookSubscriptionResponse{
+		Subscription: subscription.ToDto(req.Subscription.Secret != ""),
+		Base:         &dto.Base{Success: true},
+	}
+}
+
+func (s *Server) DeleteSubscription(ctx *gin.Context, id string) *dto.DeleteWebhookSubscriptionResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksWrite); err != nil {
+		return &dto.DeleteWebhookSubscriptionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(id, validator.GetWebhookValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.DeleteWebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	subscription := new(Subscription)
+	if err := s.core.GetSubscription(ctx, subscription, id); err != nil {
+		return &dto.DeleteWebhookSubscriptionResponse{Base: dto.GetErrorResponse(queryErrorCode(err), err.Error())}
+	}
+	if err := s.core.DeleteSubscription(ctx, subscription); err != nil {
+		return &dto.DeleteWebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrDBPersistError, err.Error())}
+	}
+	return &dto.DeleteWebhookSubscriptionResponse{Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) ListSubscriptions(ctx *gin.Context, req *dto.ListWebhookSubscriptionRequest) *dto.ListWebhookSubscriptionResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksRead); err != nil {
+		return &dto.ListWebhookSubscriptionResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(req, validator.ListWebhookSubscriptionValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.ListWebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	subscriptions, err := s.core.ListSubscriptions(ctx, req)
+	if err != nil {
+		return &dto.ListWebhookSubscriptionResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	subscriptionsDto := make([]*dto.WebhookSubscription, 0)
+	for _, subscription := range *subscriptions {
+		subscriptionsDto = append(subscriptionsDto, subscription.ToDto(false))
+	}
+	return &dto.ListWebhookSubscriptionResponse{Subscriptions: subscriptionsDto, Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) ListDeliveries(ctx *gin.Context, req *dto.ListWebhookDeliveryRequest) *dto.ListWebhookDeliveryResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksRead); err != nil {
+		return &dto.ListWebhookDeliveryResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(req, validator.ListWebhookDeliveryValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.ListWebhookDeliveryResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	deliveries, err := s.core.ListDeliveries(ctx, req)
+	if err != nil {
+		return &dto.ListWebhookDeliveryResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	deliveriesDto := make([]*dto.WebhookDelivery, 0)
+	for _, delivery := range *deliveries {
+		deliveriesDto = append(deliveriesDto, delivery.ToDto())
+	}
+	return &dto.ListWebhookDeliveryResponse{Deliveries: deliveriesDto, Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) ListAttempts(ctx *gin.Context, deliveryID string) *dto.ListWebhookAttemptResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksRead); err != nil {
+		return &dto.ListWebhookAttemptResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(deliveryID, validator.GetWebhookValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.ListWebhookAttemptResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	attempts, err := s.core.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return &dto.ListWebhookAttemptResponse{Base: dto.GetErrorResponse(common.ErrDBQueryError, err.Error())}
+	}
+	attemptsDto := make([]*dto.WebhookAttempt, 0)
+	for _, attempt := range *attempts {
+		attemptsDto = append(attemptsDto, attempt.ToDto())
+	}
+	return &dto.ListWebhookAttemptResponse{Attempts: attemptsDto, Base: &dto.Base{Success: true}}
+}
+
+func (s *Server) Redeliver(ctx *gin.Context, deliveryID string) *dto.WebhookDeliveryResponse {
+	if err := s.policy.Authorize(ctx, policy.PermissionWebhooksWrite); err != nil {
+		return &dto.WebhookDeliveryResponse{Base: dto.GetErrorResponse(policy.ErrorCode(err), err.Error())}
+	}
+	if err := validator.NewValidWebhook(deliveryID, validator.GetWebhookValidator, s.config.AllowInsecureURLs); err != nil {
+		return &dto.WebhookDeliveryResponse{Base: dto.GetErrorResponse(common.ErrValidationFailed, err.Error())}
+	}
+	delivery := new(Delivery)
+	if err := s.core.Redeliver(ctx, delivery, deliveryID); err != nil {
+		code := queryErrorCode(err)
+		if errors.Is(err, ErrNotDead) {
+			code = common.ErrValidationFailed
+		}
+		return &dto.WebhookDeliveryResponse{Base: dto.GetErrorResponse(code, err.Error())}
+	}
+	return &dto.WebhookDeliveryResponse{Delivery: delivery.ToDto(), Base: &dto.Base{Success: true}}
+}
+
+// queryErrorCode returns the error code of a failure to find an entity.
+func queryErrorCode(err error) string {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return common.ErrNotFoundFailed
+	}
+	return common.ErrDBQueryError
+}
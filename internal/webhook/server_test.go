@@ -0,0 +1,226 @@
+package webhook_test
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+	"transaction-server/internal/auth"
+	"transaction-server/internal/common"
+	"transaction-server/internal/dto"
+	"transaction-server/internal/policy"
+	"transaction-server/internal/webhook"
+	"transaction-server/internal/webhook/mock"
+)
+
+type ServerTest struct {
+	mockCoreCtrl *gomock.Controller
+	core         *mock.MockICore
+	server       webhook.IServer
+}
+
+func setupServerTest(t *testing.T) *ServerTest {
+	mockCoreCtrl := gomock.NewController(t)
+	mockCore := mock.NewMockICore(mockCoreCtrl)
+	server := webhook.NewServer(mockCore, policy.NewPolicy(nil, policy.Config{}), webhook.Config{})
+	return &ServerTest{
+		mockCoreCtrl: mockCoreCtrl,
+		core:         mockCore,
+		server:       server,
+	}
+}
+
+func teardownServerTest(td *ServerTest) {
+	td.mockCoreCtrl.Finish()
+}
+
+func TestServer_CreateSubscription_Success(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.CreateWebhookSubscriptionRequest{
+		Subscription: &dto.WebhookSubscription{
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"transaction.created", "balance.discharged"},
+		},
+	}
+
+	td.core.EXPECT().CreateSubscription(ctx, gomock.Any()).DoAndReturn(
+		func(_ *gin.Context, subscription *webhook.Subscription) error {
+			assert.True(t, subscription.Active)
+			assert.Equal(t, "transaction.created,balance.discharged", subscription.EventTypes)
+			subscription.Secret = "whsec_generated"
+			return nil
+		})
+
+	resp := td.server.CreateSubscription(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "whsec_generated", resp.Subscription.Secret)
+}
+
+func TestServer_CreateSubscription_ValidationFailed(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	for name, subscription := range map[string]*dto.WebhookSubscription{
+		"missing":        nil,
+		"relative url":   {URL: "/hooks", EventTypes: []string{"*"}},
+		"ftp url":        {URL: "ftp://example.com/hooks", EventTypes: []string{"*"}},
+		"no event types": {URL: "https://example.com/hooks"},
+		"unknown event":  {URL: "https://example.com/hooks", EventTypes: []string{"account.deleted"}},
+		"short secret":   {URL: "https://example.com/hooks", EventTypes: []string{"*"}, Secret: "short"},
+	} {
+		resp := td.server.CreateSubscription(ctx, &dto.CreateWebhookSubscriptionRequest{Subscription: subscription})
+		assert.False(t, resp.Success, name)
+		assert.Equal(t, common.ErrValidationFailed, resp.Error.Code, name)
+	}
+}
+
+func TestServer_GetSubscription_Hides_Secret(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	id := "abcdefghijklmn"
+	td.core.EXPECT().GetSubscription(ctx, gomock.Any(), id).DoAndReturn(
+		func(_ *gin.Context, subscription *webhook.Subscription, id string) error {
+			subscription.ID = id
+			subscription.Secret = "whsec_secret"
+			subscription.EventTypes = "*"
+			return nil
+		})
+
+	resp := td.server.GetSubscription(ctx, id)
+	assert.True(t, resp.Success)
+	assert.Empty(t, resp.Subscription.Secret)
+}
+
+func TestServer_GetSubscription_NotFound(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	id := "abcdefghijklmn"
+	td.core.EXPECT().GetSubscription(ctx, gomock.Any(), id).Return(gorm.ErrRecordNotFound)
+
+	resp := td.server.GetSubscription(ctx, id)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrNotFoundFailed, resp.Error.Code)
+}
+
+func TestServer_UpdateSubscription_Keeps_Secret(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	req := &dto.UpdateWebhookSubscriptionRequest{
+		ID:           "abcdefghijklmn",
+		Subscription: &dto.WebhookSubscription{URL: "https://example.com/v2", EventTypes: []string{"*"}},
+	}
+	td.core.EXPECT().GetSubscription(ctx, gomock.Any(), req.ID).DoAndReturn(
+		func(_ *gin.Context, subscription *webhook.Subscription, id string) error {
+			subscription.ID = id
+			subscription.Secret = "whsec_current"
+			return nil
+		})
+	td.core.EXPECT().UpdateSubscription(ctx, gomock.Any()).DoAndReturn(
+		func(_ *gin.Context, subscription *webhook.Subscription) error {
+			assert.Equal(t, "whsec_current", subscription.Secret)
+			assert.Equal(t, "https://example.com/v2", subscription.URL)
+			return nil
+		})
+
+	resp := td.server.UpdateSubscription(ctx, req)
+	assert.True(t, resp.Success)
+	assert.Empty(t, resp.Subscription.Secret)
+}
+
+func TestServer_ListDeliveries_ValidationFailed(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	resp := td.server.ListDeliveries(ctx, &dto.ListWebhookDeliveryRequest{SubscriptionID: "abcdefghijklmn", Status: "failed"})
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_Redeliver_NotDead(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	id := "abcdefghijklmn"
+	td.core.EXPECT().Redeliver(ctx, gomock.Any(), id).Return(webhook.ErrNotDead)
+
+	resp := td.server.Redeliver(ctx, id)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrValidationFailed, resp.Error.Code)
+}
+
+func TestServer_Redeliver_QueryError(t *testing.T) {
+	td := setupServerTest(t)
+	defer teardownServerTest(td)
+
+	ctx := asClient(policy.RoleAdmin)
+	id := "abcdefghijklmn"
+	td.core.EXPECT().Redeliver(ctx, gomock.Any(), id).Return(errors.New("db down"))
+
+	resp := td.server.Redeliver(ctx, id)
+	assert.False(t, resp.Success)
+	assert.Equal(t, common.ErrDBQueryError, resp.Error.Code)
+}
+
+// asClient returns a context authenticated as a client with the role.
+func asClient(role string) *gin.Context {
+	ctx := &gin.Context{}
+	ctx.Set(auth.ContextKeyIdentity, &auth.Identity{ClientID: "0c000000000001", Role: role})
+	return ctx
+}
+
+func TestServer_Forbidden(t *testing.T) {
+	id := "abcdefghijklmn"
+	subscription := &dto.WebhookSubscription{URL: "https://example.com/hooks", EventTypes: []string{"*"}}
+	handlers := map[string]func(server webhook.IServer, ctx *gin.Context) *dto.Base{
+		"create subscription": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.CreateSubscription(ctx, &dto.CreateWebhookSubscriptionRequest{Subscription: subscription}).Base
+		},
+		"get subscription": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.GetSubscription(ctx, id).Base
+		},
+		"update subscription": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.UpdateSubscription(ctx, &dto.UpdateWebhookSubscriptionRequest{ID: id, Subscription: subscription}).Base
+		},
+		"delete subscription": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.DeleteSubscription(ctx, id).Base
+		},
+		"list subscriptions": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.ListSubscriptions(ctx, &dto.ListWebhookSubscriptionRequest{}).Base
+		},
+		"list deliveries": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.ListDeliveries(ctx, &dto.ListWebhookDeliveryRequest{}).Base
+		},
+		"list attempts": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.ListAttempts(ctx, id).Base
+		},
+		"redeliver": func(server webhook.IServer, ctx *gin.Context) *dto.Base {
+			return server.Redeliver(ctx, id).Base
+		},
+	}
+	for _, role := range []string{policy.RoleAgent, policy.RolePartner} {
+		for name, handle := range handlers {
+			t.Run(role+" "+name, func(t *testing.T) {
+				td := setupServerTest(t)
+				defer teardownServerTest(td)
+
+				base := handle(td.server, asClient(role))
+				assert.False(t, base.Success)
+				assert.Equal(t, common.ErrForbidden, base.Error.Code)
+			})
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"transaction-server/internal/dto"
+)
+
+// TestTransactionAmountsAPI stores amounts too large or too precise for two decimals and
+// reads them back unchanged.
+func TestTransactionAmountsAPI(t *testing.T) {
+	for _, tc := range []struct {
+		currency string
+		amount   float64
+	}{
+		{currency: "JPY", amount: 12345678},
+		{currency: "BHD", amount: 1234.567},
+	} {
+		t.Run(tc.currency, func(t *testing.T) {
+			account := marshalJson(dto.CreateAccountRequest{Account: &dto.Account{
+				Name:           "Account " + tc.currency,
+				DocumentNumber: "12121231232323",
+				Currency:       tc.currency,
+			}})
+			accountId := getAccountsResponse(makeAPICall(t, account, "http://localhost:9040/accounts", "POST")).ID
+			require.NotEqual(t, "", accountId)
+
+			transaction := marshalJson(dto.CreateTransactionRequest{Transaction: &dto.Transaction{
+				AccountID:     accountId,
+				OperationType: "Withdraw",
+				Amount:        tc.amount,
+				Currency:      tc.currency,
+			}})
+			created := getTransactionResponse(makeAPICall(t, transaction, "http://localhost:9040/transactions", "POST"))
+			require.NotEqual(t, "", created.ID)
+
+			stored := getTransactionResponse(makeAPICall(t, nil, "http://localhost:9040/transactions/"+created.ID, "GET"))
+			require.Equal(t, -tc.amount, stored.Amount)
+			require.Equal(t, -tc.amount, stored.Balance)
+			require.Equal(t, -tc.amount, stored.SettledAmount)
+		})
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"testing"
+	"time"
+	"transaction-server/internal/auth"
 	"transaction-server/internal/dto"
 )
 
@@ -36,6 +39,13 @@ func makeAPICall(t *testing.T, requestBody []byte, url string, method string) []
 	// Set the request header
 	req.Header.Set("Content-Type", "application/json")
 
+	// Sign the request when the server requires authentication
+	if apiKey := os.Getenv("API_KEY"); apiKey != "" {
+		if err := auth.SignRequest(req, apiKey, time.Now()); err != nil {
+			t.Fatalf("failed to sign request: %v", err)
+		}
+	}
+
 	// Create an HTTP client
 	client := &http.Client{}
 